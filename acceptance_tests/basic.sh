@@ -250,6 +250,50 @@ EOL
   assertEquals "10" "$X"
 }
 
+testBasicUpdateInPlaceMinimalDiff() {
+  cat >test.yml <<EOL
+# settings
+a:    0   # the a
+
+b:
+    c: "cat"
+EOL
+  read -r -d '' expected << EOM
+# settings
+a:    10   # the a
+
+b:
+    c: "cat"
+    d: dog
+EOM
+  ./yq -i --minimal-diff '.a = 10 | .b.d = "dog"' test.yml
+  X=$(cat test.yml)
+  assertEquals "$expected" "$X"
+}
+
+testBasicUpdateInPlacePreserveFormatting() {
+  cat >test.yml <<EOL
+a: 0
+
+items:
+- cat
+
+b: 1
+EOL
+  read -r -d '' expected << EOM
+a: 10
+
+items:
+- cat
+- dog
+
+b: 1
+EOM
+  ./yq -i --preserve-formatting '.a = 10 | .items += ["dog"]' test.yml
+  X=$(cat test.yml)
+  assertEquals "$expected" "$X"
+}
+
 testBasicUpdateInPlaceMultipleFilesNoExpressionEval() {
   cat >test.yml <<EOL
 a: 0
@@ -257,14 +301,11 @@ EOL
   cat >test2.yml <<EOL
 a: 1
 EOL
-read -r -d '' expected << EOM
-0
----
-1
-EOM
   ./yq -i test.yml test2.yml
   X=$(./yq e '.a' test.yml)
-  assertEquals "$expected" "$X"
+  assertEquals "0" "$X"
+  X=$(./yq e '.a' test2.yml)
+  assertEquals "1" "$X"
 }
 
 testBasicUpdateInPlaceMultipleFilesNoExpressionEvalAll() {
@@ -274,14 +315,240 @@ EOL
   cat >test2.yml <<EOL
 a: 1
 EOL
-read -r -d '' expected << EOM
-0
+  ./yq -i ea test.yml test2.yml
+  X=$(./yq e '.a' test.yml)
+  assertEquals "0" "$X"
+  X=$(./yq e '.a' test2.yml)
+  assertEquals "1" "$X"
+}
+
+testBasicUpdateInPlaceMultipleFiles() {
+  cat >test.yml <<EOL
+a: 0
+EOL
+  cat >test2.yml <<EOL
+a: 1
 ---
-1
+a: 2
+EOL
+  ./yq -i '.a += 10' test.yml test2.yml
+  X=$(./yq e '.a' test.yml)
+  assertEquals "10" "$X"
+  read -r -d '' expected << EOM
+11
+---
+12
 EOM
-  ./yq -i ea test.yml test2.yml
+  X=$(./yq e '.a' test2.yml)
+  assertEquals "$expected" "$X"
+}
+
+testBasicUpdateInPlaceMultipleFilesEvalAll() {
+  cat >test.yml <<EOL
+a: 0
+EOL
+  cat >test2.yml <<EOL
+a: 1
+EOL
+  ./yq -i ea '.a |= . + 10' test.yml test2.yml
+  X=$(cat test.yml)
+  assertEquals "a: 10" "$X"
+  X=$(cat test2.yml)
+  assertEquals "a: 11" "$X"
+}
+
+testBasicUpdateInPlaceMultipleFilesEvalAllMerged() {
+  cat >test.yml <<EOL
+a: 0
+EOL
+  cat >test2.yml <<EOL
+b: 1
+EOL
+  read -r -d '' expected << EOM
+a: 0
+b: 1
+EOM
+  ./yq -i ea '. as $item ireduce ({}; . * $item)' test.yml test2.yml
+  X=$(cat test.yml)
+  assertEquals "$expected" "$X"
+  X=$(cat test2.yml)
+  assertEquals "" "$X"
+}
+
+testBasicUpdateInPlaceFilterEverythingEvalAll() {
+  cat >test.yml <<EOL
+x: 0
+EOL
+  cat >test2.yml <<EOL
+x: 2
+EOL
+  ./yq -i ea 'select(.x == 1)' test.yml test2.yml
+  assertEquals "0" "$?"
+  X=$(cat test.yml)
+  assertEquals "" "$X"
+  X=$(cat test2.yml)
+  assertEquals "" "$X"
+}
+
+testBasicUpdateInPlaceMultipleFilesFailure() {
+  cat >test.yml <<EOL
+a: 0
+EOL
+  cat >test2.yml <<EOL
+a: {b: cat}
+EOL
+  ./yq -i '.a += 1' test.yml test2.yml 2>/dev/null
+  assertEquals "1" "$?"
   X=$(./yq e '.a' test.yml)
+  assertEquals "1" "$X"
+  X=$(cat test2.yml)
+  assertEquals "a: {b: cat}" "$X"
+}
+
+testBasicUpdateInPlaceMultipleFilesAllOrNothing() {
+  cat >test.yml <<EOL
+a: 0
+EOL
+  cat >test2.yml <<EOL
+a: {b: cat}
+EOL
+  ./yq -i --all-or-nothing '.a += 1' test.yml test2.yml 2>/dev/null
+  assertEquals "1" "$?"
+  X=$(cat test.yml)
+  assertEquals "a: 0" "$X"
+  X=$(cat test2.yml)
+  assertEquals "a: {b: cat}" "$X"
+}
+
+testBasicUpdateInPlaceBackup() {
+  cat >test.yml <<EOL
+a: 0
+EOL
+  ./yq -i --backup=.bak '.a = 1' test.yml
+  X=$(cat test.yml)
+  assertEquals "a: 1" "$X"
+  X=$(cat test.yml.bak)
+  assertEquals "a: 0" "$X"
+  rm -f test.yml.bak
+}
+
+testBasicUpdateInPlaceDryRun() {
+  cat >test.yml <<EOL
+a: 0
+b: 1
+EOL
+  read -r -d '' expected << EOM
+--- test.yml
++++ test.yml
+@@ -1,2 +1,2 @@
+-a: 0
++a: 5
+ b: 1
+EOM
+  X=$(./yq --dry-run '.a = 5' test.yml)
   assertEquals "$expected" "$X"
+  X=$(cat test.yml)
+  assertEquals "$(printf 'a: 0\nb: 1')" "$X"
+}
+
+testBasicUpdateInPlaceCheck() {
+  cat >test.yml <<EOL
+a: 0
+EOL
+  cat >test2.yml <<EOL
+a: 1
+EOL
+  X=$(./yq --check '.a = 1' test.yml test2.yml 2>&1)
+  assertEquals "1" "$?"
+  assertEquals "Error: 1 file(s) would change: test.yml" "$X"
+
+  ./yq --check '.' test2.yml
+  assertEquals "0" "$?"
+  X=$(cat test.yml)
+  assertEquals "a: 0" "$X"
+}
+
+testBasicDirectoryAndGlobArguments() {
+  mkdir -p test_tree/sub test_tree/generated
+  echo "a: 1" > test_tree/a.yaml
+  echo "b: 2" > test_tree/sub/b.yml
+  echo "c: 3" > test_tree/generated/c.yaml
+  echo "d: 4" > test_tree/sub/d.json
+  echo "generated/" > test_tree/.gitignore
+
+  read -r -d '' expected << EOM
+a: 1
+b: 2
+EOM
+  X=$(./yq ea '. as $item ireduce ({}; . * $item)' test_tree)
+  assertEquals "$expected" "$X"
+
+  X=$(./yq ea '. as $item ireduce ({}; . * $item)' 'test_tree/**/*.yaml' --gitignore=false)
+  assertEquals "$(printf 'a: 1\nc: 3')" "$X"
+
+  X=$(./yq '.' test_tree --exclude=sub --include='*.yaml')
+  assertEquals "a: 1" "$X"
+
+  X=$(./yq '.' 'test_tree/*.txt' 2>&1)
+  assertEquals "Error: no files found for 'test_tree/*.txt'" "$X"
+  rm -rf test_tree
+}
+
+testBasicMoreFilesThanOpenFileLimit() {
+  mkdir -p test_tree
+  for i in $(seq 1 100); do
+    echo "a: 1" > "test_tree/f$i.yaml"
+  done
+
+  X=$(ulimit -n 32 && ./yq -N '.a' test_tree | wc -l)
+  assertEquals "100" "$X"
+  X=$(ulimit -n 32 && ./yq ea '[.a] | length' test_tree)
+  assertEquals "100" "$X"
+  rm -rf test_tree
+}
+
+testBasicInputOperators() {
+  cat >test.yml <<EOL
+a: 1
+---
+a: 2
+EOL
+  cat >test2.yml <<EOL
+a: 3
+EOL
+  X=$(./yq -oj -I0 '[.a, (input | .a)]' test.yml)
+  assertEquals "[1,2]" "$X"
+
+  X=$(./yq '(., inputs) as $doc ireduce (0; . + $doc.a)' test.yml test2.yml)
+  assertEquals "6" "$X"
+}
+
+testBasicParallel() {
+  cat >test.yml <<EOL
+a: 1
+---
+a: 2
+EOL
+  cat >test2.yml <<EOL
+a: 3
+EOL
+  cat >test3.json <<EOL
+{"a": 4}
+EOL
+  read -r -d '' expected << EOM
+test.yml 1
+test.yml 2
+test2.yml 3
+test3.json 4
+EOM
+  X=$(./yq --parallel 3 -N -oy 'filename + " " + .a' test.yml test2.yml test3.json)
+  assertEquals "$expected" "$X"
+
+  X=$(./yq --parallel 2 '.a' test.yml test2.yml 2>&1 && ./yq --parallel 0 '.a' test.yml 2>&1)
+  assertEquals "$(printf '1\n---\n2\n---\n3\nError: parallel must be at least 1')" "$X"
+
+  X=$(./yq ea --parallel 2 '.a' test.yml test2.yml 2>&1)
+  assertEquals "Error: parallel cannot be used with eval-all, which evaluates the files together" "$X"
 }
 
 testBasicNoExitStatus() {
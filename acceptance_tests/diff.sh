@@ -0,0 +1,59 @@
+#!/bin/bash
+
+setUp() {
+  rm test*.yml 2>/dev/null || true
+  rm test*.json 2>/dev/null || true
+  cat >test.yml <<EOL
+spec:
+  replicas: 1
+  image: nginx:1.24
+  ports: [80, 443]
+EOL
+  cat >test2.yml <<EOL
+spec:
+    ports:
+      - 443
+      - 80
+    replicas: 3
+EOL
+}
+
+testDiff() {
+  read -r -d '' expected << EOM
+~ spec.replicas: 1 -> 3
+- spec.image: nginx:1.24
+EOM
+
+  X=$(./yq diff --ignore-array-order test.yml test2.yml)
+  assertEquals "$expected" "$X"
+}
+
+testDiffExitCode() {
+  ./yq diff --exit-code test.yml test.yml
+  assertEquals 0 $?
+
+  ./yq diff --exit-code test.yml test2.yml > /dev/null 2>&1
+  assertEquals 1 $?
+}
+
+testDiffPatchRoundTrip() {
+  ./yq diff --format patch test.yml test2.yml > test-patch.json
+  ./yq patch test-patch.json test.yml
+
+  X=$(./yq diff test.yml test2.yml)
+  assertEquals "" "$X"
+}
+
+testDiffPatchIdentical() {
+  X=$(./yq diff --format patch test.yml test.yml)
+  assertEquals "[]" "$X"
+
+  ./yq diff --exit-code --format patch test.yml test.yml > /dev/null
+  assertEquals 0 $?
+
+  ./yq diff --format patch test.yml test.yml > test-patch.json
+  ./yq patch test-patch.json test.yml
+  assertEquals 0 $?
+}
+
+source ./scripts/shunit2
@@ -246,4 +246,37 @@ EOM
   assertEquals "$expected" "$X"
 }
 
+testInputMixedFormats() {
+  cat >test.yml <<EOL
+a: 1
+b:
+  c: 2
+EOL
+  cat >test.json <<EOL
+{ "b": { "d": 3 } }
+EOL
+  cat >test.toml <<EOL
+[b]
+e = 4
+EOL
+  cat >test.txt <<EOL
+f = 5
+EOL
+
+  read -r -d '' expected << EOM
+a: 1
+b:
+  c: 2
+  d: 3
+  e: 4
+f: "5"
+EOM
+
+  X=$(./yq ea '. as $item ireduce ({}; . * $item)' test.yml test.json test.toml props:test.txt)
+  assertEquals "$expected" "$X"
+
+  X=$(./yq '.b' test.json test.yml -oy)
+  assertEquals "$(printf 'd: 3\n---\nc: 2')" "$X"
+}
+
 source ./scripts/shunit2
@@ -6,6 +6,7 @@ setUp() {
   rm test*.csv 2>/dev/null || true
   rm test*.tsv 2>/dev/null || true
   rm test*.xml 2>/dev/null || true
+  rm test*.txt 2>/dev/null || true
 }
 
 testInputProperties() {
@@ -200,4 +201,94 @@ EOM
   assertEquals "$expected" "$X"
 }
 
+testInputTextOutputGzip() {
+  cat >test.txt <<EOL
+hello <b>world</b>
+EOL
+
+  read -r -d '' expected << EOM
+hello &lt;b&gt;world&lt;/b&gt;
+EOM
+
+  X=$(./yq -p=text -o=gzip test.txt | ./yq -p=gzip -o=html)
+  assertEquals "$expected" "$X"
+}
+
+testInputSniffStdin() {
+  read -r -d '' expected << EOM
+{
+  "b": 1
+}
+EOM
+  X=$(echo '{"a": {"b": 1}}' | ./yq -p sniff '.a')
+  assertEquals "$expected" "$X"
+
+  X=$(echo '<a><b>1</b></a>' | ./yq -p sniff '.a')
+  assertEquals "<b>1</b>" "$X"
+
+  X=$(printf 'mike.things = hello\n' | ./yq -p sniff '.mike.things = "bye"')
+  assertEquals "mike.things = bye" "$X"
+
+  X=$(printf '[owner]\nname = "Tom"\n' | ./yq -p sniff -oy)
+  assertEquals "$(printf 'owner:\n  name: Tom')" "$X"
+
+  X=$(printf 'name,age\ncat,3\n' | ./yq -p sniff -oy '.[0].name')
+  assertEquals "cat" "$X"
+}
+
+testInputSniffFiles() {
+  cat >test.txt <<EOL
+{ "a": 1 }
+EOL
+  cat >test.yml <<EOL
+b: 2
+EOL
+  X=$(./yq ea -p sniff -oy '. as $item ireduce ({}; . * $item)' test.txt test.yml)
+  assertEquals "$(printf 'a: 1\nb: 2')" "$X"
+}
+
+testInputRawLines() {
+  cat >test.txt <<EOL
+INFO started
+ERROR failed
+INFO done
+EOL
+  X=$(./yq -R 'select(test("^ERROR"))' test.txt)
+  assertEquals "ERROR failed" "$X"
+
+  X=$(./yq -R --slurp -oj -I0 'map(split(" ") | .[0])' test.txt)
+  assertEquals '["INFO","ERROR","INFO"]' "$X"
+}
+
+testInputSlurp() {
+  cat >test.yml <<EOL
+a: 1
+---
+a: 2
+EOL
+  X=$(./yq --slurp 'map(.a)' -oj -I0 test.yml)
+  assertEquals '[1,2]' "$X"
+
+  X=$(printf '{"a": 1}\n{"a": 2}\n' | ./yq -p json --slurp 'length')
+  assertEquals "2" "$X"
+}
+
+testInputStream() {
+  cat >test.json <<EOL
+{"items": [{"name": "a"}, {"name": "b"}], "other": 1}
+EOL
+  read -r -d '' expected << EOM
+[["items",0,"name"],"a"]
+[["items",1,"name"],"b"]
+EOM
+  X=$(./yq --stream 'select(length == 2 and .[0][0] == "items")' -I0 test.json)
+  assertEquals "$expected" "$X"
+
+  X=$(./yq --stream '. as $first | fromstream(1 | truncate_stream($first, inputs))' -oj -I0 test.json)
+  assertEquals '[{"name":"a"},{"name":"b"}]' "$X"
+
+  X=$(printf 'a: [1, 2]\n' | ./yq --stream -oj -I0 '[., inputs] | length')
+  assertEquals "4" "$X"
+}
+
 source ./scripts/shunit2
\ No newline at end of file
@@ -0,0 +1,55 @@
+#!/bin/bash
+
+setUp() {
+  rm test*.yml 2>/dev/null || true
+  cat >test-base.yml <<EOL
+a: 1
+b: 2
+EOL
+  cat >test-ours.yml <<EOL
+# ours
+a: 10 # changed
+b: 2
+EOL
+  cat >test-theirs.yml <<EOL
+a: 1
+b: 20
+EOL
+}
+
+testMerge3() {
+  read -r -d '' expected << EOM
+# ours
+a: 10 # changed
+b: 20
+EOM
+
+  X=$(./yq merge3 test-base.yml test-ours.yml test-theirs.yml)
+  assertEquals 0 $?
+  assertEquals "$expected" "$X"
+}
+
+testMerge3InPlaceConflict() {
+  cat >test-theirs.yml <<EOL
+a: 11
+b: 2
+EOL
+
+  read -r -d '' expected << EOM
+# ours
+<<<<<<< ours
+a: 10 # changed
+=======
+a: 11
+>>>>>>> theirs
+b: 2
+EOM
+
+  ./yq merge3 -i test-base.yml test-ours.yml test-theirs.yml 2>/dev/null
+  assertEquals 1 $?
+
+  X=$(cat test-ours.yml)
+  assertEquals "$expected" "$X"
+}
+
+source ./scripts/shunit2
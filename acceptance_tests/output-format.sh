@@ -271,4 +271,106 @@ EOM
   assertEquals "$expected" "$X"
 }
 
+testOutputYamlStyles() {
+  cat >test.yml <<EOL
+name: "the quick brown fox jumps over the lazy dog"
+ports:
+  - 80
+  - 443
+tags:
+  - web
+  - frontend
+  - public
+EOL
+
+  read -r -d '' expected << EOM
+name: 'the quick brown
+  fox jumps over the lazy
+  dog'
+ports: [80, 443]
+tags:
+- 'web'
+- 'frontend'
+- 'public'
+EOM
+
+  X=$(./yq --line-width=25 --quote-style=single --indentless-sequences --flow-threshold=2 test.yml)
+  assertEquals "$expected" "$X"
+}
+
+testOutputJsonSchema() {
+  cat >test.yml <<EOL
+a: {b: ["cat"]}
+EOL
+
+  read -r -d '' expected << EOM
+{
+  "\$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "a": {
+      "type": "object",
+      "properties": {
+        "b": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        }
+      },
+      "required": [
+        "b"
+      ]
+    }
+  },
+  "required": [
+    "a"
+  ]
+}
+EOM
+
+  X=$(./yq e --output-format=jsonschema test.yml)
+  assertEquals "$expected" "$X"
+}
+
+testOutputJsonSchemaEvalAll() {
+  cat >test.yml <<EOL
+a: {b: ["cat"]}
+EOL
+  cat >test2.yml <<EOL
+a: {c: 1}
+---
+d: true
+EOL
+
+  read -r -d '' expected << EOM
+{
+  "\$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "a": {
+      "type": "object",
+      "properties": {
+        "b": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "c": {
+          "type": "integer"
+        }
+      }
+    },
+    "d": {
+      "type": "boolean"
+    }
+  }
+}
+EOM
+
+  X=$(./yq ea --output-format=jsonschema test.yml test2.yml)
+  assertEquals "$expected" "$X"
+}
+
 source ./scripts/shunit2
\ No newline at end of file
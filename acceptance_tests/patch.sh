@@ -0,0 +1,62 @@
+#!/bin/bash
+
+setUp() {
+  rm test*.yml 2>/dev/null || true
+  rm test*.json 2>/dev/null || true
+  cat >test.yml <<EOL
+# deployment
+spec:
+  replicas: 1 # scale me
+  image: nginx:1.24
+EOL
+  cp test.yml test2.yml
+}
+
+testPatch() {
+  cat >test-patch.json <<EOL
+[{ "op": "replace", "path": "/spec/replicas", "value": 3 }, { "op": "add", "path": "/spec/port", "value": "80" }]
+EOL
+
+  read -r -d '' expected << EOM
+# deployment
+spec:
+  replicas: 3 # scale me
+  image: nginx:1.24
+  port: "80"
+EOM
+
+  ./yq patch test-patch.json test.yml test2.yml
+  assertEquals "$expected" "$(cat test.yml)"
+  assertEquals "$expected" "$(cat test2.yml)"
+}
+
+testPatchFailedTest() {
+  cat >test-patch.json <<EOL
+[{ "op": "remove", "path": "/spec" }, { "op": "test", "path": "/spec/replicas", "value": 2 }]
+EOL
+
+  original=$(cat test.yml)
+  X=$(./yq patch test-patch.json test.yml 2>&1)
+  assertEquals 1 $?
+  assertEquals "Error: could not patch test.yml: json patch operation [1] failed: path '/spec' does not exist" "$X"
+  assertEquals "$original" "$(cat test.yml)"
+}
+
+testMergePatch() {
+  cat >test-patch.yml <<EOL
+spec:
+  image: null
+  replicas: 2
+EOL
+
+  read -r -d '' expected << EOM
+# deployment
+spec:
+  replicas: 2 # scale me
+EOM
+
+  ./yq patch --merge test-patch.yml test.yml
+  assertEquals "$expected" "$(cat test.yml)"
+}
+
+source ./scripts/shunit2
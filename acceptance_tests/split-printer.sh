@@ -204,4 +204,80 @@ EOM
   assertEquals "$expectedDoc3" "$doc3"
 }
 
+testSplitOtherFormats() {
+  cat >test.yml <<EOL
+a: test_doc1
+---
+a: test_doc2
+EOL
+
+  ./yq test.yml -o=xml -s ".a"
+  assertEquals "<a>test_doc1</a>" "$(cat test_doc1.xml)"
+
+  ./yq test.yml -o=props -s '.a + "_props"'
+  assertEquals "a = test_doc2" "$(cat test_doc2_props.properties)"
+
+  ./yq test.yml -o=shell -s '.a + "_sh"'
+  assertEquals "a=test_doc1" "$(cat test_doc1_sh.sh)"
+  rm -f test_doc*.xml test_doc*.properties test_doc*.sh
+}
+
+testSplitIntoDirectories() {
+  cat >test.yml <<EOL
+kind: Service
+metadata:
+  namespace: test_a
+---
+kind: Deployment
+metadata:
+  namespace: test_b
+EOL
+
+  ./yq test.yml -s '"test_out/" + .metadata.namespace + "/" + .kind'
+
+  assertEquals "$(printf 'kind: Service\nmetadata:\n  namespace: test_a')" "$(cat test_out/test_a/Service.yml)"
+  assertEquals "Deployment" "$(./yq '.kind' test_out/test_b/Deployment.yml)"
+  rm -rf test_out
+}
+
+testSplitAppend() {
+  cat >test.yml <<EOL
+a: test_doc1
+b: 1
+---
+a: test_doc2
+b: 2
+---
+a: test_doc1
+b: 3
+EOL
+
+  ./yq test.yml -s '.a' --split-append
+
+  read -r -d '' expected << EOM
+a: test_doc1
+b: 1
+---
+a: test_doc1
+b: 3
+EOM
+  assertEquals "$expected" "$(cat test_doc1.yml)"
+  assertEquals "$(printf -- '---\na: test_doc2\nb: 2')" "$(cat test_doc2.yml)"
+
+  ./yq test.yml -s '.a'
+  assertEquals "$(printf -- '---\na: test_doc1\nb: 3')" "$(cat test_doc1.yml)"
+}
+
+testSplitNoOverwrite() {
+  cat >test.yml <<EOL
+a: test_doc1
+EOL
+  echo "existing" > test_doc1.yml
+
+  X=$(./yq test.yml -s '.a' --split-no-overwrite 2>&1)
+  assertEquals "1" "$?"
+  assertEquals "Error: refusing to overwrite 'test_doc1.yml', it already exists" "$X"
+  assertEquals "existing" "$(cat test_doc1.yml)"
+}
+
 source ./scripts/shunit2
\ No newline at end of file
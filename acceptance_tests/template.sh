@@ -0,0 +1,37 @@
+#!/bin/bash
+
+setUp() {
+  rm test*.yml 2>/dev/null || true
+  rm test*.tmpl 2>/dev/null || true
+  cat >test.yml <<EOL
+name: web
+replicas: 2
+EOL
+  cat >test.tmpl <<EOL
+app: {{ .name }}
+count: {{ yq ".replicas * 2" }}
+EOL
+}
+
+testTemplate() {
+  read -r -d '' expected << EOM
+app: web
+count: 4
+EOM
+
+  X=$(./yq template -f test.yml test.tmpl)
+  assertEquals "$expected" "$X"
+}
+
+testTemplateParsed() {
+  X=$(./yq template -f test.yml -p yaml -o json -I 0 test.tmpl)
+  assertEquals '{"app":"web","count":4}' "$X"
+}
+
+testTemplateOutputFormatNeedsInputFormat() {
+  X=$(./yq template -f test.yml -o json test.tmpl 2>&1)
+  assertEquals 1 $?
+  assertEquals "Error: the rendered templates are printed as they are, also give their format with --input-format to print them as json" "$X"
+}
+
+source ./scripts/shunit2
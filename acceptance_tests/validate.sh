@@ -0,0 +1,44 @@
+#!/bin/bash
+
+setUp() {
+  rm test*.yml 2>/dev/null || true
+  rm test*.json 2>/dev/null || true
+  cat >test-schema.json <<EOL
+{
+  "type": "object",
+  "required": ["name"],
+  "properties": {
+    "replicas": {"\$ref": "test-defs.json#/\$defs/replicas"}
+  }
+}
+EOL
+  cat >test-defs.json <<EOL
+{"\$defs": {"replicas": {"type": "integer", "minimum": 1}}}
+EOL
+  cat >test.yml <<EOL
+name: web
+replicas: 2
+EOL
+  cat >test2.yml <<EOL
+replicas: 0
+EOL
+}
+
+testValidateValid() {
+  X=$(./yq validate --schema test-schema.json test.yml)
+  assertEquals 0 $?
+  assertEquals "" "$X"
+}
+
+testValidateInvalid() {
+  read -r -d '' expected << EOM
+test2.yml:1:1: .: missing required property 'name'
+test2.yml:1:11: replicas: 0 is less than the minimum of 1
+EOM
+
+  X=$(./yq validate --schema test-schema.json test.yml test2.yml 2>/dev/null)
+  assertEquals 1 $?
+  assertEquals "$expected" "$X"
+}
+
+source ./scripts/shunit2
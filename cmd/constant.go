@@ -5,12 +5,23 @@ var unwrapScalarFlag = newUnwrapFlag()
 var unwrapScalar = false
 
 var writeInplace = false
+var allOrNothing = false
+var backupSuffix = ""
+var dryRun = false
+var checkInplace = false
+var minimalDiff = false
 var outputToJSON = false
 
 var outputFormat = ""
 
 var inputFormat = ""
 
+// the input format is detected from the extension of each file
+var autoInputFormat = false
+
+// the formats of the files given with a format: prefix
+var fileInputFormats = map[string]string{}
+
 var exitStatus = false
 var forceColor = false
 var forceNoColor = false
@@ -18,6 +29,10 @@ var colorsEnabled = false
 var indent = 2
 var noDocSeparators = false
 var nullInput = false
+var rawInput = false
+var slurp = false
+var streamInput = false
+var parallel = 1
 var nulSepOutput = false
 var verbose = false
 var version = false
@@ -29,8 +44,6 @@ var frontMatter = ""
 var splitFileExp = ""
 var splitFileExpFile = ""
 
-var completedSuccessfully = false
-
 var forceExpression = ""
 
 var expressionFile = ""
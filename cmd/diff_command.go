@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v3"
+)
+
+var diffFormat = "text"
+var diffIgnoreArrayOrder = false
+var diffIgnoreComments = false
+var diffExitCode = false
+
+func createDiffCommand() *cobra.Command {
+	var cmdDiff = &cobra.Command{
+		Use:   "diff [old_file] [new_file]",
+		Short: "Shows the structural differences between two files",
+		Example: `
+# List the paths that have been added, removed or changed
+yq diff old.yaml new.yaml
+
+# Create a json patch that updates old.yaml to new.yaml
+yq diff --format patch old.yaml new.yaml > patch.json
+
+# Fail (exit code 1) if the files are different, ignoring the order of arrays
+yq diff --exit-code --ignore-array-order old.yaml new.yaml
+`,
+		Long: `yq is a portable command-line YAML processor (https://github.com/mikefarah/yq/)
+See https://mikefarah.gitbook.io/yq/ for detailed documentation and examples.
+
+## Diff ##
+This command compares the content of two files, ignoring formatting differences like
+indentation, quoting and key order, and lists the paths that have been added, removed or changed.`,
+		RunE: diffFiles,
+	}
+	cmdDiff.Flags().StringVar(&diffFormat, "format", "text", "[text|patch|changes] text is a human readable listing, patch is an RFC 6902 json patch and changes is a yaml change set")
+	cmdDiff.Flags().BoolVar(&diffIgnoreArrayOrder, "ignore-array-order", false, "compare arrays as bags of values, ignoring the order of their elements")
+	cmdDiff.Flags().BoolVar(&diffIgnoreComments, "ignore-comments", false, "do not report changes to comments")
+	cmdDiff.Flags().BoolVar(&diffExitCode, "exit-code", false, "exit with status 1 if there are differences")
+	return cmdDiff
+}
+
+// loadExpressionForFile returns the expression to load the given file, based on its extension.
+func loadExpressionForFile(filename string) string {
+	switch yqlib.FormatFromFilename(filename) {
+	case "xml":
+		return fmt.Sprintf("load_xml(%v)", quoteExpressionString(filename))
+	case "properties", "props":
+		return fmt.Sprintf("load_props(%v)", quoteExpressionString(filename))
+	}
+	return fmt.Sprintf("load(%v)", quoteExpressionString(filename))
+}
+
+func diffFiles(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	if len(args) != 2 {
+		return fmt.Errorf("diff requires exactly two files to compare")
+	}
+
+	// both files are loaded the same way, so that
+	// the comparison is symmetric.
+	expression := fmt.Sprintf(`%v | diff(%v; {"format": "%v", "ignore_array_order": %v, "ignore_comments": %v})`,
+		loadExpressionForFile(args[0]), loadExpressionForFile(args[1]), diffFormat, diffIgnoreArrayOrder, diffIgnoreComments)
+	if diffFormat == "text" {
+		// the patch and changes are printed even when empty, so that they can still be applied
+		expression = expression + " | select(length > 0)"
+	}
+
+	if isAutomaticOutputFormat() {
+		outputFormat = "yaml"
+		if diffFormat == "patch" {
+			outputFormat = "json"
+		}
+	}
+	format, err := yqlib.OutputFormatFromString(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format == yqlib.YamlOutputFormat {
+		unwrapScalar = true
+	}
+	yqlib.ConfiguredYamlPreferences.UnwrapScalar = unwrapScalar
+
+	encoder, err := createEncoder(format)
+	if err != nil {
+		return err
+	}
+	printer := &diffPrinter{Printer: yqlib.NewPrinter(encoder, yqlib.NewSinglePrinterWriter(cmd.OutOrStdout()))}
+
+	err = yqlib.NewStreamEvaluator().EvaluateNew(expression, printer)
+	if err == nil && diffExitCode && printer.different {
+		return errors.New("files are different")
+	}
+	return err
+}
+
+// diffPrinter records if any of the differences it prints are not empty.
+type diffPrinter struct {
+	yqlib.Printer
+	different bool
+}
+
+func (p *diffPrinter) PrintResults(matchingNodes *list.List) error {
+	for el := matchingNodes.Front(); el != nil; el = el.Next() {
+		node := el.Value.(*yqlib.CandidateNode).Node
+		if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+			node = node.Content[0]
+		}
+		p.different = p.different || len(node.Content) > 0 || (node.Kind == yaml.ScalarNode && node.Value != "")
+	}
+	return p.Printer.PrintResults(matchingNodes)
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/mikefarah/yq/v4/pkg/yqlib"
 	"github.com/spf13/cobra"
@@ -22,6 +23,10 @@ yq ea -i 'select(fi == 0) * select(fi == 1)' f1.yml f2.yml
 # Merge all given files
 yq ea '. as $item ireduce ({}; . * $item )' file1.yml file2.yml ...
 
+# Merge files of different formats
+## each file is read in the format of its extension, or of a 'format:' prefix
+yq ea '. as $item ireduce ({}; . * $item )' base.yaml overrides.json props:extra.txt
+
 # Pipe from STDIN
 ## use '-' as a filename to pipe from STDIN
 cat file2.yml | yq ea '.a.b' file1.yml - file3.yml
@@ -36,9 +41,10 @@ Note that it consumes more memory than eval.
 `,
 		RunE: evaluateAll,
 	}
+	addFrontMatterFlag(cmdEvalAll)
 	return cmdEvalAll
 }
-func evaluateAll(cmd *cobra.Command, args []string) (cmdError error) {
+func evaluateAll(cmd *cobra.Command, args []string) error {
 	// 0 args, read std in
 	// 1 arg, null input, process expression
 	// 1 arg, read file in sequence
@@ -51,23 +57,16 @@ func evaluateAll(cmd *cobra.Command, args []string) (cmdError error) {
 		return err
 	}
 
+	if parallel > 1 {
+		return errors.New("parallel cannot be used with eval-all, which evaluates the files together")
+	}
+
 	out := cmd.OutOrStdout()
 
 	if writeInplace {
 		// only use colors if its forced
 		colorsEnabled = forceColor
-		writeInPlaceHandler := yqlib.NewWriteInPlaceHandler(args[0])
-		out, err = writeInPlaceHandler.CreateTempFile()
-		if err != nil {
-			return err
-		}
-		// need to indirectly call the function so  that completedSuccessfully is
-		// passed when we finish execution as opposed to now
-		defer func() {
-			if cmdError == nil {
-				cmdError = writeInPlaceHandler.FinishWriteInPlace(completedSuccessfully)
-			}
-		}()
+		return evaluateAllInPlace(processExpression(expression), args, cmd.OutOrStdout())
 	}
 
 	format, err := yqlib.OutputFormatFromString(outputFormat)
@@ -89,6 +88,13 @@ func evaluateAll(cmd *cobra.Command, args []string) (cmdError error) {
 		return err
 	}
 
+	expression = processExpression(expression)
+	if format == yqlib.JSONSchemaOutputFormat {
+		// infer a single schema from all the documents, rather than one for each
+		expression = inferSchemaExpression(expression)
+		encoder = yqlib.NewJSONEncoder(indent, colorsEnabled, false)
+	}
+
 	printer := yqlib.NewPrinter(encoder, printerWriter)
 	if nulSepOutput {
 		printer.SetNulSepOutput(true)
@@ -115,20 +121,25 @@ func evaluateAll(cmd *cobra.Command, args []string) (cmdError error) {
 	switch len(args) {
 	case 0:
 		if nullInput {
-			err = yqlib.NewStreamEvaluator().EvaluateNew(processExpression(expression), printer)
+			err = yqlib.NewStreamEvaluator().EvaluateNew(expression, printer)
 		} else {
 			cmd.Println(cmd.UsageString())
 			return nil
 		}
 	default:
-		err = allAtOnceEvaluator.EvaluateFiles(processExpression(expression), args, printer, decoder)
+		err = allAtOnceEvaluator.EvaluateFiles(expression, args, printer, decoder)
 	}
 
-	completedSuccessfully = err == nil
-
 	if err == nil && exitStatus && !printer.PrintedAnything() {
 		return errors.New("no matches found")
 	}
 
 	return err
 }
+
+func inferSchemaExpression(expression string) string {
+	if expression == "" {
+		return "infer_schema"
+	}
+	return fmt.Sprintf("(%v) | infer_schema", expression)
+}
@@ -30,6 +30,9 @@ yq e -n '.a.b.c = "cat"'
 
 # Update a file inplace
 yq e '.a.b = "cool"' -i file.yaml 
+
+# Evaluate 8 files at a time, printing the results in order
+yq e --parallel 8 '.kind' config/
 `,
 		Long: `yq is a portable command-line YAML processor (https://github.com/mikefarah/yq/) 
 See https://mikefarah.gitbook.io/yq/ for detailed documentation and examples.
@@ -39,6 +42,7 @@ This command iterates over each yaml document from each given file, applies the
 expression and prints the result in sequence.`,
 		RunE: evaluateSequence,
 	}
+	addFrontMatterFlag(cmdEvalSequence)
 	return cmdEvalSequence
 }
 
@@ -52,7 +56,7 @@ func processExpression(expression string) string {
 	return expression
 }
 
-func evaluateSequence(cmd *cobra.Command, args []string) (cmdError error) {
+func evaluateSequence(cmd *cobra.Command, args []string) error {
 	// 0 args, read std in
 	// 1 arg, null input, process expression
 	// 1 arg, read file in sequence
@@ -70,18 +74,7 @@ func evaluateSequence(cmd *cobra.Command, args []string) (cmdError error) {
 	if writeInplace {
 		// only use colors if its forced
 		colorsEnabled = forceColor
-		writeInPlaceHandler := yqlib.NewWriteInPlaceHandler(args[0])
-		out, err = writeInPlaceHandler.CreateTempFile()
-		if err != nil {
-			return err
-		}
-		// need to indirectly call the function so  that completedSuccessfully is
-		// passed when we finish execution as opposed to now
-		defer func() {
-			if cmdError == nil {
-				cmdError = writeInPlaceHandler.FinishWriteInPlace(completedSuccessfully)
-			}
-		}()
+		return evaluateSequenceInPlace(processExpression(expression), args, cmd.OutOrStdout())
 	}
 
 	format, err := yqlib.OutputFormatFromString(outputFormat)
@@ -108,6 +101,11 @@ func evaluateSequence(cmd *cobra.Command, args []string) (cmdError error) {
 		return err
 	}
 	streamEvaluator := yqlib.NewStreamEvaluator()
+	if parallel > 1 {
+		streamEvaluator = yqlib.NewParallelStreamEvaluator(parallel, func() (yqlib.Decoder, error) {
+			return configureDecoder(false)
+		})
+	}
 
 	if frontMatter != "" {
 		yqlib.GetLogger().Debug("using front matter handler")
@@ -137,8 +135,6 @@ func evaluateSequence(cmd *cobra.Command, args []string) (cmdError error) {
 	default:
 		err = streamEvaluator.EvaluateFiles(processExpression(expression), args, printer, decoder)
 	}
-	completedSuccessfully = err == nil
-
 	if err == nil && exitStatus && !printer.PrintedAnything() {
 		return errors.New("no matches found")
 	}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+	"github.com/spf13/cobra"
+)
+
+func createMerge3Command() *cobra.Command {
+	var cmdMerge3 = &cobra.Command{
+		Use:   "merge3 [base_file] [our_file] [their_file]",
+		Short: "Three way merges the changes made in two files, like a version control merge",
+		Example: `
+# Print the merge of the changes in ours.yaml and theirs.yaml
+yq merge3 base.yaml ours.yaml theirs.yaml
+
+# Use as a git merge driver: in .gitattributes add '*.yaml merge=yq' and run
+git config merge.yq.driver 'yq merge3 -i %O %A %B'
+`,
+		Long: `yq is a portable command-line YAML processor (https://github.com/mikefarah/yq/)
+See https://mikefarah.gitbook.io/yq/ for detailed documentation and examples.
+
+## Merge3 ##
+This command merges the changes made to base in ours and theirs, comparing values rather than
+lines. Comments, key order and formatting are kept from ours. When both sides change the same
+path differently, conflict markers are written for that path (yaml output only, otherwise our
+value is kept) and yq exits with status 1.
+
+With --inplace the result is written to our file, as expected by git merge drivers.`,
+		RunE: merge3Files,
+	}
+	return cmdMerge3
+}
+
+func merge3Files(cmd *cobra.Command, args []string) (cmdError error) {
+	cmd.SilenceUsage = true
+
+	if len(args) != 3 {
+		return fmt.Errorf("merge3 requires a base, our and their file")
+	}
+	ourFile := args[1]
+
+	inputFormatType, err := yqlib.InputFormatFromString(formatForFile(inputFormat, ourFile))
+	if err != nil {
+		return err
+	}
+	decoder, err := createDecoder(inputFormatType, false)
+	if err != nil {
+		return err
+	}
+
+	outputFormatType, err := yqlib.OutputFormatFromString(formatForFile(outputFormat, ourFile))
+	if err != nil {
+		return err
+	}
+	if outputFormatType == yqlib.YamlOutputFormat {
+		unwrapScalar = true
+	}
+	yqlib.ConfiguredYamlPreferences.UnwrapScalar = unwrapScalar
+	yqlib.ConfiguredYamlPreferences.PrintDocSeparators = !noDocSeparators
+	encoder, err := createEncoder(outputFormatType)
+	if err != nil {
+		return err
+	}
+
+	readers := make([]io.Reader, len(args))
+	for i, filename := range args {
+		file, err := os.Open(filename) // #nosec
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		readers[i] = file
+	}
+
+	// the result is written even when there are conflicts, so they can be resolved by hand
+	merged := false
+	out := cmd.OutOrStdout()
+	if writeInplace || dryRun || checkInplace {
+		// only use colors if its forced
+		colorsEnabled = forceColor
+		writeInPlaceHandler := yqlib.NewWriteInPlaceHandler(ourFile)
+		configureWriteInPlaceHandler(writeInPlaceHandler, out)
+		tempFile, err := writeInPlaceHandler.CreateTempFile()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			finishErr := writeInPlaceHandler.FinishWriteInPlace(merged)
+			if cmdError == nil {
+				cmdError = finishErr
+			}
+			if cmdError == nil && writeInPlaceHandler.Changed() {
+				cmdError = changedFilesError([]string{ourFile})
+			}
+		}()
+		out = tempFile
+	}
+
+	merger := yqlib.NewThreeWayMerger(decoder, encoder, outputFormatType == yqlib.YamlOutputFormat)
+	conflicts, err := merger.Merge(readers[0], readers[1], readers[2], out)
+	if err != nil {
+		return err
+	}
+	merged = true
+	if len(conflicts) > 0 {
+		return fmt.Errorf("merge conflicts at: %v", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+	"github.com/spf13/cobra"
+)
+
+var mergePatch = false
+
+func createPatchCommand() *cobra.Command {
+	var cmdPatch = &cobra.Command{
+		Use:   "patch [patch_file] [file1]...",
+		Short: "Applies a json patch (RFC 6902) or json merge patch (RFC 7386) file to each file in place",
+		Example: `
+# Apply a json patch to a deployment
+yq patch patch.json deployment.yaml
+
+# Apply a json merge patch to several files
+yq patch --merge overrides.yaml values-dev.yaml values-prod.yaml
+`,
+		Long: `yq is a portable command-line YAML processor (https://github.com/mikefarah/yq/)
+See https://mikefarah.gitbook.io/yq/ for detailed documentation and examples.
+
+## Patch ##
+This command applies the patch file to each document in each of the given files, updating
+the files in place. The patch file may be yaml or json. If any patch operation fails,
+including a 'test' operation, yq exits with an error and that file is left unchanged.`,
+		RunE: patchFiles,
+	}
+	cmdPatch.Flags().BoolVarP(&mergePatch, "merge", "m", false, "treat the patch file as a json merge patch (RFC 7386) instead of a json patch (RFC 6902)")
+	return cmdPatch
+}
+
+// quoteExpressionString wraps the value in quotes so it can be used as a string in an expression.
+func quoteExpressionString(value string) string {
+	return fmt.Sprintf(`"%v"`, strings.ReplaceAll(filepath.ToSlash(value), `"`, `\"`))
+}
+
+func patchFiles(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	if len(args) < 2 {
+		return fmt.Errorf("patch requires a patch file and at least one file to patch")
+	}
+
+	operator := "patch"
+	if mergePatch {
+		operator = "merge_patch"
+	}
+	patchDocument := fmt.Sprintf("load(%v)", quoteExpressionString(args[0]))
+	if yqlib.FormatFromFilename(args[0]) == "json" {
+		// json styling (quoted keys and values) shouldn't leak into the patched files
+		patchDocument = fmt.Sprintf("%v | %v", patchDocument, yqlib.PrettyPrintExp)
+	}
+	expression := fmt.Sprintf("%v(%v)", operator, patchDocument)
+
+	// only use colors if its forced
+	colorsEnabled = forceColor
+	yqlib.ConfiguredYamlPreferences.PrintDocSeparators = !noDocSeparators
+
+	var changedFiles []string
+	for _, filename := range args[1:] {
+		changed, err := patchFile(expression, filename, cmd.OutOrStdout())
+		if err != nil {
+			return fmt.Errorf("could not patch %v: %w", filename, err)
+		}
+		if changed {
+			changedFiles = append(changedFiles, filename)
+		}
+	}
+	return changedFilesError(changedFiles)
+}
+
+// formatForFile returns the explicitly given format, or detects it from the filename, defaulting to yaml.
+func formatForFile(format string, filename string) string {
+	if format == "sniff" {
+		sniffed, err := yqlib.SniffFormat(filename)
+		if err != nil {
+			// reading the file reports the error
+			return "yaml"
+		}
+		return sniffed
+	}
+	if format != "" && format != "auto" && format != "a" {
+		return format
+	}
+	detected := yqlib.FormatFromFilename(filename)
+	if _, err := yqlib.InputFormatFromString(detected); err != nil {
+		return "yaml"
+	}
+	return detected
+}
+
+func patchFile(expression string, filename string, diffWriter io.Writer) (changed bool, cmdError error) {
+	inputFormatType, err := yqlib.InputFormatFromString(formatForFile(inputFormat, filename))
+	if err != nil {
+		return false, err
+	}
+	decoder, err := createDecoder(inputFormatType, false)
+	if err != nil {
+		return false, err
+	}
+
+	outputFormatType, err := yqlib.OutputFormatFromString(formatForFile(outputFormat, filename))
+	if err != nil {
+		return false, err
+	}
+	encoder, err := createEncoder(outputFormatType)
+	if err != nil {
+		return false, err
+	}
+
+	writeInPlaceHandler := yqlib.NewWriteInPlaceHandler(filename)
+	configureWriteInPlaceHandler(writeInPlaceHandler, diffWriter)
+	out, err := writeInPlaceHandler.CreateTempFile()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		finishErr := writeInPlaceHandler.FinishWriteInPlace(cmdError == nil)
+		if cmdError == nil {
+			changed, cmdError = writeInPlaceHandler.Changed(), finishErr
+		}
+	}()
+
+	printer := yqlib.NewPrinter(encoder, yqlib.NewSinglePrinterWriter(out))
+	return false, yqlib.NewStreamEvaluator().EvaluateFiles(expression, []string{filename}, printer, decoder)
+}
@@ -65,8 +65,8 @@ yq -P sample.json
 		panic(err)
 	}
 
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output-format", "o", "auto", "[auto|a|yaml|y|json|j|props|p|xml|x|tsv|t|csv|c] output format type.")
-	rootCmd.PersistentFlags().StringVarP(&inputFormat, "input-format", "p", "auto", "[auto|a|yaml|y|props|p|xml|x|tsv|t|csv|c|toml] parse format for input. Note that json is a subset of yaml.")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output-format", "o", "auto", "[auto|a|yaml|y|json|j|props|p|xml|x|tsv|t|csv|c|base32|hex|gzip|html|json-escape|text|jsonschema] output format type.")
+	rootCmd.PersistentFlags().StringVarP(&inputFormat, "input-format", "p", "auto", "[auto|a|sniff|yaml|y|props|p|xml|x|tsv|t|csv|c|toml|base32|hex|gzip|html|json-escape|text] parse format for input, sniff detects the format of each input from its content. Note that json is a subset of yaml.")
 
 	rootCmd.PersistentFlags().StringVar(&yqlib.ConfiguredXMLPreferences.AttributePrefix, "xml-attribute-prefix", yqlib.ConfiguredXMLPreferences.AttributePrefix, "prefix for xml attributes")
 	rootCmd.PersistentFlags().StringVar(&yqlib.ConfiguredXMLPreferences.ContentName, "xml-content-name", yqlib.ConfiguredXMLPreferences.ContentName, "name for xml content (if no attribute name is present).")
@@ -79,11 +79,20 @@ yq -P sample.json
 	rootCmd.PersistentFlags().BoolVar(&yqlib.ConfiguredXMLPreferences.SkipDirectives, "xml-skip-directives", yqlib.ConfiguredXMLPreferences.SkipDirectives, "skip over directives (e.g. <!DOCTYPE thing cat>)")
 
 	rootCmd.PersistentFlags().BoolVarP(&nullInput, "null-input", "n", false, "Don't read input, simply evaluate the expression given. Useful for creating docs from scratch.")
+	rootCmd.PersistentFlags().BoolVarP(&rawInput, "raw-input", "R", false, "read each line of the input as a string, instead of parsing it.")
+	rootCmd.PersistentFlags().BoolVarP(&slurp, "slurp", "", false, "read all the documents of each input into a single array.")
+	rootCmd.PersistentFlags().BoolVarP(&streamInput, "stream", "", false, "read the input as a stream of [path, leaf] events. Only json is decoded incrementally, other formats are loaded a whole document at a time.")
 	rootCmd.PersistentFlags().BoolVarP(&noDocSeparators, "no-doc", "N", false, "Don't print document separators (---)")
 
 	rootCmd.PersistentFlags().IntVarP(&indent, "indent", "I", 2, "sets indent level for output")
+	rootCmd.PersistentFlags().IntVarP(&parallel, "parallel", "", 1, "decode and evaluate this many files at once, the output stays in order. input and inputs only read from the file being evaluated.")
 	rootCmd.Flags().BoolVarP(&version, "version", "V", false, "Print version information and quit")
-	rootCmd.PersistentFlags().BoolVarP(&writeInplace, "inplace", "i", false, "update the file inplace of first file given.")
+	rootCmd.PersistentFlags().BoolVarP(&writeInplace, "inplace", "i", false, "update the given files inplace.")
+	rootCmd.PersistentFlags().BoolVarP(&allOrNothing, "all-or-nothing", "", false, "when updating files inplace, only write them if every file was evaluated successfully.")
+	rootCmd.PersistentFlags().StringVarP(&backupSuffix, "backup", "", "", "when updating files inplace, keep a copy of each original file named with this suffix, e.g. --backup=.bak")
+	rootCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "", false, "print a unified diff of the changes updating the files inplace would make, without changing them. Implies -i.")
+	rootCmd.PersistentFlags().BoolVarP(&checkInplace, "check", "", false, "exit with an error if updating the files inplace would change any of them, without changing them. Implies -i.")
+	rootCmd.PersistentFlags().BoolVarP(&minimalDiff, "minimal-diff", "", false, "when updating yaml inplace, only rewrite the nodes that changed and leave the rest of the file as it was. Changes that can't be spliced in (e.g. reordered keys, or switching between block and flow style) rewrite the whole file, with a warning.")
 	rootCmd.PersistentFlags().VarP(unwrapScalarFlag, "unwrapScalar", "r", "unwrap scalar, print the value with no quotes, colors or comments. Defaults to true for yaml")
 	rootCmd.PersistentFlags().Lookup("unwrapScalar").NoOptDefVal = "true"
 	rootCmd.PersistentFlags().BoolVarP(&nulSepOutput, "nul-output", "0", false, "Use NUL char to separate values. If unwrap scalar is also set, fail if unwrapped scalar contains NUL char.")
@@ -93,19 +102,42 @@ yq -P sample.json
 
 	rootCmd.PersistentFlags().BoolVarP(&forceColor, "colors", "C", false, "force print with colors")
 	rootCmd.PersistentFlags().BoolVarP(&forceNoColor, "no-colors", "M", false, "force print with no colors")
-	rootCmd.PersistentFlags().StringVarP(&frontMatter, "front-matter", "f", "", "(extract|process) first input as yaml front-matter. Extract will pull out the yaml content, process will run the expression against the yaml content, leaving the remaining data intact")
+	addFrontMatterFlag(rootCmd)
 	rootCmd.PersistentFlags().StringVarP(&forceExpression, "expression", "", "", "forcibly set the expression argument. Useful when yq argument detection thinks your expression is a file.")
 	rootCmd.PersistentFlags().BoolVarP(&yqlib.ConfiguredYamlPreferences.LeadingContentPreProcessing, "header-preprocess", "", true, "Slurp any header comments and separators before processing expression.")
+	rootCmd.PersistentFlags().BoolVarP(&yqlib.ConfiguredYamlPreferences.PreserveFormatting, "preserve-formatting", "", false, "Keep blank lines and the sequence indentation style (indented or indentless) of yaml documents.")
+	rootCmd.PersistentFlags().IntVarP(&yqlib.ConfiguredYamlPreferences.LineWidth, "line-width", "", 0, "wrap long yaml strings onto several lines to keep lines within this width, 0 doesn't wrap.")
+	rootCmd.PersistentFlags().BoolVarP(&yqlib.ConfiguredYamlPreferences.IndentlessSequences, "indentless-sequences", "", false, "don't indent the '- ' of yaml sequences under map keys.")
+	rootCmd.PersistentFlags().StringVarP(&yqlib.ConfiguredYamlPreferences.QuoteStyle, "quote-style", "", "", "quote style of yaml string values: single, double or minimal. Defaults to keeping the style of each string.")
+	rootCmd.PersistentFlags().BoolVarP(&yqlib.ConfiguredYamlPreferences.LiteralMultilineStrings, "literal-multiline", "", false, "write yaml strings spanning several lines as literal blocks (|).")
+	rootCmd.PersistentFlags().IntVarP(&yqlib.ConfiguredYamlPreferences.FlowThreshold, "flow-threshold", "", 0, "write yaml arrays and maps of at most this many scalars in flow style, 0 keeps their style.")
 
 	rootCmd.PersistentFlags().StringVarP(&splitFileExp, "split-exp", "s", "", "print each result (or doc) into a file named (exp). [exp] argument must return a string. You can use $index in the expression as the result counter.")
 	rootCmd.PersistentFlags().StringVarP(&splitFileExpFile, "split-exp-file", "", "", "Use a file to specify the split-exp expression.")
+	rootCmd.PersistentFlags().BoolVarP(&yqlib.ConfiguredSplitFilePreferences.Append, "split-append", "", false, "when splitting into files, write the results named the same file into that file one after another, instead of replacing it.")
+	rootCmd.PersistentFlags().BoolVarP(&yqlib.ConfiguredSplitFilePreferences.NoOverwrite, "split-no-overwrite", "", false, "when splitting into files, fail instead of replacing files that already exist.")
+
+	rootCmd.PersistentFlags().StringArrayVarP(&yqlib.ConfiguredFileExpansionPreferences.Include, "include", "", yqlib.ConfiguredFileExpansionPreferences.Include, "pattern of the files to read from directory arguments, e.g. --include='*.json'. Can be given several times.")
+	rootCmd.PersistentFlags().StringArrayVarP(&yqlib.ConfiguredFileExpansionPreferences.Exclude, "exclude", "", nil, "pattern of the files and directories to skip in directory and glob arguments, e.g. --exclude='**/testdata'. Can be given several times.")
+	rootCmd.PersistentFlags().BoolVarP(&yqlib.ConfiguredFileExpansionPreferences.GitIgnore, "gitignore", "", true, "skip the files ignored by .gitignore files in directory and glob arguments.")
 
 	rootCmd.PersistentFlags().StringVarP(&expressionFile, "from-file", "", "", "Load expression from specified file.")
 
 	rootCmd.AddCommand(
 		createEvaluateSequenceCommand(),
 		createEvaluateAllCommand(),
+		createPatchCommand(),
+		createDiffCommand(),
+		createMerge3Command(),
+		createValidateCommand(),
+		createTemplateCommand(),
 		completionCmd,
 	)
 	return rootCmd
 }
+
+// addFrontMatterFlag adds the front matter flag to the commands that evaluate expressions,
+// it is not persistent so that other commands can use -f.
+func addFrontMatterFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&frontMatter, "front-matter", "f", "", "(extract|process) first input as yaml front-matter. Extract will pull out the yaml content, process will run the expression against the yaml content, leaving the remaining data intact")
+}
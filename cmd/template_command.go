@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+	"github.com/spf13/cobra"
+)
+
+var templateValuesFile = ""
+
+func createTemplateCommand() *cobra.Command {
+	var cmdTemplate = &cobra.Command{
+		Use:   "template -f [values_file] [template_file]...",
+		Short: "Renders Go text/templates using the values in a file",
+		Example: `
+# Render a template with the values in values.yaml
+yq template -f values.yaml config.tmpl
+
+# Render a yaml template, and print it as json
+yq template -f values.yaml -p yaml -o json deployment.yaml.tmpl
+`,
+		Long: `yq is a portable command-line YAML processor (https://github.com/mikefarah/yq/)
+See https://mikefarah.gitbook.io/yq/ for detailed documentation and examples.
+
+## Template ##
+This command renders each Go text/template (https://pkg.go.dev/text/template) with the values file
+as its data, and prints the result. Templates can evaluate yq expressions with the yq function,
+e.g. {{ yq ".a.b" }}.
+
+If an input format is given with --input-format, the rendered output is parsed in that format
+and printed in the output format. The output format can only be given with an input format.`,
+		RunE: templateFiles,
+	}
+	cmdTemplate.Flags().StringVarP(&templateValuesFile, "values", "f", "", "the file with the values to render the templates with")
+	return cmdTemplate
+}
+
+func templateFiles(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	if len(args) == 0 {
+		return fmt.Errorf("template requires at least one template file")
+	}
+
+	var valuesDecoder yqlib.Decoder
+	if templateValuesFile != "" {
+		valuesFormat, err := yqlib.InputFormatFromString(formatForFile("", templateValuesFile))
+		if err != nil {
+			return err
+		}
+		valuesDecoder, err = createDecoder(valuesFormat, false)
+		if err != nil {
+			return err
+		}
+	}
+	renderer := yqlib.NewTemplateRenderer(templateValuesFile, valuesDecoder)
+
+	if isAutomaticInputFormat() && !isAutomaticOutputFormat() {
+		return fmt.Errorf("the rendered templates are printed as they are, also give their format with --input-format to print them as %v", outputFormat)
+	} else if isAutomaticInputFormat() {
+		for _, templateFile := range args {
+			if err := renderer.Render(templateFile, cmd.OutOrStdout()); err != nil {
+				return fmt.Errorf("could not render %v: %w", templateFile, err)
+			}
+		}
+		return nil
+	}
+
+	// re-parse the rendered output
+	inputFormatType, err := yqlib.InputFormatFromString(inputFormat)
+	if err != nil {
+		return err
+	}
+	decoder, err := createDecoder(inputFormatType, false)
+	if err != nil {
+		return err
+	}
+	if isAutomaticOutputFormat() {
+		outputFormat = "yaml"
+		if _, err := yqlib.OutputFormatFromString(inputFormat); err == nil {
+			outputFormat = inputFormat
+		}
+	}
+	outputFormatType, err := yqlib.OutputFormatFromString(outputFormat)
+	if err != nil {
+		return err
+	}
+	if outputFormatType == yqlib.YamlOutputFormat || outputFormatType == yqlib.PropsOutputFormat {
+		unwrapScalar = true
+	}
+	yqlib.ConfiguredYamlPreferences.UnwrapScalar = unwrapScalar
+	yqlib.ConfiguredYamlPreferences.PrintDocSeparators = !noDocSeparators
+	encoder, err := createEncoder(outputFormatType)
+	if err != nil {
+		return err
+	}
+
+	printer := yqlib.NewPrinter(encoder, yqlib.NewSinglePrinterWriter(cmd.OutOrStdout()))
+	identity, err := yqlib.ExpressionParser.ParseExpression(".")
+	if err != nil {
+		return err
+	}
+	for _, templateFile := range args {
+		var rendered bytes.Buffer
+		if err := renderer.Render(templateFile, &rendered); err != nil {
+			return fmt.Errorf("could not render %v: %w", templateFile, err)
+		}
+		if _, err := yqlib.NewStreamEvaluator().Evaluate(templateFile, &rendered, identity, printer, decoder); err != nil {
+			return fmt.Errorf("could not parse the rendered %v: %w", templateFile, err)
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/mikefarah/yq/v4/pkg/yqlib"
 	"github.com/spf13/cobra"
@@ -14,6 +15,16 @@ func isAutomaticOutputFormat() bool {
 	return outputFormat == "" || outputFormat == "auto" || outputFormat == "a"
 }
 
+// useMinimalDiff checks if inplace updates should only rewrite the changed nodes, which needs yaml output.
+func useMinimalDiff() bool {
+	format, err := yqlib.OutputFormatFromString(outputFormat)
+	return minimalDiff && err == nil && format == yqlib.YamlOutputFormat
+}
+
+func isAutomaticInputFormat() bool {
+	return inputFormat == "" || inputFormat == "auto" || inputFormat == "a"
+}
+
 func initCommand(cmd *cobra.Command, args []string) (string, []string, error) {
 	cmd.SilenceUsage = true
 
@@ -28,6 +39,11 @@ func initCommand(cmd *cobra.Command, args []string) (string, []string, error) {
 		return "", nil, err
 	}
 
+	args, err = processFileArgs(args)
+	if err != nil {
+		return "", nil, err
+	}
+
 	if splitFileExpFile != "" {
 		splitExpressionBytes, err := os.ReadFile(splitFileExpFile)
 		if err != nil {
@@ -41,6 +57,10 @@ func initCommand(cmd *cobra.Command, args []string) (string, []string, error) {
 		outputFormat = "json"
 	}
 
+	if dryRun || checkInplace {
+		writeInplace = true
+	}
+
 	if writeInplace && (len(args) == 0 || args[0] == "-") {
 		return "", nil, fmt.Errorf("write inplace flag only applicable when giving an expression and at least one file")
 	}
@@ -53,6 +73,20 @@ func initCommand(cmd *cobra.Command, args []string) (string, []string, error) {
 		return "", nil, fmt.Errorf("write inplace cannot be used with split file")
 	}
 
+	if parallel < 1 {
+		return "", nil, fmt.Errorf("parallel must be at least 1")
+	}
+
+	if writeInplace && parallel > 1 {
+		return "", nil, fmt.Errorf("write inplace cannot be used with parallel")
+	}
+
+	switch yqlib.ConfiguredYamlPreferences.QuoteStyle {
+	case "", yqlib.QuoteStyleSingle, yqlib.QuoteStyleDouble, yqlib.QuoteStyleMinimal:
+	default:
+		return "", nil, fmt.Errorf("unknown quote style '%v', use one of: single, double, minimal", yqlib.ConfiguredYamlPreferences.QuoteStyle)
+	}
+
 	if nullInput && len(args) > 0 {
 		return "", nil, fmt.Errorf("cannot pass files in when using null-input flag")
 	}
@@ -61,9 +95,27 @@ func initCommand(cmd *cobra.Command, args []string) (string, []string, error) {
 	if len(args) > 0 {
 		inputFilename = args[0]
 	}
-	if inputFormat == "" || inputFormat == "auto" || inputFormat == "a" {
+	autoInputFormat = inputFormat == "" || inputFormat == "auto" || inputFormat == "a"
+	if inputFormat == "sniff" {
+		if err := sniffFileFormats(args); err != nil {
+			return "", nil, err
+		}
+		if inputFilename == "" {
+			inputFilename = "-"
+		}
+		inputFormat = "yaml"
+		if sniffedFormat, exists := fileInputFormats[inputFilename]; exists && !nullInput {
+			inputFormat = sniffedFormat
+		}
+		if isAutomaticOutputFormat() {
+			outputFormat = inputFormat
+		}
+	} else if autoInputFormat {
 
 		inputFormat = yqlib.FormatFromFilename(inputFilename)
+		if prefixedFormat, exists := fileInputFormats[inputFilename]; exists {
+			inputFormat = prefixedFormat
+		}
 
 		_, err := yqlib.InputFormatFromString(inputFormat)
 		if err != nil {
@@ -111,23 +163,145 @@ func initCommand(cmd *cobra.Command, args []string) (string, []string, error) {
 	//copy preference form global setting
 	yqlib.ConfiguredYamlPreferences.UnwrapScalar = unwrapScalar
 
-	yqlib.ConfiguredYamlPreferences.PrintDocSeparators = !noDocSeparators
+	// each line is a document, but they are printed like lines
+	yqlib.ConfiguredYamlPreferences.PrintDocSeparators = !noDocSeparators && (!rawInput || slurp)
 
 	return expression, args, nil
 }
 
+// processFileArgs strips the optional format: prefix of each file argument, recording the format to read those
+// files as, and expands the directories and globs into the files they contain.
+func processFileArgs(args []string) ([]string, error) {
+	fileInputFormats = map[string]string{}
+	files := make([]string, 0, len(args))
+	for _, arg := range args {
+		format, filename := splitFormatPrefix(arg)
+		expanded, err := yqlib.ExpandFiles([]string{filename}, yqlib.ConfiguredFileExpansionPreferences)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range expanded {
+			if format != "" {
+				fileInputFormats[file] = format
+			}
+		}
+		files = append(files, expanded...)
+	}
+	return files, nil
+}
+
+// splitFormatPrefix splits a 'format:path' argument into the format and path. Single letter prefixes
+// are not formats, so that windows drive letters still work, nor are prefixes of files that exist.
+func splitFormatPrefix(arg string) (string, string) {
+	separator := strings.Index(arg, ":")
+	if separator < 2 {
+		return "", arg
+	}
+	if _, err := yqlib.InputFormatFromString(arg[:separator]); err != nil {
+		return "", arg
+	}
+	if _, err := os.Stat(arg); err == nil {
+		return "", arg
+	}
+	return arg[:separator], arg[separator+1:]
+}
+
+// sniffFileFormats detects the format of each file (or stdin when there are none) from its content, unless it
+// was given with a format: prefix.
+func sniffFileFormats(args []string) error {
+	if nullInput {
+		return nil
+	}
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+	for _, filename := range args {
+		if _, exists := fileInputFormats[filename]; exists {
+			continue
+		}
+		format, err := yqlib.SniffFormat(filename)
+		if err != nil {
+			return err
+		}
+		fileInputFormats[filename] = format
+	}
+	return nil
+}
+
+// inputFormatForFile finds the format of a file given to the eval commands, from its format: prefix,
+// the input format flag, or else its extension.
+func inputFormatForFile(filename string) string {
+	if prefixedFormat, exists := fileInputFormats[filename]; exists {
+		return prefixedFormat
+	}
+	if autoInputFormat && filename == "-" {
+		return inputFormat
+	} else if autoInputFormat {
+		return formatForFile("auto", filename)
+	}
+	return inputFormat
+}
+
 func configureDecoder(evaluateTogether bool) (yqlib.Decoder, error) {
+	var decoder yqlib.Decoder
+	if rawInput {
+		decoder = yqlib.NewLineDecoder()
+		if streamInput {
+			decoder = createStreamDecoder(decoder)
+		}
+	} else {
+		var err error
+		if decoder, err = configureFormatDecoder(evaluateTogether); err != nil {
+			return nil, err
+		}
+	}
+	if slurp {
+		decoder = yqlib.NewSlurpDecoder(decoder)
+	}
+	return decoder, nil
+}
+
+func configureFormatDecoder(evaluateTogether bool) (yqlib.Decoder, error) {
+	if autoInputFormat || len(fileInputFormats) > 0 {
+		// the files may have different formats
+		return yqlib.NewByFileDecoder(func(filename string) (yqlib.InputFormat, error) {
+			return yqlib.InputFormatFromString(inputFormatForFile(filename))
+		}, func(format yqlib.InputFormat) (yqlib.Decoder, error) {
+			return createInputDecoder(format, evaluateTogether)
+		}), nil
+	}
 	yqlibInputFormat, err := yqlib.InputFormatFromString(inputFormat)
 	if err != nil {
 		return nil, err
 	}
-	yqlibDecoder, err := createDecoder(yqlibInputFormat, evaluateTogether)
+	yqlibDecoder, err := createInputDecoder(yqlibInputFormat, evaluateTogether)
 	if yqlibDecoder == nil {
 		return nil, fmt.Errorf("no support for %s input format", inputFormat)
 	}
 	return yqlibDecoder, err
 }
 
+// createInputDecoder creates the decoder for the format, decoding it into [path, leaf] events with --stream.
+func createInputDecoder(format yqlib.InputFormat, evaluateTogether bool) (yqlib.Decoder, error) {
+	if !streamInput {
+		return createDecoder(format, evaluateTogether)
+	} else if format == yqlib.JsonInputFormat {
+		return yqlib.NewJSONStreamDecoder(), nil
+	}
+	decoder, err := createDecoder(format, evaluateTogether)
+	if decoder == nil || err != nil {
+		return decoder, err
+	}
+	return createStreamDecoder(decoder), nil
+}
+
+// createStreamDecoder splits each document the decoder reads into events, which unlike json still loads
+// every document in full.
+func createStreamDecoder(decoder yqlib.Decoder) yqlib.Decoder {
+	yqlib.GetLogger().Warning("--stream only decodes json input incrementally, other formats are loaded a whole document at a time (use -p=json for json)")
+	return yqlib.NewStreamDecoder(decoder)
+}
+
 func createDecoder(format yqlib.InputFormat, evaluateTogether bool) (yqlib.Decoder, error) {
 	switch format {
 	case yqlib.XMLInputFormat:
@@ -142,6 +316,18 @@ func createDecoder(format yqlib.InputFormat, evaluateTogether bool) (yqlib.Decod
 		return yqlib.NewCSVObjectDecoder('\t'), nil
 	case yqlib.TomlInputFormat:
 		return yqlib.NewTomlDecoder(), nil
+	case yqlib.Base32InputFormat:
+		return yqlib.NewBase32Decoder(), nil
+	case yqlib.HexInputFormat:
+		return yqlib.NewHexDecoder(), nil
+	case yqlib.GzipInputFormat:
+		return yqlib.NewGzipDecoder(), nil
+	case yqlib.HtmlInputFormat:
+		return yqlib.NewHtmlDecoder(), nil
+	case yqlib.JSONEscapeInputFormat:
+		return yqlib.NewJSONUnescapeDecoder(), nil
+	case yqlib.TextInputFormat:
+		return yqlib.NewTextDecoder(), nil
 	case yqlib.YamlInputFormat:
 		prefs := yqlib.ConfiguredYamlPreferences
 		prefs.EvaluateTogether = evaluateTogether
@@ -160,7 +346,7 @@ func configurePrinterWriter(format yqlib.PrinterOutputFormat, out io.Writer) (yq
 		if err != nil {
 			return nil, fmt.Errorf("bad split document expression: %w", err)
 		}
-		printerWriter = yqlib.NewMultiPrinterWriter(splitExp, format)
+		printerWriter = yqlib.NewMultiPrinterWriter(splitExp, format, yqlib.ConfiguredSplitFilePreferences)
 	} else {
 		printerWriter = yqlib.NewSinglePrinterWriter(out)
 	}
@@ -197,6 +383,20 @@ func createEncoder(format yqlib.PrinterOutputFormat) (yqlib.Encoder, error) {
 		return yqlib.NewTomlEncoder(), nil
 	case yqlib.ShellVariablesOutputFormat:
 		return yqlib.NewShellVariablesEncoder(), nil
+	case yqlib.Base32OutputFormat:
+		return yqlib.NewBase32Encoder(), nil
+	case yqlib.HexOutputFormat:
+		return yqlib.NewHexEncoder(), nil
+	case yqlib.GzipOutputFormat:
+		return yqlib.NewGzipEncoder(), nil
+	case yqlib.HtmlOutputFormat:
+		return yqlib.NewHtmlEncoder(), nil
+	case yqlib.JSONEscapeOutputFormat:
+		return yqlib.NewJSONEscapeEncoder(), nil
+	case yqlib.TextOutputFormat:
+		return yqlib.NewTextEncoder(), nil
+	case yqlib.JSONSchemaOutputFormat:
+		return yqlib.NewJSONSchemaEncoder(indent, colorsEnabled), nil
 	}
 	return nil, fmt.Errorf("invalid encoder: %v", format)
 }
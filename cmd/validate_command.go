@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+	"github.com/spf13/cobra"
+)
+
+var schemaFile = ""
+
+func createValidateCommand() *cobra.Command {
+	var cmdValidate = &cobra.Command{
+		Use:   "validate --schema [schema_file] [file1]...",
+		Short: "Validates files against a JSON Schema",
+		Example: `
+# Validate helm values against their schema
+yq validate --schema values.schema.json values.yaml values-prod.yaml
+`,
+		Long: `yq is a portable command-line YAML processor (https://github.com/mikefarah/yq/)
+See https://mikefarah.gitbook.io/yq/ for detailed documentation and examples.
+
+## Validate ##
+This command validates every document in the given files against a JSON Schema (draft 2020-12).
+The schema may be json or yaml, and $refs to other schema files are resolved relative to it.
+Each violation is printed as file:line:column: path: message, and yq exits with an error
+if any are found.`,
+		RunE: validateFiles,
+	}
+	cmdValidate.Flags().StringVar(&schemaFile, "schema", "", "the JSON Schema file to validate against")
+	return cmdValidate
+}
+
+func validateFiles(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	if schemaFile == "" {
+		return fmt.Errorf("a schema file must be given with --schema")
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("validate requires at least one file to validate")
+	}
+
+	validator, err := yqlib.NewJSONSchemaValidator(schemaFile)
+	if err != nil {
+		return fmt.Errorf("could not load schema %v: %w", schemaFile, err)
+	}
+
+	violationCount := 0
+	for _, filename := range args {
+		inputFormatType, err := yqlib.InputFormatFromString(formatForFile(inputFormat, filename))
+		if err != nil {
+			return err
+		}
+		decoder, err := createDecoder(inputFormatType, false)
+		if err != nil {
+			return err
+		}
+		violations, err := validator.ValidateFile(filename, decoder)
+		if err != nil {
+			return fmt.Errorf("could not validate %v: %w", filename, err)
+		}
+		for _, violation := range violations {
+			fmt.Fprintf(cmd.OutOrStdout(), "%v:%v:%v: %v: %v\n", filename, violation.Line, violation.Column, violation.PathString(), violation.Message)
+		}
+		violationCount = violationCount + len(violations)
+	}
+
+	if violationCount > 0 {
+		return fmt.Errorf("found %v schema violation(s)", violationCount)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+)
+
+type inPlaceHandler interface {
+	CreateTempFile() (*os.File, error)
+	FinishWriteInPlace(evaluatedSuccessfully bool) error
+	SetBackupSuffix(suffix string)
+	SetDryRun(diffWriter io.Writer)
+	Changed() bool
+}
+
+// configureWriteInPlaceHandler sets the backup and dry run options, dry runs print the diffs to out.
+func configureWriteInPlaceHandler(handler inPlaceHandler, out io.Writer) {
+	handler.SetBackupSuffix(backupSuffix)
+	if dryRun {
+		handler.SetDryRun(out)
+	} else if checkInplace {
+		handler.SetDryRun(io.Discard)
+	}
+}
+
+// changedFilesError fails the check when any of the files would be changed.
+func changedFilesError(changedFiles []string) error {
+	if !checkInplace || len(changedFiles) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%v file(s) would change: %v", len(changedFiles), strings.Join(changedFiles, ", "))
+}
+
+// inPlaceFile is a file being updated inplace, its results are printed into a temp file
+// that replaces the file once it has been evaluated successfully.
+type inPlaceFile struct {
+	// the file to evaluate, this is the yaml front matter when updating front matter
+	filename       string
+	targetFilename string
+	handler        inPlaceHandler
+	printer        yqlib.Printer
+	cleanUp        func()
+}
+
+func newInPlaceFile(filename string, diffWriter io.Writer) (*inPlaceFile, error) {
+	var handler inPlaceHandler = yqlib.NewWriteInPlaceHandler(filename)
+	if useMinimalDiff() {
+		handler = yqlib.NewMinimalDiffWriteInPlaceHandler(filename, indent)
+	}
+	configureWriteInPlaceHandler(handler, diffWriter)
+	out, err := handler.CreateTempFile()
+	if err != nil {
+		return nil, err
+	}
+	file := &inPlaceFile{filename: filename, targetFilename: filename, handler: handler, cleanUp: func() {}}
+
+	encoder, err := configureEncoder()
+	if err != nil {
+		return nil, file.abort(err)
+	}
+	printer := yqlib.NewPrinter(encoder, yqlib.NewSinglePrinterWriter(out))
+	if nulSepOutput {
+		printer.SetNulSepOutput(true)
+	}
+	file.printer = printer
+
+	if frontMatter != "" {
+		frontMatterHandler := yqlib.NewFrontMatterHandler(filename)
+		if err := frontMatterHandler.Split(); err != nil {
+			return nil, file.abort(err)
+		}
+		file.filename = frontMatterHandler.GetYamlFrontMatterFilename()
+		file.cleanUp = frontMatterHandler.CleanUp
+		if frontMatter == "process" {
+			reader := frontMatterHandler.GetContentReader()
+			printer.SetAppendix(reader)
+			file.cleanUp = func() {
+				yqlib.SafelyCloseReader(reader)
+				frontMatterHandler.CleanUp()
+			}
+		}
+	}
+	return file, nil
+}
+
+func (f *inPlaceFile) finish(evaluatedSuccessfully bool) error {
+	defer f.cleanUp()
+	return f.handler.FinishWriteInPlace(evaluatedSuccessfully)
+}
+
+func (f *inPlaceFile) abort(err error) error {
+	if finishErr := f.finish(false); finishErr != nil {
+		yqlib.GetLogger().Warning("could not remove the temp file: %v", finishErr)
+	}
+	return err
+}
+
+// finishInPlaceFiles writes the files that were evaluated successfully, returning the first error.
+func finishInPlaceFiles(files []*inPlaceFile, evaluationErr error) error {
+	err := evaluationErr
+	for _, file := range files {
+		if finishErr := file.finish(evaluationErr == nil); err == nil {
+			err = finishErr
+		}
+	}
+	return err
+}
+
+func changedInPlaceFiles(files []*inPlaceFile) []string {
+	var changedFiles []string
+	for _, file := range files {
+		if file.handler.Changed() {
+			changedFiles = append(changedFiles, file.targetFilename)
+		}
+	}
+	return changedFiles
+}
+
+// evaluateSequenceInPlace evaluates each file on its own and writes the results back into it. Each file is
+// written once it's evaluated, unless all or nothing is set where the files are only written when all of them
+// were evaluated successfully.
+func evaluateSequenceInPlace(expression string, filenames []string, diffWriter io.Writer) error {
+	decoder, err := configureDecoder(false)
+	if err != nil {
+		return err
+	}
+	// the same evaluator keeps the file index counting across the files
+	streamEvaluator := yqlib.NewStreamEvaluator()
+
+	var files []*inPlaceFile
+	var pending []*inPlaceFile
+	printedAnything := false
+	for _, filename := range filenames {
+		var file *inPlaceFile
+		file, err = newInPlaceFile(filename, diffWriter)
+		if err != nil {
+			break
+		}
+		files = append(files, file)
+		err = streamEvaluator.EvaluateFiles(expression, []string{file.filename}, file.printer, decoder)
+		printedAnything = printedAnything || file.printer.PrintedAnything()
+
+		pending = append(pending, file)
+		if !allOrNothing {
+			err = finishInPlaceFiles(pending, err)
+			pending = nil
+		}
+		if err != nil {
+			break
+		}
+	}
+	err = finishInPlaceFiles(pending, err)
+
+	if err == nil && exitStatus && !printedAnything {
+		return errors.New("no matches found")
+	} else if err == nil {
+		return changedFilesError(changedInPlaceFiles(files))
+	}
+	return err
+}
+
+// evaluateAllInPlace evaluates all the files together, then writes each result back into the file it came from.
+// Like evaluating them in sequence, files without any results are emptied.
+func evaluateAllInPlace(expression string, filenames []string, diffWriter io.Writer) error {
+	files := make([]*inPlaceFile, 0, len(filenames))
+	printers := make([]yqlib.Printer, 0, len(filenames))
+	evaluatedFilenames := make([]string, 0, len(filenames))
+	var err error
+	for _, filename := range filenames {
+		var file *inPlaceFile
+		file, err = newInPlaceFile(filename, diffWriter)
+		if err != nil {
+			break
+		}
+		files = append(files, file)
+		printers = append(printers, file.printer)
+		evaluatedFilenames = append(evaluatedFilenames, file.filename)
+	}
+
+	printer := yqlib.NewFileRoutingPrinter(printers)
+	if err == nil {
+		var decoder yqlib.Decoder
+		if decoder, err = configureDecoder(true); err == nil {
+			err = yqlib.NewAllAtOnceEvaluator().EvaluateFiles(expression, evaluatedFilenames, printer, decoder)
+		}
+	}
+
+	// the files are evaluated together, so if that fails none of them are written
+	err = finishInPlaceFiles(files, err)
+
+	if err == nil && exitStatus && !printer.PrintedAnything() {
+		return errors.New("no matches found")
+	} else if err == nil {
+		return changedFilesError(changedInPlaceFiles(files))
+	}
+	return err
+}
@@ -25,6 +25,9 @@ type CandidateNode struct {
 	// (e.g. top level cross document merge). This property does not propagate to child nodes.
 	EvaluateTogether bool
 	IsMapKey         bool
+	// the blank lines before map entries and sequence items in the source, kept when preserving the
+	// formatting. Only the yaml encoder writes them out.
+	BlankLines map[*yaml.Node]int
 }
 
 func (n *CandidateNode) GetKey() string {
@@ -62,38 +65,41 @@ func (n *CandidateNode) CreateChildInMap(key *yaml.Node, node *yaml.Node) *Candi
 		value = key.Value
 	}
 	return &CandidateNode{
-		Node:      node,
-		Path:      n.createChildPath(value),
-		Parent:    n,
-		Key:       key,
-		Document:  n.Document,
-		Filename:  n.Filename,
-		FileIndex: n.FileIndex,
+		Node:       node,
+		Path:       n.createChildPath(value),
+		Parent:     n,
+		Key:        key,
+		Document:   n.Document,
+		Filename:   n.Filename,
+		FileIndex:  n.FileIndex,
+		BlankLines: n.BlankLines,
 	}
 }
 
 func (n *CandidateNode) CreateChildInArray(index int, node *yaml.Node) *CandidateNode {
 	return &CandidateNode{
-		Node:      node,
-		Path:      n.createChildPath(index),
-		Parent:    n,
-		Key:       &yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprintf("%v", index), Tag: "!!int"},
-		Document:  n.Document,
-		Filename:  n.Filename,
-		FileIndex: n.FileIndex,
+		Node:       node,
+		Path:       n.createChildPath(index),
+		Parent:     n,
+		Key:        &yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprintf("%v", index), Tag: "!!int"},
+		Document:   n.Document,
+		Filename:   n.Filename,
+		FileIndex:  n.FileIndex,
+		BlankLines: n.BlankLines,
 	}
 }
 
 func (n *CandidateNode) CreateReplacement(node *yaml.Node) *CandidateNode {
 	return &CandidateNode{
-		Node:      node,
-		Path:      n.createChildPath(nil),
-		Parent:    n.Parent,
-		Key:       n.Key,
-		IsMapKey:  n.IsMapKey,
-		Document:  n.Document,
-		Filename:  n.Filename,
-		FileIndex: n.FileIndex,
+		Node:       node,
+		Path:       n.createChildPath(nil),
+		Parent:     n.Parent,
+		Key:        n.Key,
+		IsMapKey:   n.IsMapKey,
+		Document:   n.Document,
+		Filename:   n.Filename,
+		FileIndex:  n.FileIndex,
+		BlankLines: n.BlankLines,
 	}
 }
 
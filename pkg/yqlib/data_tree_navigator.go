@@ -17,6 +17,8 @@ type DataTreeNavigator interface {
 }
 
 type dataTreeNavigator struct {
+	// inputs are the documents still to be evaluated, for the input and inputs operators
+	inputs documentInputs
 }
 
 func NewDataTreeNavigator() DataTreeNavigator {
@@ -18,6 +18,12 @@ const (
 	TSVObjectInputFormat
 	TomlInputFormat
 	UriInputFormat
+	Base32InputFormat
+	HexInputFormat
+	GzipInputFormat
+	HtmlInputFormat
+	JSONEscapeInputFormat
+	TextInputFormat
 )
 
 type Decoder interface {
@@ -41,8 +47,20 @@ func InputFormatFromString(format string) (InputFormat, error) {
 		return TSVObjectInputFormat, nil
 	case "toml":
 		return TomlInputFormat, nil
+	case "base32":
+		return Base32InputFormat, nil
+	case "hex":
+		return HexInputFormat, nil
+	case "gzip":
+		return GzipInputFormat, nil
+	case "html":
+		return HtmlInputFormat, nil
+	case "json-escape":
+		return JSONEscapeInputFormat, nil
+	case "text":
+		return TextInputFormat, nil
 	default:
-		return 0, fmt.Errorf("unknown format '%v' please use [yaml|json|props|csv|tsv|xml|toml]", format)
+		return 0, fmt.Errorf("unknown format '%v' please use [yaml|json|props|csv|tsv|xml|toml|base32|hex|gzip|html|json-escape|text]", format)
 	}
 }
 
@@ -0,0 +1,71 @@
+package yqlib
+
+import (
+	"fmt"
+	"io"
+)
+
+// fileDecoder is a decoder that depends on the file being read, the evaluators select the file before
+// initialising it with the file's reader.
+type fileDecoder interface {
+	Decoder
+	SelectFile(filename string) error
+}
+
+// initDecoder initialises the decoder to read the given file.
+func initDecoder(decoder Decoder, filename string, reader io.Reader) error {
+	if fileDecoder, ok := decoder.(fileDecoder); ok {
+		if err := fileDecoder.SelectFile(filename); err != nil {
+			return err
+		}
+	}
+	return decoder.Init(reader)
+}
+
+// byFileDecoder decodes each file with the decoder of its format, so files of different formats
+// can be evaluated together.
+type byFileDecoder struct {
+	formatForFile func(filename string) (InputFormat, error)
+	createDecoder func(format InputFormat) (Decoder, error)
+	// the decoders are kept across files, as some track the files they have read (e.g. yaml leading content)
+	decoders map[InputFormat]Decoder
+	current  Decoder
+}
+
+// NewByFileDecoder creates a decoder that reads each file with the decoder created for the format of that file.
+func NewByFileDecoder(formatForFile func(filename string) (InputFormat, error), createDecoder func(format InputFormat) (Decoder, error)) Decoder {
+	return &byFileDecoder{
+		formatForFile: formatForFile,
+		createDecoder: createDecoder,
+		decoders:      map[InputFormat]Decoder{},
+	}
+}
+
+func (dec *byFileDecoder) SelectFile(filename string) error {
+	format, err := dec.formatForFile(filename)
+	if err != nil {
+		return err
+	}
+	decoder, exists := dec.decoders[format]
+	if !exists {
+		decoder, err = dec.createDecoder(format)
+		if err != nil {
+			return err
+		}
+		dec.decoders[format] = decoder
+	}
+	log.Debugf("decoding '%v' with the decoder for format %v", filename, format)
+	dec.current = decoder
+	return nil
+}
+
+func (dec *byFileDecoder) Init(reader io.Reader) error {
+	if dec.current == nil {
+		return fmt.Errorf("no file selected to decode")
+	}
+	return dec.current.Init(reader)
+}
+
+func (dec *byFileDecoder) Decode() (*CandidateNode, error) {
+	return dec.current.Decode()
+}
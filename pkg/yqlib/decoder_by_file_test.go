@@ -0,0 +1,74 @@
+package yqlib
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+func newTestByFileDecoder() Decoder {
+	return NewByFileDecoder(func(filename string) (InputFormat, error) {
+		return InputFormatFromString(FormatFromFilename(filename))
+	}, func(format InputFormat) (Decoder, error) {
+		switch format {
+		case JsonInputFormat:
+			return NewJSONDecoder(), nil
+		case TomlInputFormat:
+			return NewTomlDecoder(), nil
+		}
+		return NewYamlDecoder(ConfiguredYamlPreferences), nil
+	})
+}
+
+func writeByFileTestFiles(t *testing.T) []string {
+	directory := t.TempDir()
+	files := map[string]string{
+		"base.yaml":  "a: 1\nb:\n  c: 2\n",
+		"over.json":  `{"b": {"d": 3}}`,
+		"extra.toml": "[b]\ne = 4\n",
+	}
+	var filenames []string
+	for _, name := range []string{"base.yaml", "over.json", "extra.toml"} {
+		filename := filepath.Join(directory, name)
+		if err := os.WriteFile(filename, []byte(files[name]), 0600); err != nil {
+			t.Fatal(err)
+		}
+		filenames = append(filenames, filename)
+	}
+	return filenames
+}
+
+func TestByFileDecoderEvaluateAll(t *testing.T) {
+	var output bytes.Buffer
+	writer := bufio.NewWriter(&output)
+	printer := NewSimpleYamlPrinter(writer, YamlOutputFormat, true, false, 2, true)
+
+	err := NewAllAtOnceEvaluator().EvaluateFiles(". as $item ireduce ({}; . * $item)", writeByFileTestFiles(t), printer, newTestByFileDecoder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Flush()
+	test.AssertResult(t, "a: 1\nb:\n  c: 2\n  d: 3\n  e: 4\n", output.String())
+}
+
+func TestByFileDecoderEvaluateSequence(t *testing.T) {
+	var output bytes.Buffer
+	writer := bufio.NewWriter(&output)
+	printer := NewSimpleYamlPrinter(writer, YamlOutputFormat, true, false, 2, false)
+
+	err := NewStreamEvaluator().EvaluateFiles(".b", writeByFileTestFiles(t), printer, newTestByFileDecoder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Flush()
+	test.AssertResult(t, "c: 2\nd: 3\ne: 4\n", output.String())
+}
+
+func TestByFileDecoderNoFileSelected(t *testing.T) {
+	err := newTestByFileDecoder().Init(bytes.NewReader(nil))
+	test.AssertResult(t, "no file selected to decode", err.Error())
+}
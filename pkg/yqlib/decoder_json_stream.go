@@ -0,0 +1,135 @@
+//go:build !yq_nojson
+
+package yqlib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// jsonStreamCollection tracks the array or object that is being read.
+type jsonStreamCollection struct {
+	path     []*yaml.Node
+	isArray  bool
+	children int
+	key      string
+	// expectingKey is set when the next token in the object is a key
+	expectingKey bool
+}
+
+// jsonStreamDecoder reads json token by token, returning the [path, leaf] events as they're found,
+// so only the current path is held in memory rather than the whole document.
+type jsonStreamDecoder struct {
+	decoder     *json.Decoder
+	collections []*jsonStreamCollection
+}
+
+func NewJSONStreamDecoder() Decoder {
+	return &jsonStreamDecoder{}
+}
+
+func (dec *jsonStreamDecoder) Init(reader io.Reader) error {
+	dec.decoder = json.NewDecoder(reader)
+	dec.decoder.UseNumber()
+	dec.collections = nil
+	return nil
+}
+
+// currentPath is the path of the next value.
+func (dec *jsonStreamDecoder) currentPath() []*yaml.Node {
+	path := []*yaml.Node{}
+	for _, collection := range dec.collections {
+		if collection.isArray {
+			path = append(path, createIndexNode(collection.children))
+		} else {
+			path = append(path, createStringScalarNode(collection.key))
+		}
+	}
+	return path
+}
+
+// endValue moves on from the value that was just read.
+func (dec *jsonStreamDecoder) endValue() {
+	if len(dec.collections) == 0 {
+		return
+	}
+	collection := dec.collections[len(dec.collections)-1]
+	collection.children++
+	collection.expectingKey = !collection.isArray
+}
+
+func (dec *jsonStreamDecoder) Decode() (*CandidateNode, error) {
+	for {
+		token, err := dec.decoder.Token()
+		if err != nil {
+			if err == io.EOF && len(dec.collections) > 0 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+
+		if len(dec.collections) > 0 {
+			collection := dec.collections[len(dec.collections)-1]
+			if collection.expectingKey {
+				if key, ok := token.(string); ok {
+					collection.key = key
+					collection.expectingKey = false
+					continue
+				}
+			}
+		}
+
+		switch token {
+		case json.Delim('['), json.Delim('{'):
+			dec.collections = append(dec.collections, &jsonStreamCollection{
+				path:         dec.currentPath(),
+				isArray:      token == json.Delim('['),
+				expectingKey: token == json.Delim('{'),
+			})
+		case json.Delim(']'), json.Delim('}'):
+			collection := dec.collections[len(dec.collections)-1]
+			dec.collections = dec.collections[:len(dec.collections)-1]
+			dec.endValue()
+			if collection.children == 0 {
+				empty := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+				if !collection.isArray {
+					empty = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Style: yaml.FlowStyle}
+				}
+				return createStreamEventDocument(createStreamEvent(collection.path, empty)), nil
+			}
+			lastKey := createStringScalarNode(collection.key)
+			if collection.isArray {
+				lastKey = createIndexNode(collection.children - 1)
+			}
+			return createStreamEventDocument(createStreamEvent(appendStreamPath(collection.path, lastKey), nil)), nil
+		default:
+			leaf, err := dec.createLeaf(token)
+			if err != nil {
+				return nil, err
+			}
+			event := createStreamEvent(dec.currentPath(), leaf)
+			dec.endValue()
+			return createStreamEventDocument(event), nil
+		}
+	}
+}
+
+func (dec *jsonStreamDecoder) createLeaf(token json.Token) (*yaml.Node, error) {
+	switch value := token.(type) {
+	case nil:
+		return createScalarNode(nil, "null"), nil
+	case bool, string:
+		return createScalarNode(value, fmt.Sprintf("%v", value)), nil
+	case json.Number:
+		if strings.ContainsAny(value.String(), ".eE") {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: value.String()}, nil
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: value.String()}, nil
+	default:
+		return nil, fmt.Errorf("unexpected json token %v", token)
+	}
+}
@@ -0,0 +1,41 @@
+package yqlib
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// lineDecoder reads each line of the input as a string document.
+type lineDecoder struct {
+	reader *bufio.Reader
+}
+
+func NewLineDecoder() Decoder {
+	return &lineDecoder{}
+}
+
+func (dec *lineDecoder) Init(reader io.Reader) error {
+	dec.reader = bufio.NewReader(reader)
+	return nil
+}
+
+func (dec *lineDecoder) Decode() (*CandidateNode, error) {
+	line, err := dec.reader.ReadString('\n')
+	if errors.Is(err, io.EOF) && line == "" {
+		return nil, io.EOF
+	} else if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	return &CandidateNode{
+		Node: &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Tag:   "!!str",
+			Value: line,
+		},
+	}, nil
+}
@@ -0,0 +1,55 @@
+package yqlib
+
+import (
+	"errors"
+	"io"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// slurpDecoder reads all the documents decoded from the input into a single array document.
+type slurpDecoder struct {
+	decoder  Decoder
+	finished bool
+}
+
+// NewSlurpDecoder wraps the decoder, so that all the documents it decodes from each input are
+// gathered into one array.
+func NewSlurpDecoder(decoder Decoder) Decoder {
+	return &slurpDecoder{decoder: decoder}
+}
+
+func (dec *slurpDecoder) SelectFile(filename string) error {
+	if fileDecoder, ok := dec.decoder.(fileDecoder); ok {
+		return fileDecoder.SelectFile(filename)
+	}
+	return nil
+}
+
+func (dec *slurpDecoder) Init(reader io.Reader) error {
+	dec.finished = false
+	return dec.decoder.Init(reader)
+}
+
+func (dec *slurpDecoder) Decode() (*CandidateNode, error) {
+	if dec.finished {
+		return nil, io.EOF
+	}
+	sequence := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for {
+		candidate, err := dec.decoder.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		sequence.Content = append(sequence.Content, unwrapDoc(candidate.Node))
+	}
+	dec.finished = true
+	return &CandidateNode{
+		Node: &yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{sequence},
+		},
+	}, nil
+}
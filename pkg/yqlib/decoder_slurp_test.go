@@ -0,0 +1,77 @@
+package yqlib
+
+import (
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+var lineDecoderScenarios = []formatScenario{
+	{
+		description: "each line is a string",
+		input:       "cat\n3\ntrue\n",
+		expression:  "tag",
+		expected:    "\"!!str\"\n\"!!str\"\n\"!!str\"\n",
+	},
+	{
+		description: "windows line endings and no trailing newline",
+		input:       "a\r\n\r\nb",
+		expected:    "\"a\"\n\"\"\n\"b\"\n",
+	},
+	{
+		description: "empty input",
+		input:       "",
+		expected:    "",
+	},
+}
+
+var slurpDecoderScenarios = []struct {
+	formatScenario
+	decoder func() Decoder
+}{
+	{
+		formatScenario: formatScenario{
+			description: "yaml documents",
+			input:       "a: 1\n---\na: 2\n",
+			expected:    "[{\"a\":1},{\"a\":2}]\n",
+		},
+		decoder: func() Decoder { return NewYamlDecoder(ConfiguredYamlPreferences) },
+	},
+	{
+		formatScenario: formatScenario{
+			description: "ndjson",
+			input:       "{\"a\": 1}\n{\"a\": 2}\n",
+			expression:  "map(.a)",
+			expected:    "[1,2]\n",
+		},
+		decoder: NewJSONDecoder,
+	},
+	{
+		formatScenario: formatScenario{
+			description: "lines",
+			input:       "x\ny\n",
+			expected:    "[\"x\",\"y\"]\n",
+		},
+		decoder: NewLineDecoder,
+	},
+	{
+		formatScenario: formatScenario{
+			description: "empty input",
+			input:       "",
+			expected:    "[]\n",
+		},
+		decoder: NewLineDecoder,
+	},
+}
+
+func TestLineDecoderScenarios(t *testing.T) {
+	for _, s := range lineDecoderScenarios {
+		test.AssertResultWithContext(t, s.expected, mustProcessFormatScenario(s, NewLineDecoder(), NewJSONEncoder(0, false, false)), s.description)
+	}
+}
+
+func TestSlurpDecoderScenarios(t *testing.T) {
+	for _, s := range slurpDecoderScenarios {
+		test.AssertResultWithContext(t, s.expected, mustProcessFormatScenario(s.formatScenario, NewSlurpDecoder(s.decoder()), NewJSONEncoder(0, false, false)), s.description)
+	}
+}
@@ -0,0 +1,54 @@
+package yqlib
+
+import (
+	"io"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// streamDecoder converts each document decoded from the input into its [path, leaf] events.
+type streamDecoder struct {
+	decoder Decoder
+	events  []*yaml.Node
+}
+
+// NewStreamDecoder wraps the decoder, so that each document it decodes is returned as a series of
+// [path, leaf] event documents, like the tostream operator. Note that the documents are still decoded
+// in full, see NewJSONStreamDecoder for incrementally decoding json.
+func NewStreamDecoder(decoder Decoder) Decoder {
+	return &streamDecoder{decoder: decoder}
+}
+
+func (dec *streamDecoder) SelectFile(filename string) error {
+	if fileDecoder, ok := dec.decoder.(fileDecoder); ok {
+		return fileDecoder.SelectFile(filename)
+	}
+	return nil
+}
+
+func (dec *streamDecoder) Init(reader io.Reader) error {
+	dec.events = nil
+	return dec.decoder.Init(reader)
+}
+
+func (dec *streamDecoder) Decode() (*CandidateNode, error) {
+	if len(dec.events) == 0 {
+		candidate, err := dec.decoder.Decode()
+		if err != nil {
+			return nil, err
+		}
+		dec.events = appendStreamEvents(nil, []*yaml.Node{}, candidate.Node)
+	}
+	event := dec.events[0]
+	dec.events = dec.events[1:]
+	return createStreamEventDocument(event), nil
+}
+
+func createStreamEventDocument(event *yaml.Node) *CandidateNode {
+	return &CandidateNode{
+		Node: &yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{event},
+		},
+	}
+}
@@ -0,0 +1,53 @@
+//go:build !yq_nojson
+
+package yqlib
+
+import (
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+var streamDecoderScenarios = []formatScenario{
+	{
+		description: "nested collections",
+		input:       `{"a": [1, {"b": "cat"}], "c": {}, "d": []}`,
+		expected:    "[[\"a\",0],1]\n[[\"a\",1,\"b\"],\"cat\"]\n[[\"a\",1,\"b\"]]\n[[\"a\",1]]\n[[\"c\"],{}]\n[[\"d\"],[]]\n[[\"d\"]]\n",
+	},
+	{
+		description: "scalars",
+		input:       `[null, true, -1.5, "x"]`,
+		expected:    "[[0],null]\n[[1],true]\n[[2],-1.5]\n[[3],\"x\"]\n[[3]]\n",
+	},
+	{
+		description: "many documents",
+		input:       "3\n{\"a\": 1}\n[]\n",
+		expected:    "[[],3]\n[[\"a\"],1]\n[[\"a\"]]\n[[],[]]\n",
+	},
+}
+
+func TestJSONStreamDecoderScenarios(t *testing.T) {
+	for _, s := range streamDecoderScenarios {
+		test.AssertResultWithContext(t, s.expected, mustProcessFormatScenario(s, NewJSONStreamDecoder(), NewJSONEncoder(0, false, false)), s.description)
+	}
+}
+
+func TestStreamDecoderScenarios(t *testing.T) {
+	for _, s := range streamDecoderScenarios {
+		test.AssertResultWithContext(t, s.expected, mustProcessFormatScenario(s, NewStreamDecoder(NewJSONDecoder()), NewJSONEncoder(0, false, false)), s.description)
+	}
+}
+
+func TestStreamDecoderOfYaml(t *testing.T) {
+	s := formatScenario{input: "a: [1, {b: cat}]\n---\nc: d\n"}
+	result := mustProcessFormatScenario(s, NewStreamDecoder(NewYamlDecoder(ConfiguredYamlPreferences)), NewJSONEncoder(0, false, false))
+	test.AssertResult(t, "[[\"a\",0],1]\n[[\"a\",1,\"b\"],\"cat\"]\n[[\"a\",1,\"b\"]]\n[[\"a\",1]]\n[[\"a\"]]\n[[\"c\"],\"d\"]\n[[\"c\"]]\n", result)
+}
+
+func TestJSONStreamDecoderUnexpectedEnd(t *testing.T) {
+	_, err := processFormatScenario(formatScenario{input: `{"a": [1`}, NewJSONStreamDecoder(), NewJSONEncoder(0, false, false))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	test.AssertResult(t, "bad file 'sample.yml': unexpected EOF", err.Error())
+}
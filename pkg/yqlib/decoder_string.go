@@ -0,0 +1,129 @@
+package yqlib
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"html"
+	"io"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// stringDecoder reads the whole input and decodes it into a single string value.
+type stringDecoder struct {
+	reader       io.Reader
+	finished     bool
+	readAnything bool
+	transform    func(value string) (string, error)
+}
+
+func NewBase32Decoder() Decoder {
+	return &stringDecoder{transform: func(value string) (string, error) {
+		value = strings.TrimSpace(value)
+		if len(value)%8 != 0 {
+			value = value + strings.Repeat("=", 8-len(value)%8)
+		}
+		decoded, err := base32.StdEncoding.DecodeString(value)
+		return string(decoded), err
+	}}
+}
+
+func NewHexDecoder() Decoder {
+	return &stringDecoder{transform: func(value string) (string, error) {
+		decoded, err := hex.DecodeString(strings.TrimSpace(value))
+		return string(decoded), err
+	}}
+}
+
+// NewGzipDecoder base64 decodes the input and then decompresses it.
+func NewGzipDecoder() Decoder {
+	return &stringDecoder{transform: func(value string) (string, error) {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return "", nil
+		}
+		compressed, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return "", err
+		}
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return "", err
+		}
+		// ignore G110 gosec issue of decompression bombs, the input is
+		// supplied by the person running yq.
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(reader); err != nil { // #nosec
+			return "", err
+		}
+		return buf.String(), reader.Close()
+	}}
+}
+
+func NewHtmlDecoder() Decoder {
+	return &stringDecoder{transform: func(value string) (string, error) {
+		return html.UnescapeString(value), nil
+	}}
+}
+
+// NewJSONUnescapeDecoder unescapes the contents of a json string, given without the surrounding quotes.
+func NewJSONUnescapeDecoder() Decoder {
+	return &stringDecoder{transform: func(value string) (string, error) {
+		var unescaped string
+		err := json.Unmarshal([]byte(`"`+value+`"`), &unescaped)
+		return unescaped, err
+	}}
+}
+
+// NewTextDecoder reads the input as a single string.
+func NewTextDecoder() Decoder {
+	return &stringDecoder{transform: func(value string) (string, error) {
+		return value, nil
+	}}
+}
+
+func (dec *stringDecoder) Init(reader io.Reader) error {
+	dec.reader = reader
+	dec.readAnything = false
+	dec.finished = false
+	return nil
+}
+
+func (dec *stringDecoder) Decode() (*CandidateNode, error) {
+	if dec.finished {
+		return nil, io.EOF
+	}
+
+	buf := new(bytes.Buffer)
+
+	if _, err := buf.ReadFrom(dec.reader); err != nil {
+		return nil, err
+	}
+	if buf.Len() == 0 {
+		dec.finished = true
+
+		// if we've read _only_ an empty string, lets return that
+		// otherwise if we've already read some bytes, and now we get
+		// an empty string, then we are done.
+		if dec.readAnything {
+			return nil, io.EOF
+		}
+	}
+	newValue, err := dec.transform(buf.String())
+	if err != nil {
+		return nil, err
+	}
+	dec.readAnything = true
+	return &CandidateNode{
+		Node: &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Tag:   "!!str",
+			Value: newValue,
+		},
+	}, nil
+}
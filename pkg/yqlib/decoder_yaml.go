@@ -20,6 +20,9 @@ type yamlDecoder struct {
 	leadingContent string
 	bufferRead     bytes.Buffer
 
+	// the lines of the current document, to find the blank lines between entries
+	source *yamlSourceLines
+
 	readAnything bool
 	firstFile    bool
 }
@@ -89,6 +92,10 @@ func (dec *yamlDecoder) Init(reader io.Reader) error {
 		// then we can read the comments from bufferRead
 		readerToUse = io.TeeReader(reader, &dec.bufferRead)
 	}
+	if dec.prefs.PreserveFormatting {
+		dec.source = newYamlSourceLines()
+		readerToUse = io.TeeReader(readerToUse, dec.source)
+	}
 	dec.leadingContent = leadingContent
 	dec.readAnything = false
 	dec.decoder = *yaml.NewDecoder(readerToUse)
@@ -119,6 +126,12 @@ func (dec *yamlDecoder) Decode() (*CandidateNode, error) {
 	candidateNode := &CandidateNode{
 		Node: &dataBucket,
 	}
+	if dec.prefs.PreserveFormatting {
+		// the entries of this document only look back as far as its first line
+		dec.source.dropBefore(dataBucket.Line)
+		candidateNode.BlankLines = map[*yaml.Node]int{}
+		recordBlankLines(&dataBucket, dec.source, candidateNode.BlankLines)
+	}
 
 	if dec.leadingContent != "" {
 		candidateNode.LeadingContent = dec.leadingContent
@@ -0,0 +1,188 @@
+package yqlib
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// canonicalJSONEncoder writes nodes as RFC 8785 (JCS) canonical json:
+// no whitespace, map keys sorted by their utf-16 code units and numbers
+// serialised in their shortest ECMAScript form.
+type canonicalJSONEncoder struct {
+}
+
+func NewCanonicalJSONEncoder() Encoder {
+	return &canonicalJSONEncoder{}
+}
+
+func (e *canonicalJSONEncoder) CanHandleAliases() bool {
+	return false
+}
+
+func (e *canonicalJSONEncoder) PrintDocumentSeparator(writer io.Writer) error {
+	return nil
+}
+
+func (e *canonicalJSONEncoder) PrintLeadingContent(writer io.Writer, content string) error {
+	return nil
+}
+
+func (e *canonicalJSONEncoder) Encode(writer io.Writer, node *yaml.Node) error {
+	var builder strings.Builder
+	if err := writeCanonicalJSON(&builder, unwrapDoc(node)); err != nil {
+		return err
+	}
+	return writeString(writer, builder.String())
+}
+
+func writeCanonicalJSON(builder *strings.Builder, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return writeCanonicalJSON(builder, unwrapDoc(node))
+	case yaml.AliasNode:
+		return writeCanonicalJSON(builder, node.Alias)
+	case yaml.SequenceNode:
+		builder.WriteString("[")
+		for i, child := range node.Content {
+			if i > 0 {
+				builder.WriteString(",")
+			}
+			if err := writeCanonicalJSON(builder, child); err != nil {
+				return err
+			}
+		}
+		builder.WriteString("]")
+		return nil
+	case yaml.MappingNode:
+		return writeCanonicalJSONMap(builder, node)
+	}
+	return writeCanonicalJSONScalar(builder, node)
+}
+
+func writeCanonicalJSONMap(builder *strings.Builder, node *yaml.Node) error {
+	values := make(map[string]*yaml.Node, len(node.Content)/2)
+	keys := make([]string, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if _, exists := values[key]; !exists {
+			keys = append(keys, key)
+		}
+		values[key] = node.Content[i+1]
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return compareUtf16(keys[i], keys[j]) < 0
+	})
+
+	builder.WriteString("{")
+	for i, key := range keys {
+		if i > 0 {
+			builder.WriteString(",")
+		}
+		writeCanonicalJSONString(builder, key)
+		builder.WriteString(":")
+		if err := writeCanonicalJSON(builder, values[key]); err != nil {
+			return err
+		}
+	}
+	builder.WriteString("}")
+	return nil
+}
+
+func writeCanonicalJSONScalar(builder *strings.Builder, node *yaml.Node) error {
+	switch guessTagFromCustomType(node) {
+	case "!!null":
+		builder.WriteString("null")
+	case "!!bool":
+		var value bool
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		builder.WriteString(strconv.FormatBool(value))
+	case "!!int":
+		_, value, err := parseInt64(node.Value)
+		if err != nil {
+			return err
+		}
+		builder.WriteString(canonicalJSONNumber(float64(value)))
+	case "!!float":
+		var value float64
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			return fmt.Errorf("cannot encode %v as canonical json, infinity and NaN are not valid json numbers", node.Value)
+		}
+		builder.WriteString(canonicalJSONNumber(value))
+	default:
+		writeCanonicalJSONString(builder, node.Value)
+	}
+	return nil
+}
+
+// canonicalJSONNumber formats the number the same way ECMAScript's
+// Number.prototype.toString does, as required by RFC 8785.
+func canonicalJSONNumber(value float64) string {
+	if value == 0 {
+		return "0"
+	}
+	abs := math.Abs(value)
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	formatted := strconv.FormatFloat(value, 'e', -1, 64)
+	mantissa, exponent, _ := strings.Cut(formatted, "e")
+	sign := exponent[:1]
+	exponent = strings.TrimLeft(exponent[1:], "0")
+	return mantissa + "e" + sign + exponent
+}
+
+func writeCanonicalJSONString(builder *strings.Builder, value string) {
+	builder.WriteString(`"`)
+	for _, r := range value {
+		switch r {
+		case '"':
+			builder.WriteString(`\"`)
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\b':
+			builder.WriteString(`\b`)
+		case '\f':
+			builder.WriteString(`\f`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				builder.WriteString(fmt.Sprintf(`\u%04x`, r))
+			} else {
+				builder.WriteRune(r)
+			}
+		}
+	}
+	builder.WriteString(`"`)
+}
+
+func compareUtf16(a string, b string) int {
+	aUnits := utf16.Encode([]rune(a))
+	bUnits := utf16.Encode([]rune(b))
+	for i := 0; i < len(aUnits) && i < len(bUnits); i++ {
+		if aUnits[i] != bUnits[i] {
+			if aUnits[i] < bUnits[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(aUnits) - len(bUnits)
+}
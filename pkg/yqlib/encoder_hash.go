@@ -0,0 +1,68 @@
+package yqlib
+
+import (
+	// ignore CWE-327 gosec issue of using weak hashes, these are offered
+	// as checksums and not for anything that needs to be secure.
+	"crypto/md5"  // #nosec
+	"crypto/sha1" // #nosec
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// hashEncoder prints the hex digest of a node. Strings are hashed as is,
+// everything else is hashed in its canonical json form so that the digest
+// does not depend on key order or yaml styling.
+type hashEncoder struct {
+	newHash func() hash.Hash
+}
+
+func NewSha256Encoder() Encoder {
+	return &hashEncoder{newHash: sha256.New}
+}
+
+func NewSha1Encoder() Encoder {
+	return &hashEncoder{newHash: sha1.New} // #nosec
+}
+
+func NewMd5Encoder() Encoder {
+	return &hashEncoder{newHash: md5.New} // #nosec
+}
+
+func NewCrc32Encoder() Encoder {
+	return &hashEncoder{newHash: func() hash.Hash { return crc32.NewIEEE() }}
+}
+
+func (e *hashEncoder) CanHandleAliases() bool {
+	return false
+}
+
+func (e *hashEncoder) PrintDocumentSeparator(writer io.Writer) error {
+	return nil
+}
+
+func (e *hashEncoder) PrintLeadingContent(writer io.Writer, content string) error {
+	return nil
+}
+
+func (e *hashEncoder) Encode(writer io.Writer, originalNode *yaml.Node) error {
+	node := unwrapDoc(originalNode)
+	content := node.Value
+	if node.Kind != yaml.ScalarNode || guessTagFromCustomType(node) != "!!str" {
+		var builder strings.Builder
+		if err := writeCanonicalJSON(&builder, node); err != nil {
+			return err
+		}
+		content = builder.String()
+	}
+	digest := e.newHash()
+	if _, err := digest.Write([]byte(content)); err != nil {
+		return err
+	}
+	return writeString(writer, hex.EncodeToString(digest.Sum(nil)))
+}
@@ -0,0 +1,33 @@
+package yqlib
+
+import (
+	"io"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// jsonSchemaEncoder writes the JSON schema inferred from each node, rather than the node itself. eval-all infers a
+// single schema from all the documents with infer_schema instead.
+type jsonSchemaEncoder struct {
+	jsonEncoder Encoder
+}
+
+func NewJSONSchemaEncoder(indent int, colorise bool) Encoder {
+	return &jsonSchemaEncoder{jsonEncoder: NewJSONEncoder(indent, colorise, false)}
+}
+
+func (e *jsonSchemaEncoder) CanHandleAliases() bool {
+	return false
+}
+
+func (e *jsonSchemaEncoder) PrintDocumentSeparator(writer io.Writer) error {
+	return nil
+}
+
+func (e *jsonSchemaEncoder) PrintLeadingContent(writer io.Writer, content string) error {
+	return nil
+}
+
+func (e *jsonSchemaEncoder) Encode(writer io.Writer, node *yaml.Node) error {
+	return e.jsonEncoder.Encode(writer, inferSchema(node))
+}
@@ -0,0 +1,107 @@
+package yqlib
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// stringEncoder encodes a single string value, such as a hex or base32 encoding.
+// A trailing newline is printed so the output is tidy on the command line,
+// the encode operators drop it again.
+type stringEncoder struct {
+	name        string
+	stringsOnly bool
+	transform   func(value string) (string, error)
+}
+
+func NewBase32Encoder() Encoder {
+	return &stringEncoder{name: "base32", stringsOnly: true, transform: func(value string) (string, error) {
+		return base32.StdEncoding.EncodeToString([]byte(value)), nil
+	}}
+}
+
+func NewHexEncoder() Encoder {
+	return &stringEncoder{name: "hex", stringsOnly: true, transform: func(value string) (string, error) {
+		return hex.EncodeToString([]byte(value)), nil
+	}}
+}
+
+// NewGzipEncoder gzips the string and base64 encodes the compressed bytes.
+func NewGzipEncoder() Encoder {
+	return &stringEncoder{name: "gzip", stringsOnly: true, transform: func(value string) (string, error) {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		if _, err := writer.Write([]byte(value)); err != nil {
+			return "", err
+		}
+		if err := writer.Close(); err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(compressed.Bytes()), nil
+	}}
+}
+
+func NewHtmlEncoder() Encoder {
+	return &stringEncoder{name: "html", stringsOnly: true, transform: func(value string) (string, error) {
+		return html.EscapeString(value), nil
+	}}
+}
+
+// NewJSONEscapeEncoder escapes the string so it can be embedded in a json string,
+// without the surrounding quotes.
+func NewJSONEscapeEncoder() Encoder {
+	return &stringEncoder{name: "json-escape", stringsOnly: true, transform: func(value string) (string, error) {
+		var builder strings.Builder
+		writeCanonicalJSONString(&builder, value)
+		escaped := builder.String()
+		return escaped[1 : len(escaped)-1], nil
+	}}
+}
+
+// NewTextEncoder prints scalars as they are, maps and arrays are printed as canonical json.
+func NewTextEncoder() Encoder {
+	return &stringEncoder{name: "text", transform: func(value string) (string, error) {
+		return value, nil
+	}}
+}
+
+func (e *stringEncoder) CanHandleAliases() bool {
+	return false
+}
+
+func (e *stringEncoder) PrintDocumentSeparator(writer io.Writer) error {
+	return nil
+}
+
+func (e *stringEncoder) PrintLeadingContent(writer io.Writer, content string) error {
+	return nil
+}
+
+func (e *stringEncoder) Encode(writer io.Writer, originalNode *yaml.Node) error {
+	node := unwrapDoc(originalNode)
+	value := node.Value
+	if node.Kind != yaml.ScalarNode || (e.stringsOnly && guessTagFromCustomType(node) != "!!str") {
+		if e.stringsOnly {
+			return fmt.Errorf("cannot encode %v as %v, can only operate on strings. Please first pipe through another encoding operator to convert the value to a string", node.Tag, e.name)
+		}
+		var builder strings.Builder
+		if err := writeCanonicalJSON(&builder, node); err != nil {
+			return err
+		}
+		value = builder.String()
+	}
+	encoded, err := e.transform(value)
+	if err != nil {
+		return err
+	}
+	return writeString(writer, encoded+"\n")
+}
@@ -79,9 +79,15 @@ func (ye *yamlEncoder) Encode(writer io.Writer, node *yaml.Node) error {
 		return writeString(writer, node.Value+"\n")
 	}
 
+	if hasYamlStyles(ye.prefs) {
+		node = deepClone(node)
+		applyYamlStyles(node, ye.prefs, false, false)
+	}
+
+	rewritesOutput := ye.prefs.PreserveFormatting || ye.prefs.IndentlessSequences || ye.prefs.LineWidth > 0
 	destination := writer
 	tempBuffer := bytes.NewBuffer(nil)
-	if ye.colorise {
+	if ye.colorise || rewritesOutput {
 		destination = tempBuffer
 	}
 
@@ -93,8 +99,19 @@ func (ye *yamlEncoder) Encode(writer io.Writer, node *yaml.Node) error {
 		return err
 	}
 
+	if !ye.colorise && !rewritesOutput {
+		return nil
+	}
+	encoded := tempBuffer.Bytes()
+	if ye.prefs.PreserveFormatting || ye.prefs.IndentlessSequences {
+		encoded = applyYamlFormatting(node, encoded, ye.prefs.IndentlessSequences)
+	}
+	if ye.prefs.LineWidth > 0 {
+		encoded = wrapYamlLines(encoded, ye.prefs.LineWidth, ye.indent)
+	}
 	if ye.colorise {
-		return colorizeAndPrint(tempBuffer.Bytes(), writer)
+		return colorizeAndPrint(encoded, writer)
 	}
-	return nil
+	_, err := writer.Write(encoded)
+	return err
 }
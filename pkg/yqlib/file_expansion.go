@@ -0,0 +1,247 @@
+package yqlib
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type FileExpansionPreferences struct {
+	// Include are the patterns of the files read from directories.
+	Include []string
+	// Exclude are the patterns of the files and directories to skip, in directories and globs.
+	Exclude []string
+	// GitIgnore skips the files ignored by .gitignore files.
+	GitIgnore bool
+}
+
+func NewDefaultFileExpansionPreferences() FileExpansionPreferences {
+	return FileExpansionPreferences{
+		Include:   []string{"*.yaml", "*.yml"},
+		Exclude:   []string{},
+		GitIgnore: true,
+	}
+}
+
+var ConfiguredFileExpansionPreferences = NewDefaultFileExpansionPreferences()
+
+// ExpandFiles replaces the directories in the arguments with the files in them, and the globs (that can
+// use ** to match any number of directories) with the files they match, in lexical order. Files and '-'
+// (stdin) are kept as they are.
+func ExpandFiles(args []string, prefs FileExpansionPreferences) ([]string, error) {
+	expanded := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "-" {
+			expanded = append(expanded, arg)
+			continue
+		}
+		// ignore CWE-22 gosec issue - that's more targeted for http based apps that run in a public directory,
+		// and ensuring that it's not possible to give a path to a file outside that directory.
+		info, err := os.Stat(arg) // #nosec
+		var files []string
+		switch {
+		case err == nil && !info.IsDir():
+			expanded = append(expanded, arg)
+			continue
+		case err == nil:
+			files, err = expandDirectory(arg, prefs)
+		case isGlob(arg):
+			files, err = expandGlob(arg, prefs)
+		default:
+			// let reading the file report that it's missing
+			expanded = append(expanded, arg)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no files found for '%v'", arg)
+		}
+		log.Debugf("expanded '%v' to %v", arg, files)
+		expanded = append(expanded, files...)
+	}
+	return expanded, nil
+}
+
+func isGlob(arg string) bool {
+	return strings.ContainsAny(arg, "*?[{")
+}
+
+func expandDirectory(directory string, prefs FileExpansionPreferences) ([]string, error) {
+	return walkFiles(directory, prefs, func(relativePath string, filename string) bool {
+		for _, include := range prefs.Include {
+			if matchesFilePattern(include, relativePath, filename) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func expandGlob(glob string, prefs FileExpansionPreferences) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	for _, pattern := range expandBraces(filepath.ToSlash(glob)) {
+		base, remainder := splitGlobBase(pattern)
+		if _, err := os.Stat(base); err != nil {
+			continue
+		}
+		remainderSegments := strings.Split(remainder, "/")
+		matches, err := walkFiles(base, prefs, func(relativePath string, filename string) bool {
+			return matchPathSegments(remainderSegments, strings.Split(relativePath, "/"))
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+	}
+	return files, nil
+}
+
+// splitGlobBase splits the glob into the directory before any wildcards, and the pattern after it.
+func splitGlobBase(glob string) (string, string) {
+	segments := strings.Split(glob, "/")
+	for index, segment := range segments {
+		if isGlob(segment) {
+			base := strings.Join(segments[:index], "/")
+			if base == "" && index > 0 {
+				base = "/"
+			} else if base == "" {
+				base = "."
+			}
+			return filepath.FromSlash(base), strings.Join(segments[index:], "/")
+		}
+	}
+	return filepath.FromSlash(path.Dir(glob)), path.Base(glob)
+}
+
+// walkFiles finds the files under the root that the include function accepts, skipping the excluded
+// and git ignored files and directories.
+func walkFiles(root string, prefs FileExpansionPreferences, include func(relativePath string, filename string) bool) ([]string, error) {
+	var ignore *gitIgnore
+	if prefs.GitIgnore {
+		var err error
+		if ignore, err = newGitIgnore(root); err != nil {
+			return nil, err
+		}
+	}
+
+	var files []string
+	err := filepath.WalkDir(root, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(root, filename)
+		if err != nil {
+			return err
+		}
+		relativePath := filepath.ToSlash(relative)
+		isDir := entry.IsDir()
+
+		if relativePath != "." {
+			skip := isDir && entry.Name() == ".git"
+			for _, exclude := range prefs.Exclude {
+				skip = skip || matchesFilePattern(exclude, relativePath, filename)
+			}
+			skip = skip || (ignore != nil && ignore.ignored(filename, isDir))
+			if skip && isDir {
+				return filepath.SkipDir
+			} else if skip {
+				return nil
+			}
+		}
+
+		if isDir && ignore != nil {
+			return ignore.load(filename)
+		} else if !isDir && include(relativePath, filename) {
+			files = append(files, filename)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// matchesFilePattern matches patterns without a '/' against the name of the file, and the others
+// against its path relative to the expanded directory, or the path as given.
+func matchesFilePattern(pattern string, relativePath string, filename string) bool {
+	for _, expandedPattern := range expandBraces(filepath.ToSlash(pattern)) {
+		if !strings.Contains(expandedPattern, "/") {
+			if matched, _ := path.Match(expandedPattern, path.Base(relativePath)); matched {
+				return true
+			}
+			continue
+		}
+		patternSegments := strings.Split(strings.TrimPrefix(expandedPattern, "./"), "/")
+		if matchPathSegments(patternSegments, strings.Split(relativePath, "/")) ||
+			matchPathSegments(patternSegments, strings.Split(path.Clean(filepath.ToSlash(filename)), "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathSegments matches the path against the pattern one directory at a time, where ** matches
+// any number of directories.
+func matchPathSegments(pattern []string, pathSegments []string) bool {
+	if len(pattern) == 0 {
+		return len(pathSegments) == 0
+	}
+	if pattern[0] == "**" {
+		for index := 0; index <= len(pathSegments); index++ {
+			if matchPathSegments(pattern[1:], pathSegments[index:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(pathSegments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], pathSegments[0]); !matched {
+		return false
+	}
+	return matchPathSegments(pattern[1:], pathSegments[1:])
+}
+
+// expandBraces expands the first {a,b} alternatives in the pattern, and recursively any after that.
+func expandBraces(pattern string) []string {
+	start := strings.Index(pattern, "{")
+	if start < 0 {
+		return []string{pattern}
+	}
+	depth := 0
+	alternativeStart := start + 1
+	var alternatives []string
+	for index := start; index < len(pattern); index++ {
+		switch pattern[index] {
+		case '{':
+			depth++
+		case ',':
+			if depth == 1 {
+				alternatives = append(alternatives, pattern[alternativeStart:index])
+				alternativeStart = index + 1
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				alternatives = append(alternatives, pattern[alternativeStart:index])
+				var expanded []string
+				for _, alternative := range alternatives {
+					expanded = append(expanded, expandBraces(pattern[:start]+alternative+pattern[index+1:])...)
+				}
+				return expanded
+			}
+		}
+	}
+	// unbalanced braces are matched literally
+	return []string{pattern}
+}
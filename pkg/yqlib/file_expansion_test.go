@@ -0,0 +1,168 @@
+package yqlib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+func createFileTree(t *testing.T, files ...string) string {
+	root := t.TempDir()
+	for _, file := range files {
+		filename := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+			t.Fatal(err)
+		}
+		content := "a: 1\n"
+		if filepath.Base(filename) == ".gitignore" {
+			content = gitIgnoreContent
+		}
+		if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+const gitIgnoreContent = `# generated files
+generated/
+*.tmp.yaml
+/top.yaml
+!keep.tmp.yaml
+`
+
+type fileExpansionScenario struct {
+	description string
+	files       []string
+	args        []string
+	prefs       func(prefs *FileExpansionPreferences)
+	expected    []string
+}
+
+var fileExpansionScenarios = []fileExpansionScenario{
+	{
+		description: "files and stdin are kept",
+		files:       []string{"a.yaml", "b.json"},
+		args:        []string{"b.json", "-", "a.yaml"},
+		expected:    []string{"b.json", "-", "a.yaml"},
+	},
+	{
+		description: "directories are walked in order",
+		files:       []string{"deploy/b.yaml", "deploy/a.yml", "deploy/sub/c.yaml", "deploy/notes.txt"},
+		args:        []string{"deploy"},
+		expected:    []string{"deploy/a.yml", "deploy/b.yaml", "deploy/sub/c.yaml"},
+	},
+	{
+		description: "doublestar globs",
+		files:       []string{"deploy/a.yaml", "deploy/x/b.yaml", "deploy/x/y/c.yaml", "deploy/x/c.json"},
+		args:        []string{"deploy/**/*.yaml"},
+		expected:    []string{"deploy/a.yaml", "deploy/x/b.yaml", "deploy/x/y/c.yaml"},
+	},
+	{
+		description: "single star globs stay in the directory",
+		files:       []string{"deploy/a.yaml", "deploy/x/b.yaml"},
+		args:        []string{"deploy/*.yaml"},
+		expected:    []string{"deploy/a.yaml"},
+	},
+	{
+		description: "brace globs",
+		files:       []string{"a.yaml", "b.json", "c.xml"},
+		args:        []string{"*.{json,yaml}"},
+		expected:    []string{"b.json", "a.yaml"},
+	},
+	{
+		description: "include patterns",
+		files:       []string{"a.yaml", "b.json", "sub/c.json"},
+		args:        []string{"."},
+		prefs:       func(prefs *FileExpansionPreferences) { prefs.Include = []string{"*.json"} },
+		expected:    []string{"b.json", "sub/c.json"},
+	},
+	{
+		description: "exclude patterns",
+		files:       []string{"a.yaml", "b.yaml", "testdata/c.yaml", "sub/testdata/d.yaml"},
+		args:        []string{"."},
+		prefs:       func(prefs *FileExpansionPreferences) { prefs.Exclude = []string{"testdata", "b.*"} },
+		expected:    []string{"a.yaml"},
+	},
+	{
+		description: "exclude path patterns",
+		files:       []string{"a/x.yaml", "b/x.yaml", "b/c/x.yaml"},
+		args:        []string{"**/x.yaml"},
+		prefs:       func(prefs *FileExpansionPreferences) { prefs.Exclude = []string{"b/**/x.yaml"} },
+		expected:    []string{"a/x.yaml"},
+	},
+	{
+		description: "gitignore",
+		files: []string{".git/config.yaml", ".gitignore", "top.yaml", "a.yaml", "a.tmp.yaml", "keep.tmp.yaml",
+			"generated/b.yaml", "sub/top.yaml", "sub/generated/c.yaml"},
+		args:     []string{"."},
+		expected: []string{"a.yaml", "keep.tmp.yaml", "sub/top.yaml"},
+	},
+	{
+		description: "gitignore of the parent directories",
+		files:       []string{".git/config.yaml", ".gitignore", "sub/a.yaml", "sub/a.tmp.yaml", "sub/generated/b.yaml"},
+		args:        []string{"sub"},
+		expected:    []string{"sub/a.yaml"},
+	},
+	{
+		description: "gitignore turned off",
+		files:       []string{".gitignore", "a.yaml", "a.tmp.yaml"},
+		args:        []string{"."},
+		prefs:       func(prefs *FileExpansionPreferences) { prefs.GitIgnore = false },
+		expected:    []string{"a.tmp.yaml", "a.yaml"},
+	},
+}
+
+func testFileExpansionScenario(t *testing.T, s fileExpansionScenario) {
+	root := createFileTree(t, s.files...)
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.Chdir(workingDirectory); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	prefs := NewDefaultFileExpansionPreferences()
+	if s.prefs != nil {
+		s.prefs(&prefs)
+	}
+	expanded, err := ExpandFiles(s.args, prefs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := make([]string, len(s.expected))
+	for index, filename := range s.expected {
+		expected[index] = filepath.FromSlash(filename)
+	}
+	test.AssertResultWithContext(t, fmt.Sprintf("%v", expected), fmt.Sprintf("%v", expanded), s.description)
+}
+
+func TestFileExpansionScenarios(t *testing.T) {
+	for _, s := range fileExpansionScenarios {
+		testFileExpansionScenario(t, s)
+	}
+}
+
+func TestFileExpansionNoFiles(t *testing.T) {
+	root := createFileTree(t, "a.json")
+	_, err := ExpandFiles([]string{filepath.Join(root, "*.yaml")}, NewDefaultFileExpansionPreferences())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	test.AssertResult(t, fmt.Sprintf("no files found for '%v'", filepath.Join(root, "*.yaml")), err.Error())
+}
+
+func TestExpandBraces(t *testing.T) {
+	test.AssertResult(t, "[a.yaml b.yaml]", fmt.Sprintf("%v", expandBraces("{a,b}.yaml")))
+	test.AssertResult(t, "[x/a1 x/a2 x/b]", fmt.Sprintf("%v", expandBraces("x/{a{1,2},b}")))
+	test.AssertResult(t, "[{a,b]", fmt.Sprintf("%v", expandBraces("{a,b")))
+}
@@ -0,0 +1,63 @@
+package yqlib
+
+import (
+	"container/list"
+	"io"
+)
+
+type fileRoutingPrinter struct {
+	printers []Printer
+}
+
+// NewFileRoutingPrinter creates a printer that prints each result with the printer of the file it came
+// from, using the file index of the result. Results that don't come from one of the files, like new
+// documents, are printed with the first printer.
+func NewFileRoutingPrinter(printers []Printer) Printer {
+	return &fileRoutingPrinter{printers: printers}
+}
+
+func (p *fileRoutingPrinter) PrintResults(matchingNodes *list.List) error {
+	fileResults := make([]*list.List, len(p.printers))
+	for index := range fileResults {
+		fileResults[index] = list.New()
+	}
+	for el := matchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		fileIndex := candidate.FileIndex
+		if fileIndex < 0 || fileIndex >= len(p.printers) {
+			fileIndex = 0
+		}
+		fileResults[fileIndex].PushBack(candidate)
+	}
+	for index, printer := range p.printers {
+		if fileResults[index].Len() == 0 {
+			continue
+		}
+		if err := printer.PrintResults(fileResults[index]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *fileRoutingPrinter) PrintedAnything() bool {
+	for _, printer := range p.printers {
+		if printer.PrintedAnything() {
+			return true
+		}
+	}
+	return false
+}
+
+// SetAppendix sets the appendix of the first file, set the appendix of each file on its own printer.
+func (p *fileRoutingPrinter) SetAppendix(reader io.Reader) {
+	if len(p.printers) > 0 {
+		p.printers[0].SetAppendix(reader)
+	}
+}
+
+func (p *fileRoutingPrinter) SetNulSepOutput(nulSepOutput bool) {
+	for _, printer := range p.printers {
+		printer.SetNulSepOutput(nulSepOutput)
+	}
+}
@@ -0,0 +1,173 @@
+package yqlib
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// sniffSize is how much of the input is peeked at to detect its format, the default buffer size of bufio.
+const sniffSize = 4096
+
+// stdinReader buffers stdin, so that it can be peeked at before it's decoded.
+var stdinReader *bufio.Reader
+var stdinReaderOnce sync.Once
+
+func stdinStream() *bufio.Reader {
+	stdinReaderOnce.Do(func() {
+		stdinReader = bufio.NewReader(os.Stdin)
+	})
+	return stdinReader
+}
+
+var tomlTableRegEx = regexp.MustCompile(`^\[\[?\s*[A-Za-z0-9_\-]+(\s*\.\s*[A-Za-z0-9_\-]+)*\s*\]\]?\s*(#.*)?$`)
+var tomlKeyValueRegEx = regexp.MustCompile(`^[A-Za-z0-9_\-."']+\s*=\s*(.*)$`)
+var tomlValueRegEx = regexp.MustCompile(`^(["'\[{].*|true|false|[+-]?(inf|nan)|[+-]?[0-9][0-9_]*(\.[0-9_]+)?([eE][+-]?[0-9]+)?|0x[0-9A-Fa-f_]+|0o[0-7_]+|0b[01_]+|\d{4}-\d{2}-\d{2}([Tt ]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})?)?|\d{2}:\d{2}:\d{2}(\.\d+)?)\s*(#.*)?$`)
+var tomlSyntaxRegEx = regexp.MustCompile(`^[^=]*(\s=\s*|=\s*["'\[{])`)
+var propertiesKeyValueRegEx = regexp.MustCompile(`^[^\s=:#!]+\s*=`)
+var yamlKeyRegEx = regexp.MustCompile(`^(-(\s|$)|[^\s:,]+:(\s|$)|"[^"]*":(\s|$)|'[^']*':(\s|$))`)
+
+// SniffFormat detects the format of the file (or stdin for '-') by peeking at its content. It recognises
+// xml, json (and ndjson), toml, properties, csv and tsv, and defaults to yaml.
+func SniffFormat(filename string) (string, error) {
+	var reader *bufio.Reader
+	if filename == "-" {
+		reader = stdinStream()
+	} else {
+		// ignore CWE-22 gosec issue - that's more targeted for http based apps that run in a public directory,
+		// and ensuring that it's not possible to give a path to a file outside that directory.
+		file, err := os.Open(filename) // #nosec
+		if err != nil {
+			return "", err
+		}
+		defer safelyCloseFile(file)
+		reader = bufio.NewReaderSize(file, sniffSize)
+	}
+	content, err := reader.Peek(sniffSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	format := sniffFormat(content, len(content) == sniffSize)
+	log.Debugf("sniffed format '%v' for '%v'", format, filename)
+	return format, nil
+}
+
+// sniffFormat detects the format of the content, which may be truncated at the end.
+func sniffFormat(content []byte, truncated bool) string {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	if truncated {
+		// the last line may be cut off
+		if lastLine := bytes.LastIndexByte(content, '\n'); lastLine >= 0 {
+			content = content[:lastLine+1]
+		}
+	}
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) == 0 {
+		return "yaml"
+	}
+
+	lines := significantLines(string(content))
+
+	switch trimmed[0] {
+	case '<':
+		return "xml"
+	case '{', '[':
+		// a json array like [1] also looks like a toml table header
+		if isJSONContent(trimmed, truncated) {
+			return "json"
+		}
+		if len(lines) > 0 && tomlTableRegEx.MatchString(lines[0]) {
+			return "toml"
+		}
+		return "yaml"
+	}
+
+	if len(lines) == 0 || lines[0] == "---" || strings.HasPrefix(lines[0], "%YAML") {
+		return "yaml"
+	}
+	for _, line := range lines {
+		if tomlTableRegEx.MatchString(line) {
+			return "toml"
+		}
+	}
+	if format, ok := sniffKeyValueFormat(lines); ok {
+		return format
+	}
+	if yamlKeyRegEx.MatchString(lines[0]) {
+		return "yaml"
+	}
+	if isSeparatedValues(string(content), '\t') {
+		return "tsv"
+	} else if isSeparatedValues(string(content), ',') {
+		return "csv"
+	}
+	return "yaml"
+}
+
+// significantLines are the lines that aren't blank or comments, without surrounding whitespace.
+func significantLines(content string) []string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "!") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func isJSONContent(content []byte, truncated bool) bool {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	for {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			return true
+		} else if err != nil {
+			return truncated && errors.Is(err, io.ErrUnexpectedEOF)
+		}
+	}
+}
+
+// sniffKeyValueFormat detects 'key = value' lines, which are toml when all the values are toml values
+// and some of them use syntax only toml has (a spaced '=', or quoted, array and inline table values),
+// and properties otherwise.
+func sniffKeyValueFormat(lines []string) (string, bool) {
+	if !propertiesKeyValueRegEx.MatchString(lines[0]) {
+		return "", false
+	}
+	tomlSyntax := false
+	for _, line := range lines {
+		// lines that aren't keys continue multiline values
+		if !propertiesKeyValueRegEx.MatchString(line) {
+			continue
+		}
+		match := tomlKeyValueRegEx.FindStringSubmatch(line)
+		if match == nil || !tomlValueRegEx.MatchString(match[1]) {
+			return "props", true
+		}
+		tomlSyntax = tomlSyntax || tomlSyntaxRegEx.MatchString(line)
+	}
+	if !tomlSyntax {
+		return "props", true
+	}
+	return "toml", true
+}
+
+// isSeparatedValues checks if there are at least two records of the same number of fields (more than one).
+func isSeparatedValues(content string, separator rune) bool {
+	if !strings.ContainsRune(content, separator) {
+		return false
+	}
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.Comma = separator
+	records, err := reader.ReadAll()
+	return err == nil && len(records) >= 2 && len(records[0]) >= 2
+}
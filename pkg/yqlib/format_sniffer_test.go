@@ -0,0 +1,182 @@
+package yqlib
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+type sniffScenario struct {
+	description string
+	input       string
+	truncated   bool
+	expected    string
+}
+
+var sniffScenarios = []sniffScenario{
+	{
+		description: "empty",
+		input:       "",
+		expected:    "yaml",
+	},
+	{
+		description: "yaml",
+		input:       "# comment\na: 1\nb:\n  - c\n",
+		expected:    "yaml",
+	},
+	{
+		description: "yaml sequence",
+		input:       "- a\n- b\n",
+		expected:    "yaml",
+	},
+	{
+		description: "yaml document",
+		input:       "---\na = 1\n",
+		expected:    "yaml",
+	},
+	{
+		description: "yaml flow map",
+		input:       "{a: 1}\n",
+		expected:    "yaml",
+	},
+	{
+		description: "json",
+		input:       "\n  {\"a\": [1, 2]}\n",
+		expected:    "json",
+	},
+	{
+		description: "json array",
+		input:       "[{\"a\": 1}]",
+		expected:    "json",
+	},
+	{
+		description: "json array of a number",
+		input:       "[1]\n",
+		expected:    "json",
+	},
+	{
+		description: "json array of a boolean",
+		input:       "[true]",
+		expected:    "json",
+	},
+	{
+		description: "truncated json array of numbers",
+		input:       "[1,\n2,\n",
+		truncated:   true,
+		expected:    "json",
+	},
+	{
+		description: "ndjson",
+		input:       "{\"a\": 1}\n{\"a\": 2}\n",
+		expected:    "json",
+	},
+	{
+		description: "truncated json",
+		input:       "{\"a\": [1, 2,\n",
+		truncated:   true,
+		expected:    "json",
+	},
+	{
+		description: "json with a byte order mark",
+		input:       "\xef\xbb\xbf{\"a\": 1}",
+		expected:    "json",
+	},
+	{
+		description: "xml",
+		input:       "<?xml version=\"1.0\"?>\n<a>1</a>\n",
+		expected:    "xml",
+	},
+	{
+		description: "toml table",
+		input:       "[server]\nhost = \"example.com\"\n",
+		expected:    "toml",
+	},
+	{
+		description: "toml table with a number name",
+		input:       "[1]\nhost = \"example.com\"\n",
+		expected:    "toml",
+	},
+	{
+		description: "toml array of tables",
+		input:       "# servers\n[[servers]]\nname = \"a\"\n",
+		expected:    "toml",
+	},
+	{
+		description: "toml values",
+		input:       "title = \"a\"\nport = 8080\nenabled = true\ndate = 1979-05-27T07:32:00Z\nports = [\n  1,\n  2,\n]\n",
+		expected:    "toml",
+	},
+	{
+		description: "properties with number values",
+		input:       "server.port=8080\n",
+		expected:    "props",
+	},
+	{
+		description: "properties with number and boolean values",
+		input:       "# settings\nport=8080\nenabled=true\n",
+		expected:    "props",
+	},
+	{
+		description: "toml with unspaced quoted values",
+		input:       "name=\"a\"\nport=8080\n",
+		expected:    "toml",
+	},
+	{
+		description: "properties",
+		input:       "# settings\nserver.host = example.com\nserver.port = 8080\n",
+		expected:    "props",
+	},
+	{
+		description: "properties with bang comments",
+		input:       "! settings\nname=cat\n",
+		expected:    "props",
+	},
+	{
+		description: "csv",
+		input:       "name,age\ncat,3\n\"dog, big\",4\n",
+		expected:    "csv",
+	},
+	{
+		description: "truncated csv",
+		input:       "name,age\ncat,3\ndog",
+		truncated:   true,
+		expected:    "csv",
+	},
+	{
+		description: "tsv",
+		input:       "name\tage\ncat\t3\n",
+		expected:    "tsv",
+	},
+	{
+		description: "single line with commas",
+		input:       "hello, world\n",
+		expected:    "yaml",
+	},
+}
+
+func TestSniffFormat(t *testing.T) {
+	for _, s := range sniffScenarios {
+		test.AssertResultWithContext(t, s.expected, sniffFormat([]byte(s.input), s.truncated), s.description)
+	}
+}
+
+func TestSniffFormatOfFile(t *testing.T) {
+	filename := createTestFile("{\"a\": 1}\n")
+	defer tryRemoveTempFile(filename)
+	format, err := SniffFormat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResult(t, "json", format)
+}
+
+func TestSniffFormatOfLargeFile(t *testing.T) {
+	filename := createTestFile("a,b\n" + strings.Repeat("1,2\n", sniffSize))
+	defer tryRemoveTempFile(filename)
+	format, err := SniffFormat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResult(t, "csv", format)
+}
@@ -43,7 +43,7 @@ func (f *frontMatterHandlerImpl) Split() error {
 	var reader *bufio.Reader
 	var err error
 	if f.originalFilename == "-" {
-		reader = bufio.NewReader(os.Stdin)
+		reader = stdinStream()
 	} else {
 		file, err := os.Open(f.originalFilename) // #nosec
 		if err != nil {
@@ -0,0 +1,131 @@
+package yqlib
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type gitIgnoreRule struct {
+	// directory is the absolute path of the directory of the .gitignore file
+	directory string
+	segments  []string
+	negate    bool
+	dirOnly   bool
+}
+
+// gitIgnore matches files against the .gitignore files of the directories they are in, and of the
+// directories above them up to the root of the git repository.
+type gitIgnore struct {
+	rules []gitIgnoreRule
+}
+
+func newGitIgnore(root string) (*gitIgnore, error) {
+	ignore := &gitIgnore{}
+	absoluteRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	// the .gitignore files above the root also apply, up to the repository they are in
+	var parents []string
+	for directory := filepath.Dir(absoluteRoot); ; directory = filepath.Dir(directory) {
+		parents = append(parents, directory)
+		if _, err := os.Stat(filepath.Join(directory, ".git")); err == nil {
+			break
+		}
+		if filepath.Dir(directory) == directory {
+			// not in a git repository
+			parents = nil
+			break
+		}
+	}
+	if _, err := os.Stat(filepath.Join(absoluteRoot, ".git")); err == nil {
+		parents = nil
+	}
+
+	for index := len(parents) - 1; index >= 0; index-- {
+		if err := ignore.load(parents[index]); err != nil {
+			return nil, err
+		}
+	}
+	return ignore, nil
+}
+
+// load adds the rules of the .gitignore file in the directory, if there is one.
+func (g *gitIgnore) load(directory string) error {
+	absoluteDirectory, err := filepath.Abs(directory)
+	if err != nil {
+		return err
+	}
+	// #nosec
+	file, err := os.Open(filepath.Join(absoluteDirectory, ".gitignore"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer safelyCloseFile(file)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseGitIgnoreRule(absoluteDirectory, scanner.Text()); ok {
+			g.rules = append(g.rules, rule)
+		}
+	}
+	return scanner.Err()
+}
+
+func parseGitIgnoreRule(directory string, line string) (gitIgnoreRule, bool) {
+	line = strings.TrimRight(strings.TrimSuffix(line, "\r"), " ")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return gitIgnoreRule{}, false
+	}
+	rule := gitIgnoreRule{directory: directory}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if line == "" {
+		return gitIgnoreRule{}, false
+	}
+
+	// patterns with a '/' are relative to the .gitignore file, the others match at any depth
+	if strings.Contains(line, "/") {
+		rule.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	} else {
+		rule.segments = []string{"**", line}
+	}
+	return rule, true
+}
+
+// ignored checks if the file is ignored, the last rule that matches it decides.
+func (g *gitIgnore) ignored(filename string, isDir bool) bool {
+	absoluteFilename, err := filepath.Abs(filename)
+	if err != nil {
+		return false
+	}
+	ignored := false
+	for _, rule := range g.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		relative, err := filepath.Rel(rule.directory, absoluteFilename)
+		if err != nil || relative == "." || strings.HasPrefix(relative, "..") {
+			continue
+		}
+		if matchPathSegments(rule.segments, strings.Split(filepath.ToSlash(relative), "/")) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,785 @@
+package yqlib
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+const maxJSONSchemaDepth = 5000
+
+// SchemaViolation is a value that does not match a JSON schema.
+type SchemaViolation struct {
+	Document uint
+	Path     []interface{}
+	Line     int
+	Column   int
+	Message  string
+}
+
+func (v *SchemaViolation) PathString() string {
+	return mergePathString(v.Path)
+}
+
+type jsonSchemaValidator interface {
+	// Validate returns the places where the node does not match the schema.
+	Validate(node *yaml.Node) ([]*SchemaViolation, error)
+	// ValidateFile validates every document in the file.
+	ValidateFile(filename string, decoder Decoder) ([]*SchemaViolation, error)
+}
+
+type jsonSchemaValidatorImpl struct {
+	root      *yaml.Node
+	resources map[string]*yaml.Node
+	bases     map[*yaml.Node]*url.URL
+	anchors   map[string]*yaml.Node
+	patterns  map[string]*regexp.Regexp
+	depth     int
+}
+
+// jsonSchemaResult holds the violations found by a schema, and the properties and items it
+// evaluated, which are needed for unevaluatedProperties and unevaluatedItems.
+type jsonSchemaResult struct {
+	violations          []*SchemaViolation
+	evaluatedProperties map[string]bool
+	evaluatedItems      map[int]bool
+}
+
+func newJSONSchemaResult() *jsonSchemaResult {
+	return &jsonSchemaResult{evaluatedProperties: map[string]bool{}, evaluatedItems: map[int]bool{}}
+}
+
+func (r *jsonSchemaResult) valid() bool {
+	return len(r.violations) == 0
+}
+
+func (r *jsonSchemaResult) addEvaluated(other *jsonSchemaResult) {
+	for key := range other.evaluatedProperties {
+		r.evaluatedProperties[key] = true
+	}
+	for index := range other.evaluatedItems {
+		r.evaluatedItems[index] = true
+	}
+}
+
+// NewJSONSchemaValidator creates a validator for the JSON schema (draft 2020-12) in the given file.
+// The schema may be json or yaml, and $ref may refer to other schema files relative to it.
+func NewJSONSchemaValidator(schemaFilename string) (jsonSchemaValidator, error) {
+	v := newJSONSchemaValidatorImpl()
+	uri, err := fileURI(schemaFilename, false)
+	if err != nil {
+		return nil, err
+	}
+	root, err := v.loadSchemaFile(uri)
+	if err != nil {
+		return nil, err
+	}
+	v.root = root
+	return v, nil
+}
+
+// newJSONSchemaValidatorForNode creates a validator for a schema that has already been loaded,
+// filename is used to resolve references to other schema files (if empty, the current directory is used).
+func newJSONSchemaValidatorForNode(schema *yaml.Node, filename string) (jsonSchemaValidator, error) {
+	v := newJSONSchemaValidatorImpl()
+	var uri *url.URL
+	var err error
+	if filename == "" {
+		uri, err = fileURI(".", true)
+	} else {
+		uri, err = fileURI(filename, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	v.root = unwrapDoc(schema)
+	v.register(v.root, uri, true)
+	return v, nil
+}
+
+func newJSONSchemaValidatorImpl() *jsonSchemaValidatorImpl {
+	return &jsonSchemaValidatorImpl{
+		resources: map[string]*yaml.Node{},
+		bases:     map[*yaml.Node]*url.URL{},
+		anchors:   map[string]*yaml.Node{},
+		patterns:  map[string]*regexp.Regexp{},
+	}
+}
+
+func fileURI(filename string, directory bool) (*url.URL, error) {
+	absolute, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.ToSlash(absolute)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if directory && !strings.HasSuffix(path, "/") {
+		path = path + "/"
+	}
+	return &url.URL{Scheme: "file", Path: path}, nil
+}
+
+func withoutFragment(uri *url.URL) string {
+	copied := *uri
+	copied.Fragment = ""
+	copied.RawFragment = ""
+	return copied.String()
+}
+
+func (v *jsonSchemaValidatorImpl) loadSchemaFile(uri *url.URL) (*yaml.Node, error) {
+	filename := filepath.FromSlash(uri.Path)
+	if filepath.VolumeName(filename[1:]) != "" {
+		// windows paths look like /C:/schemas/...
+		filename = filename[1:]
+	}
+	file, err := os.Open(filename) // #nosec
+	if err != nil {
+		return nil, err
+	}
+	defer safelyCloseFile(file)
+
+	documents, err := readDocuments(bufio.NewReader(file), filename, 0, NewYamlDecoder(LoadYamlPreferences))
+	if err != nil {
+		return nil, err
+	}
+	if documents.Len() == 0 {
+		return nil, fmt.Errorf("schema file %v is empty", filename)
+	}
+	root := unwrapDoc(documents.Front().Value.(*CandidateNode).Node)
+	v.register(root, uri, true)
+	return root, nil
+}
+
+func jsonSchemaKeyword(schema *yaml.Node, keyword string) *yaml.Node {
+	if schema.Kind != yaml.MappingNode {
+		return nil
+	}
+	index := findJSONPatchKey(schema, keyword)
+	if index == -1 {
+		return nil
+	}
+	return resolveMergeAlias(schema.Content[index+1])
+}
+
+// register records the schema resources ($id) and anchors, so that references to them can be resolved.
+func (v *jsonSchemaValidatorImpl) register(schema *yaml.Node, base *url.URL, resourceRoot bool) {
+	schema = resolveMergeAlias(schema)
+	switch schema.Kind {
+	case yaml.MappingNode:
+		if id := jsonSchemaKeyword(schema, "$id"); id != nil && id.Kind == yaml.ScalarNode {
+			if idURL, err := url.Parse(id.Value); err == nil {
+				base = base.ResolveReference(idURL)
+				resourceRoot = true
+			}
+		}
+		if resourceRoot {
+			v.resources[withoutFragment(base)] = schema
+			v.bases[schema] = base
+		}
+		for _, keyword := range []string{"$anchor", "$dynamicAnchor"} {
+			if anchor := jsonSchemaKeyword(schema, keyword); anchor != nil && anchor.Kind == yaml.ScalarNode {
+				v.anchors[withoutFragment(base)+"#"+anchor.Value] = schema
+			}
+		}
+		for index := 0; index < len(schema.Content); index = index + 2 {
+			switch schema.Content[index].Value {
+			case "enum", "const", "default", "examples":
+				// these are values, not schemas
+				continue
+			}
+			v.register(schema.Content[index+1], base, false)
+		}
+	case yaml.SequenceNode:
+		for _, child := range schema.Content {
+			v.register(child, base, false)
+		}
+	}
+}
+
+func (v *jsonSchemaValidatorImpl) resolveRef(ref string, base *url.URL) (*yaml.Node, *url.URL, error) {
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid $ref '%v': %w", ref, err)
+	}
+	target := base.ResolveReference(refURL)
+	resourceURI := withoutFragment(target)
+	resource, exists := v.resources[resourceURI]
+	if !exists {
+		if target.Scheme != "file" {
+			return nil, nil, fmt.Errorf("could not resolve $ref '%v', only local files can be loaded", ref)
+		}
+		resource, err = v.loadSchemaFile(target)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not resolve $ref '%v': %w", ref, err)
+		}
+	}
+	resourceBase := v.bases[resource]
+
+	if target.Fragment == "" {
+		return resource, resourceBase, nil
+	} else if strings.HasPrefix(target.Fragment, "/") {
+		path, err := parseJSONPointer(target.Fragment)
+		if err != nil {
+			return nil, nil, err
+		}
+		schema, err := jsonPointerGet(resource, path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not resolve $ref '%v': %w", ref, err)
+		}
+		return schema, resourceBase, nil
+	}
+	schema, exists := v.anchors[resourceURI+"#"+target.Fragment]
+	if !exists {
+		return nil, nil, fmt.Errorf("could not resolve $ref '%v', anchor '%v' not found", ref, target.Fragment)
+	}
+	return schema, resourceBase, nil
+}
+
+func (v *jsonSchemaValidatorImpl) Validate(node *yaml.Node) ([]*SchemaViolation, error) {
+	result, err := v.validate(v.root, v.bases[v.root], unwrapDoc(node), []interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	return result.violations, nil
+}
+
+func (v *jsonSchemaValidatorImpl) ValidateFile(filename string, decoder Decoder) ([]*SchemaViolation, error) {
+	file, err := os.Open(filename) // #nosec
+	if err != nil {
+		return nil, err
+	}
+	defer safelyCloseFile(file)
+
+	documents, err := readDocuments(bufio.NewReader(file), filename, 0, decoder)
+	if err != nil {
+		return nil, err
+	}
+	violations := make([]*SchemaViolation, 0)
+	for el := documents.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		documentViolations, err := v.Validate(candidate.Node)
+		if err != nil {
+			return nil, err
+		}
+		for _, violation := range documentViolations {
+			violation.Document = candidate.Document
+		}
+		violations = append(violations, documentViolations...)
+	}
+	return violations, nil
+}
+
+func newSchemaViolation(node *yaml.Node, path []interface{}, format string, a ...interface{}) *SchemaViolation {
+	return &SchemaViolation{
+		Path:    append([]interface{}{}, path...),
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+func jsonSchemaNumber(node *yaml.Node) (float64, bool) {
+	if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+		return 0, false
+	}
+	var value float64
+	if err := node.Decode(&value); err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+func jsonSchemaInt(node *yaml.Node) (int, bool) {
+	value, isNumber := jsonSchemaNumber(node)
+	if !isNumber || value != math.Trunc(value) {
+		return 0, false
+	}
+	return int(value), true
+}
+
+// jsonSchemaTypeOf returns the JSON type name of the node.
+func jsonSchemaTypeOf(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.Tag {
+	case "!!null":
+		return "null"
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		if value, isNumber := jsonSchemaNumber(node); isNumber && value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	}
+	return "string"
+}
+
+func jsonSchemaTypeMatches(node *yaml.Node, schemaType string) bool {
+	actual := jsonSchemaTypeOf(node)
+	return actual == schemaType || (schemaType == "number" && actual == "integer")
+}
+
+func (v *jsonSchemaValidatorImpl) pattern(expression string) (*regexp.Regexp, error) {
+	if compiled, exists := v.patterns[expression]; exists {
+		return compiled, nil
+	}
+	compiled, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%v' in schema: %w", expression, err)
+	}
+	v.patterns[expression] = compiled
+	return compiled, nil
+}
+
+func (v *jsonSchemaValidatorImpl) validate(schema *yaml.Node, base *url.URL, node *yaml.Node, path []interface{}) (*jsonSchemaResult, error) {
+	v.depth++
+	defer func() { v.depth-- }()
+	if v.depth > maxJSONSchemaDepth {
+		return nil, fmt.Errorf("schema is too deeply nested, is there a $ref loop?")
+	}
+
+	schema = resolveMergeAlias(schema)
+	node = resolveMergeAlias(node)
+	result := newJSONSchemaResult()
+
+	if schema.Kind == yaml.ScalarNode && schema.Tag == "!!bool" {
+		if schema.Value != "true" {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "value is not allowed"))
+		}
+		return result, nil
+	} else if schema.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid schema at line %v, expected an object or a boolean but found %v", schema.Line, schema.Tag)
+	}
+
+	if id := jsonSchemaKeyword(schema, "$id"); id != nil {
+		if idURL, err := url.Parse(id.Value); err == nil {
+			base = base.ResolveReference(idURL)
+		}
+	}
+
+	for index := 0; index < len(schema.Content); index = index + 2 {
+		keyword := schema.Content[index].Value
+		value := resolveMergeAlias(schema.Content[index+1])
+		var err error
+		switch keyword {
+		case "$ref", "$dynamicRef":
+			err = v.validateRef(value, base, node, path, result)
+		case "allOf", "anyOf", "oneOf", "not":
+			err = v.validateApplicator(keyword, value, base, node, path, result)
+		case "if":
+			err = v.validateIf(schema, value, base, node, path, result)
+		case "type", "enum", "const":
+			v.validateGeneric(keyword, value, node, path, result)
+		default:
+			switch jsonSchemaTypeOf(node) {
+			case "integer", "number":
+				v.validateNumber(keyword, value, node, path, result)
+			case "string":
+				err = v.validateString(keyword, value, node, path, result)
+			case "array":
+				err = v.validateArray(schema, keyword, value, base, node, path, result)
+			case "object":
+				err = v.validateObject(schema, keyword, value, base, node, path, result)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// unevaluated keywords depend on all the others, so they go last
+	if node.Kind == yaml.MappingNode {
+		if unevaluated := jsonSchemaKeyword(schema, "unevaluatedProperties"); unevaluated != nil {
+			for index := 0; index < len(node.Content); index = index + 2 {
+				key := node.Content[index].Value
+				if result.evaluatedProperties[key] {
+					continue
+				}
+				if err := v.validateChild(unevaluated, base, node.Content[index+1], appendDiffPath(path, key), result); err != nil {
+					return nil, err
+				}
+				result.evaluatedProperties[key] = true
+			}
+		}
+	} else if node.Kind == yaml.SequenceNode {
+		if unevaluated := jsonSchemaKeyword(schema, "unevaluatedItems"); unevaluated != nil {
+			for itemIndex, item := range node.Content {
+				if result.evaluatedItems[itemIndex] {
+					continue
+				}
+				if err := v.validateChild(unevaluated, base, item, appendDiffPath(path, itemIndex), result); err != nil {
+					return nil, err
+				}
+				result.evaluatedItems[itemIndex] = true
+			}
+		}
+	}
+	return result, nil
+}
+
+// validateChild validates a property or item, adding its violations to the result.
+func (v *jsonSchemaValidatorImpl) validateChild(schema *yaml.Node, base *url.URL, node *yaml.Node, path []interface{}, result *jsonSchemaResult) error {
+	childResult, err := v.validate(schema, base, node, path)
+	if err != nil {
+		return err
+	}
+	result.violations = append(result.violations, childResult.violations...)
+	return nil
+}
+
+// validateInPlace validates the node against another schema, adding its violations and evaluated properties to the result.
+func (v *jsonSchemaValidatorImpl) validateInPlace(schema *yaml.Node, base *url.URL, node *yaml.Node, path []interface{}, result *jsonSchemaResult) (*jsonSchemaResult, error) {
+	subResult, err := v.validate(schema, base, node, path)
+	if err != nil {
+		return nil, err
+	}
+	result.violations = append(result.violations, subResult.violations...)
+	if subResult.valid() {
+		result.addEvaluated(subResult)
+	}
+	return subResult, nil
+}
+
+func (v *jsonSchemaValidatorImpl) validateRef(ref *yaml.Node, base *url.URL, node *yaml.Node, path []interface{}, result *jsonSchemaResult) error {
+	target, targetBase, err := v.resolveRef(ref.Value, base)
+	if err != nil {
+		return err
+	}
+	_, err = v.validateInPlace(target, targetBase, node, path, result)
+	return err
+}
+
+func (v *jsonSchemaValidatorImpl) validateApplicator(keyword string, value *yaml.Node, base *url.URL, node *yaml.Node, path []interface{}, result *jsonSchemaResult) error {
+	if keyword == "allOf" {
+		for _, schema := range value.Content {
+			if _, err := v.validateInPlace(schema, base, node, path, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	} else if keyword == "not" {
+		subResult, err := v.validate(value, base, node, path)
+		if err != nil {
+			return err
+		}
+		if subResult.valid() {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "value must not match the schema in 'not'"))
+		}
+		return nil
+	}
+
+	matches := 0
+	for _, schema := range value.Content {
+		subResult, err := v.validate(schema, base, node, path)
+		if err != nil {
+			return err
+		}
+		if subResult.valid() {
+			matches++
+			result.addEvaluated(subResult)
+		}
+	}
+	if keyword == "anyOf" && matches == 0 {
+		result.violations = append(result.violations, newSchemaViolation(node, path, "value must match at least one of the schemas in 'anyOf'"))
+	} else if keyword == "oneOf" && matches != 1 {
+		result.violations = append(result.violations, newSchemaViolation(node, path, "value must match exactly one of the schemas in 'oneOf', but matched %v", matches))
+	}
+	return nil
+}
+
+func (v *jsonSchemaValidatorImpl) validateIf(schema *yaml.Node, ifSchema *yaml.Node, base *url.URL, node *yaml.Node, path []interface{}, result *jsonSchemaResult) error {
+	ifResult, err := v.validate(ifSchema, base, node, path)
+	if err != nil {
+		return err
+	}
+	branch := jsonSchemaKeyword(schema, "else")
+	if ifResult.valid() {
+		result.addEvaluated(ifResult)
+		branch = jsonSchemaKeyword(schema, "then")
+	}
+	if branch != nil {
+		_, err = v.validateInPlace(branch, base, node, path, result)
+	}
+	return err
+}
+
+func (v *jsonSchemaValidatorImpl) validateGeneric(keyword string, value *yaml.Node, node *yaml.Node, path []interface{}, result *jsonSchemaResult) {
+	switch keyword {
+	case "type":
+		types := []string{value.Value}
+		if value.Kind == yaml.SequenceNode {
+			types = make([]string, len(value.Content))
+			for i, schemaType := range value.Content {
+				types[i] = schemaType.Value
+			}
+		}
+		for _, schemaType := range types {
+			if jsonSchemaTypeMatches(node, schemaType) {
+				return
+			}
+		}
+		result.violations = append(result.violations, newSchemaViolation(node, path, "expected %v but found %v", strings.Join(types, " or "), jsonSchemaTypeOf(node)))
+	case "enum":
+		for _, allowed := range value.Content {
+			if jsonValuesEqual(node, allowed) {
+				return
+			}
+		}
+		result.violations = append(result.violations, newSchemaViolation(node, path, "%v is not one of the allowed values %v", diffValueText(node), diffValueText(value)))
+	case "const":
+		if !jsonValuesEqual(node, value) {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "%v does not equal %v", diffValueText(node), diffValueText(value)))
+		}
+	}
+}
+
+func (v *jsonSchemaValidatorImpl) validateNumber(keyword string, value *yaml.Node, node *yaml.Node, path []interface{}, result *jsonSchemaResult) {
+	limit, isNumber := jsonSchemaNumber(value)
+	actual, _ := jsonSchemaNumber(node)
+	if !isNumber {
+		return
+	}
+	switch keyword {
+	case "minimum":
+		if actual < limit {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "%v is less than the minimum of %v", node.Value, value.Value))
+		}
+	case "maximum":
+		if actual > limit {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "%v is greater than the maximum of %v", node.Value, value.Value))
+		}
+	case "exclusiveMinimum":
+		if actual <= limit {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "%v must be greater than %v", node.Value, value.Value))
+		}
+	case "exclusiveMaximum":
+		if actual >= limit {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "%v must be less than %v", node.Value, value.Value))
+		}
+	case "multipleOf":
+		quotient := actual / limit
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "%v is not a multiple of %v", node.Value, value.Value))
+		}
+	}
+}
+
+func (v *jsonSchemaValidatorImpl) validateString(keyword string, value *yaml.Node, node *yaml.Node, path []interface{}, result *jsonSchemaResult) error {
+	switch keyword {
+	case "minLength":
+		if limit, isInt := jsonSchemaInt(value); isInt && utf8.RuneCountInString(node.Value) < limit {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "must be at least %v characters long", limit))
+		}
+	case "maxLength":
+		if limit, isInt := jsonSchemaInt(value); isInt && utf8.RuneCountInString(node.Value) > limit {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "must be at most %v characters long", limit))
+		}
+	case "pattern":
+		compiled, err := v.pattern(value.Value)
+		if err != nil {
+			return err
+		}
+		if !compiled.MatchString(node.Value) {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "'%v' does not match the pattern '%v'", node.Value, value.Value))
+		}
+	}
+	return nil
+}
+
+func (v *jsonSchemaValidatorImpl) validateArray(schema *yaml.Node, keyword string, value *yaml.Node, base *url.URL, node *yaml.Node, path []interface{}, result *jsonSchemaResult) error {
+	switch keyword {
+	case "prefixItems":
+		for index, itemSchema := range value.Content {
+			if index >= len(node.Content) {
+				break
+			}
+			if err := v.validateChild(itemSchema, base, node.Content[index], appendDiffPath(path, index), result); err != nil {
+				return err
+			}
+			result.evaluatedItems[index] = true
+		}
+	case "items":
+		start := 0
+		if prefixItems := jsonSchemaKeyword(schema, "prefixItems"); prefixItems != nil {
+			start = len(prefixItems.Content)
+		}
+		for index := start; index < len(node.Content); index++ {
+			if err := v.validateChild(value, base, node.Content[index], appendDiffPath(path, index), result); err != nil {
+				return err
+			}
+			result.evaluatedItems[index] = true
+		}
+	case "contains":
+		matches := 0
+		for index, item := range node.Content {
+			itemResult, err := v.validate(value, base, item, appendDiffPath(path, index))
+			if err != nil {
+				return err
+			}
+			if itemResult.valid() {
+				matches++
+				result.evaluatedItems[index] = true
+			}
+		}
+		minimum := 1
+		if minContains := jsonSchemaKeyword(schema, "minContains"); minContains != nil {
+			minimum, _ = jsonSchemaInt(minContains)
+		}
+		if matches < minimum {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "must contain at least %v item(s) matching 'contains', but found %v", minimum, matches))
+		}
+		if maxContains := jsonSchemaKeyword(schema, "maxContains"); maxContains != nil {
+			if maximum, isInt := jsonSchemaInt(maxContains); isInt && matches > maximum {
+				result.violations = append(result.violations, newSchemaViolation(node, path, "must contain at most %v item(s) matching 'contains', but found %v", maximum, matches))
+			}
+		}
+	case "minItems":
+		if limit, isInt := jsonSchemaInt(value); isInt && len(node.Content) < limit {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "must have at least %v items", limit))
+		}
+	case "maxItems":
+		if limit, isInt := jsonSchemaInt(value); isInt && len(node.Content) > limit {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "must have at most %v items", limit))
+		}
+	case "uniqueItems":
+		if value.Value != "true" {
+			return nil
+		}
+		for index, item := range node.Content {
+			for previous := 0; previous < index; previous++ {
+				if jsonValuesEqual(node.Content[previous], item) {
+					result.violations = append(result.violations, newSchemaViolation(item, appendDiffPath(path, index), "items must be unique, but this is the same as item %v", previous))
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (v *jsonSchemaValidatorImpl) matchesPatternProperty(schema *yaml.Node, key string) (bool, error) {
+	patternProperties := jsonSchemaKeyword(schema, "patternProperties")
+	if patternProperties == nil {
+		return false, nil
+	}
+	for index := 0; index < len(patternProperties.Content); index = index + 2 {
+		compiled, err := v.pattern(patternProperties.Content[index].Value)
+		if err != nil {
+			return false, err
+		}
+		if compiled.MatchString(key) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (v *jsonSchemaValidatorImpl) validateObject(schema *yaml.Node, keyword string, value *yaml.Node, base *url.URL, node *yaml.Node, path []interface{}, result *jsonSchemaResult) error {
+	switch keyword {
+	case "properties":
+		for index := 0; index < len(value.Content); index = index + 2 {
+			key := value.Content[index].Value
+			if property := mergeMapValue(node, key); property != nil {
+				if err := v.validateChild(value.Content[index+1], base, property, appendDiffPath(path, key), result); err != nil {
+					return err
+				}
+				result.evaluatedProperties[key] = true
+			}
+		}
+	case "patternProperties":
+		for index := 0; index < len(value.Content); index = index + 2 {
+			compiled, err := v.pattern(value.Content[index].Value)
+			if err != nil {
+				return err
+			}
+			for propertyIndex := 0; propertyIndex < len(node.Content); propertyIndex = propertyIndex + 2 {
+				key := node.Content[propertyIndex].Value
+				if !compiled.MatchString(key) {
+					continue
+				}
+				if err := v.validateChild(value.Content[index+1], base, node.Content[propertyIndex+1], appendDiffPath(path, key), result); err != nil {
+					return err
+				}
+				result.evaluatedProperties[key] = true
+			}
+		}
+	case "additionalProperties":
+		properties := jsonSchemaKeyword(schema, "properties")
+		for index := 0; index < len(node.Content); index = index + 2 {
+			key := node.Content[index].Value
+			if properties != nil && findJSONPatchKey(properties, key) != -1 {
+				continue
+			}
+			matchesPattern, err := v.matchesPatternProperty(schema, key)
+			if err != nil {
+				return err
+			} else if matchesPattern {
+				continue
+			}
+			if err := v.validateChild(value, base, node.Content[index+1], appendDiffPath(path, key), result); err != nil {
+				return err
+			}
+			result.evaluatedProperties[key] = true
+		}
+	case "propertyNames":
+		for index := 0; index < len(node.Content); index = index + 2 {
+			key := node.Content[index]
+			if err := v.validateChild(value, base, key, appendDiffPath(path, key.Value), result); err != nil {
+				return err
+			}
+		}
+	case "required":
+		for _, required := range value.Content {
+			if findJSONPatchKey(node, required.Value) == -1 {
+				result.violations = append(result.violations, newSchemaViolation(node, path, "missing required property '%v'", required.Value))
+			}
+		}
+	case "dependentRequired":
+		for index := 0; index < len(value.Content); index = index + 2 {
+			key := value.Content[index].Value
+			if findJSONPatchKey(node, key) == -1 {
+				continue
+			}
+			for _, required := range value.Content[index+1].Content {
+				if findJSONPatchKey(node, required.Value) == -1 {
+					result.violations = append(result.violations, newSchemaViolation(node, path, "missing property '%v', which is required when '%v' is present", required.Value, key))
+				}
+			}
+		}
+	case "dependentSchemas":
+		for index := 0; index < len(value.Content); index = index + 2 {
+			if findJSONPatchKey(node, value.Content[index].Value) == -1 {
+				continue
+			}
+			if _, err := v.validateInPlace(value.Content[index+1], base, node, path, result); err != nil {
+				return err
+			}
+		}
+	case "minProperties":
+		if limit, isInt := jsonSchemaInt(value); isInt && len(node.Content)/2 < limit {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "must have at least %v properties", limit))
+		}
+	case "maxProperties":
+		if limit, isInt := jsonSchemaInt(value); isInt && len(node.Content)/2 > limit {
+			result.violations = append(result.violations, newSchemaViolation(node, path, "must have at most %v properties", limit))
+		}
+	}
+	return nil
+}
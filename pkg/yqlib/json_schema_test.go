@@ -0,0 +1,65 @@
+package yqlib
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+func TestJSONSchemaValidatorFileRefs(t *testing.T) {
+	definitions := createTestFile(`{"$defs": {"replicas": {"type": "integer", "minimum": 1}}}`)
+	schema := createTestFile(fmt.Sprintf(`{"properties": {"spec": {"properties": {"replicas": {"$ref": "%v#/$defs/replicas"}}}}}`, filepath.Base(definitions)))
+	document := createTestFile("spec:\n  replicas: 0\n---\nspec:\n  replicas: 2\n")
+
+	validator, err := NewJSONSchemaValidator(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations, err := validator.ValidateFile(document, NewYamlDecoder(ConfiguredYamlPreferences))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = fmt.Sprintf("%v %v:%v %v: %v", violation.Document, violation.Line, violation.Column, violation.PathString(), violation.Message)
+	}
+	test.AssertResult(t, "0 2:13 spec.replicas: 0 is less than the minimum of 1", strings.Join(messages, "\n"))
+
+	tryRemoveTempFile(definitions)
+	tryRemoveTempFile(schema)
+	tryRemoveTempFile(document)
+}
+
+func TestJSONSchemaValidatorIdAndAnchorRefs(t *testing.T) {
+	schema := createTestFile(`
+$id: https://example.com/schemas/config
+properties:
+  name: {$ref: "#name"}
+  owner: {$ref: "https://example.com/schemas/person"}
+$defs:
+  name: {$anchor: name, type: string}
+  person: {$id: person, required: [email]}
+`)
+	validator, err := NewJSONSchemaValidator(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := createTestFile("name: [frodo]\nowner: {}\n")
+	violations, err := validator.ValidateFile(document, NewYamlDecoder(ConfiguredYamlPreferences))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = fmt.Sprintf("%v: %v", violation.PathString(), violation.Message)
+	}
+	test.AssertResult(t, "name: expected string but found array\nowner: missing required property 'email'", strings.Join(messages, "\n"))
+
+	tryRemoveTempFile(schema)
+	tryRemoveTempFile(document)
+}
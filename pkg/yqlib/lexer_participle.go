@@ -49,6 +49,13 @@ var participleYqRules = []*participleYqRule{
 	simpleOp("from_?unix", fromUnixOpType),
 	simpleOp("to_?unix", toUnixOpType),
 	simpleOp("with_dtf", withDtFormatOpType),
+	simpleOp("date_?add", dateAddOpType),
+	simpleOp("date_?diff", dateDiffOpType),
+	simpleOp("date_?trunc", dateTruncOpType),
+	{"Weekday", `weekday`, opTokenWithPrefs(dateTimePartOpType, nil, dateTimePartPrefs{Part: weekdayPart}), 0},
+	{"WeekNumber", `week_?number`, opTokenWithPrefs(dateTimePartOpType, nil, dateTimePartPrefs{Part: weekNumberPart}), 0},
+	{"Strftime", `strftime`, opToken(formatDateTimeOpType), 0},
+	simpleOp("strptime", strptimeOpType),
 	simpleOp("error", errorOpType),
 	simpleOp("shuffle", shuffleOpType),
 	simpleOp("sortKeys", sortKeysOpType),
@@ -64,7 +71,10 @@ var participleYqRules = []*participleYqRule{
 	{"YamlEncode", `to_?yaml|@yaml`, encodeWithIndent(YamlOutputFormat, 2), 0},
 
 	{"JSONEncode", `to_?json`, encodeWithIndent(JSONOutputFormat, 2), 0},
+	{"JSONEscape", `@json_?escape|@json-escape`, encodeWithIndent(JSONEscapeOutputFormat, 0), 0},
+	{"JSONUnescape", `@json_?unescape|@json-unescape`, decodeOp(JSONEscapeInputFormat), 0},
 	{"JSONEncodeNoIndent", `@json`, encodeWithIndent(JSONOutputFormat, 0), 0},
+	{"CanonicalJSONEncode", `to_?canonical_?json|@canonical_?json`, encodeWithIndent(CanonicalJSONOutputFormat, 0), 0},
 
 	{"PropertiesDecode", `from_?props|@propsd`, decodeOp(PropertiesInputFormat), 0},
 	{"PropsEncode", `to_?props|@props`, encodeWithIndent(PropsOutputFormat, 2), 0},
@@ -82,8 +92,26 @@ var participleYqRules = []*participleYqRule{
 	{"Base64d", `@base64d`, decodeOp(Base64InputFormat), 0},
 	{"Base64", `@base64`, encodeWithIndent(Base64OutputFormat, 0), 0},
 
+	{"Base32d", `@base32d`, decodeOp(Base32InputFormat), 0},
+	{"Base32", `@base32`, encodeWithIndent(Base32OutputFormat, 0), 0},
+
+	{"Hexd", `@hexd`, decodeOp(HexInputFormat), 0},
+	{"Hex", `@hex`, encodeWithIndent(HexOutputFormat, 0), 0},
+
+	{"Gunzip", `@gunzip|@gzipd`, decodeOp(GzipInputFormat), 0},
+	{"Gzip", `@gzip`, encodeWithIndent(GzipOutputFormat, 0), 0},
+
+	{"Htmld", `@htmld`, decodeOp(HtmlInputFormat), 0},
+	{"Html", `@html`, encodeWithIndent(HtmlOutputFormat, 0), 0},
+
+	{"Text", `@text`, encodeWithIndent(TextOutputFormat, 0), 0},
+
 	{"Urid", `@urid`, decodeOp(UriInputFormat), 0},
 	{"Uri", `@uri`, encodeWithIndent(UriOutputFormat, 0), 0},
+	{"Sha256", `@sha256`, encodeWithIndent(Sha256OutputFormat, 0), 0},
+	{"Sha1", `@sha1`, encodeWithIndent(Sha1OutputFormat, 0), 0},
+	{"Md5", `@md5`, encodeWithIndent(Md5OutputFormat, 0), 0},
+	{"Crc32", `@crc32`, encodeWithIndent(Crc32OutputFormat, 0), 0},
 	{"SH", `@sh`, encodeWithIndent(ShOutputFormat, 0), 0},
 
 	{"LoadXML", `load_?xml|xml_?load`, loadOp(NewXMLDecoder(ConfiguredXMLPreferences), false), 0},
@@ -115,6 +143,13 @@ var participleYqRules = []*participleYqRule{
 	simpleOp("match", matchOpType),
 	simpleOp("capture", captureOpType),
 	simpleOp("test", testOpType),
+	simpleOp("scan", scanOpType),
+
+	simpleOp("semver_?parse", semverParseOpType),
+	simpleOp("semver_?compare", semverCompareOpType),
+	simpleOp("semver_?satisfies", semverSatisfiesOpType),
+	simpleOp("semver_?bump", semverBumpOpType),
+	simpleOp("semver", semverOpType),
 
 	simpleOp("sort_?by", sortByOpType),
 	simpleOp("sort", sortOpType),
@@ -128,6 +163,7 @@ var participleYqRules = []*participleYqRule{
 	simpleOp("all", allOpType),
 
 	simpleOp("contains", containsOpType),
+	simpleOp("splits", splitsOpType),
 	simpleOp("split", splitStringOpType),
 	simpleOp("parent", getParentOpType),
 
@@ -137,14 +173,26 @@ var participleYqRules = []*participleYqRule{
 
 	simpleOp("file_?name|fileName", getFilenameOpType),
 	simpleOp("file_?index|fileIndex|fi", getFileIndexOpType),
+	simpleOp("inputs", inputsOpType),
+	simpleOp("input", inputOpType),
+	simpleOp("to_?stream|toStream", toStreamOpType),
+	simpleOp("from_?stream|fromStream", fromStreamOpType),
+	simpleOp("truncate_?stream|truncateStream", truncateStreamOpType),
 	simpleOp("path", getPathOpType),
 	simpleOp("set_?path", setPathOpType),
 	simpleOp("del_?paths", delPathsOpType),
+	{"MergePatch", `merge_?patch`, opTokenWithPrefs(patchOpType, nil, patchPreferences{MergePatch: true}), 0},
+	{"Patch", `patch`, opTokenWithPrefs(patchOpType, nil, patchPreferences{}), 0},
+	simpleOp("diff", diffOpType),
+	simpleOp("validate", validateOpType),
+	simpleOp("infer_?schema", inferSchemaOpType),
+	simpleOp("render", renderOpType),
 
 	simpleOp("to_?entries|toEntries", toEntriesOpType),
 	simpleOp("from_?entries|fromEntries", fromEntriesOpType),
 	simpleOp("with_?entries|withEntries", withEntriesOpType),
 
+	simpleOp("with_?keys|withKeys|rename_?keys|renameKeys", renameKeysOpType),
 	simpleOp("with", withOpType),
 
 	simpleOp("collect", collectOpType),
@@ -165,6 +213,11 @@ var participleYqRules = []*participleYqRule{
 
 	{"Uppercase", `upcase|ascii_?upcase`, opTokenWithPrefs(changeCaseOpType, nil, changeCasePrefs{ToUpperCase: true}), 0},
 	{"Downcase", `downcase|ascii_?downcase`, opTokenWithPrefs(changeCaseOpType, nil, changeCasePrefs{ToUpperCase: false}), 0},
+	{"CamelCase", `camel_?case|camelCase`, opTokenWithPrefs(convertCaseOpType, nil, convertCasePrefs{Style: camelCaseStyle}), 0},
+	{"PascalCase", `pascal_?case|pascalCase`, opTokenWithPrefs(convertCaseOpType, nil, convertCasePrefs{Style: pascalCaseStyle}), 0},
+	{"ScreamingSnakeCase", `screaming_?snake_?case`, opTokenWithPrefs(convertCaseOpType, nil, convertCasePrefs{Style: screamingSnakeCaseStyle}), 0},
+	{"SnakeCase", `snake_?case|snakeCase`, opTokenWithPrefs(convertCaseOpType, nil, convertCasePrefs{Style: snakeCaseStyle}), 0},
+	{"KebabCase", `kebab_?case|kebabCase`, opTokenWithPrefs(convertCaseOpType, nil, convertCasePrefs{Style: kebabCaseStyle}), 0},
 	simpleOp("trim", trimOpType),
 
 	{"HexValue", `0[xX][0-9A-Fa-f]+`, hexValue(), 0},
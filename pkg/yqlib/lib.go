@@ -8,6 +8,7 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"sync"
 
 	logging "gopkg.in/op/go-logging.v1"
 	yaml "gopkg.in/yaml.v3"
@@ -15,10 +16,16 @@ import (
 
 var ExpressionParser ExpressionParserInterface
 
+var initExpressionParserOnce sync.Once
+
+// InitExpressionParser creates the ExpressionParser, it's safe to call from multiple goroutines.
+// The parser itself is stateless, so it can be used concurrently once created.
 func InitExpressionParser() {
-	if ExpressionParser == nil {
-		ExpressionParser = newExpressionParser()
-	}
+	initExpressionParserOnce.Do(func() {
+		if ExpressionParser == nil {
+			ExpressionParser = newExpressionParser()
+		}
+	})
 }
 
 var log = logging.MustGetLogger("yq-lib")
@@ -100,6 +107,11 @@ var nowOpType = &operationType{Type: "NOW", NumArgs: 0, Precedence: 50, Handler:
 var tzOpType = &operationType{Type: "TIMEZONE", NumArgs: 1, Precedence: 50, Handler: tzOp}
 var fromUnixOpType = &operationType{Type: "FROM_UNIX", NumArgs: 0, Precedence: 50, Handler: fromUnixOp}
 var toUnixOpType = &operationType{Type: "TO_UNIX", NumArgs: 0, Precedence: 50, Handler: toUnixOp}
+var dateAddOpType = &operationType{Type: "DATE_ADD", NumArgs: 1, Precedence: 50, Handler: dateAddOp}
+var dateDiffOpType = &operationType{Type: "DATE_DIFF", NumArgs: 1, Precedence: 50, Handler: dateDiffOp}
+var dateTruncOpType = &operationType{Type: "DATE_TRUNC", NumArgs: 1, Precedence: 50, Handler: dateTruncOp}
+var dateTimePartOpType = &operationType{Type: "DATE_TIME_PART", NumArgs: 0, Precedence: 50, Handler: dateTimePartOp}
+var strptimeOpType = &operationType{Type: "STRPTIME", NumArgs: 1, Precedence: 50, Handler: strptimeOp}
 
 var encodeOpType = &operationType{Type: "ENCODE", NumArgs: 0, Precedence: 50, Handler: encodeOperator}
 var decodeOpType = &operationType{Type: "DECODE", NumArgs: 0, Precedence: 50, Handler: decodeOperator}
@@ -131,10 +143,20 @@ var getAliasOpType = &operationType{Type: "GET_ALIAS", NumArgs: 0, Precedence: 5
 var getDocumentIndexOpType = &operationType{Type: "GET_DOCUMENT_INDEX", NumArgs: 0, Precedence: 50, Handler: getDocumentIndexOperator}
 var getFilenameOpType = &operationType{Type: "GET_FILENAME", NumArgs: 0, Precedence: 50, Handler: getFilenameOperator}
 var getFileIndexOpType = &operationType{Type: "GET_FILE_INDEX", NumArgs: 0, Precedence: 50, Handler: getFileIndexOperator}
+var inputOpType = &operationType{Type: "INPUT", NumArgs: 0, Precedence: 50, Handler: inputOperator}
+var inputsOpType = &operationType{Type: "INPUTS", NumArgs: 0, Precedence: 50, Handler: inputsOperator}
+var toStreamOpType = &operationType{Type: "TO_STREAM", NumArgs: 0, Precedence: 50, Handler: toStreamOperator}
+var fromStreamOpType = &operationType{Type: "FROM_STREAM", NumArgs: 1, Precedence: 50, Handler: fromStreamOperator}
+var truncateStreamOpType = &operationType{Type: "TRUNCATE_STREAM", NumArgs: 1, Precedence: 50, Handler: truncateStreamOperator}
 
 var getPathOpType = &operationType{Type: "GET_PATH", NumArgs: 0, Precedence: 50, Handler: getPathOperator}
 var setPathOpType = &operationType{Type: "SET_PATH", NumArgs: 1, Precedence: 50, Handler: setPathOperator}
 var delPathsOpType = &operationType{Type: "DEL_PATHS", NumArgs: 1, Precedence: 50, Handler: delPathsOperator}
+var patchOpType = &operationType{Type: "PATCH", NumArgs: 1, Precedence: 50, Handler: patchOperator}
+var diffOpType = &operationType{Type: "DIFF", NumArgs: 1, Precedence: 50, Handler: diffOperator}
+var validateOpType = &operationType{Type: "VALIDATE", NumArgs: 1, Precedence: 50, Handler: validateOperator}
+var inferSchemaOpType = &operationType{Type: "INFER_SCHEMA", NumArgs: 0, Precedence: 50, Handler: inferSchemaOperator}
+var renderOpType = &operationType{Type: "RENDER", NumArgs: 1, Precedence: 50, Handler: renderOperator}
 
 var explodeOpType = &operationType{Type: "EXPLODE", NumArgs: 1, Precedence: 50, Handler: explodeOperator}
 var sortByOpType = &operationType{Type: "SORT_BY", NumArgs: 1, Precedence: 50, Handler: sortByOperator}
@@ -142,20 +164,30 @@ var reverseOpType = &operationType{Type: "REVERSE", NumArgs: 0, Precedence: 50,
 var sortOpType = &operationType{Type: "SORT", NumArgs: 0, Precedence: 50, Handler: sortOperator}
 var shuffleOpType = &operationType{Type: "SHUFFLE", NumArgs: 0, Precedence: 50, Handler: shuffleOperator}
 
+var semverOpType = &operationType{Type: "SEMVER", NumArgs: 0, Precedence: 50, Handler: semverOperator}
+var semverParseOpType = &operationType{Type: "SEMVER_PARSE", NumArgs: 0, Precedence: 50, Handler: semverParseOperator}
+var semverCompareOpType = &operationType{Type: "SEMVER_COMPARE", NumArgs: 1, Precedence: 50, Handler: semverCompareOperator}
+var semverSatisfiesOpType = &operationType{Type: "SEMVER_SATISFIES", NumArgs: 1, Precedence: 50, Handler: semverSatisfiesOperator}
+var semverBumpOpType = &operationType{Type: "SEMVER_BUMP", NumArgs: 1, Precedence: 50, Handler: semverBumpOperator}
+
 var sortKeysOpType = &operationType{Type: "SORT_KEYS", NumArgs: 1, Precedence: 50, Handler: sortKeysOperator}
 
 var joinStringOpType = &operationType{Type: "JOIN", NumArgs: 1, Precedence: 50, Handler: joinStringOperator}
 var subStringOpType = &operationType{Type: "SUBSTR", NumArgs: 1, Precedence: 50, Handler: substituteStringOperator}
 var matchOpType = &operationType{Type: "MATCH", NumArgs: 1, Precedence: 50, Handler: matchOperator}
 var captureOpType = &operationType{Type: "CAPTURE", NumArgs: 1, Precedence: 50, Handler: captureOperator}
+var scanOpType = &operationType{Type: "SCAN", NumArgs: 1, Precedence: 50, Handler: scanOperator}
+var splitsOpType = &operationType{Type: "SPLITS", NumArgs: 1, Precedence: 50, Handler: splitsOperator}
 var testOpType = &operationType{Type: "TEST", NumArgs: 1, Precedence: 50, Handler: testOperator}
 var splitStringOpType = &operationType{Type: "SPLIT", NumArgs: 1, Precedence: 50, Handler: splitStringOperator}
 var changeCaseOpType = &operationType{Type: "CHANGE_CASE", NumArgs: 0, Precedence: 50, Handler: changeCaseOperator}
+var convertCaseOpType = &operationType{Type: "CONVERT_CASE", NumArgs: 0, Precedence: 50, Handler: convertCaseOperator}
 var trimOpType = &operationType{Type: "TRIM", NumArgs: 0, Precedence: 50, Handler: trimSpaceOperator}
 
 var loadOpType = &operationType{Type: "LOAD", NumArgs: 1, Precedence: 50, Handler: loadYamlOperator}
 
 var keysOpType = &operationType{Type: "KEYS", NumArgs: 0, Precedence: 50, Handler: keysOperator}
+var renameKeysOpType = &operationType{Type: "RENAME_KEYS", NumArgs: 1, Precedence: 50, Handler: renameKeysOperator}
 
 var collectObjectOpType = &operationType{Type: "COLLECT_OBJECT", NumArgs: 0, Precedence: 50, Handler: collectObjectOperator}
 var traversePathOpType = &operationType{Type: "TRAVERSE_PATH", NumArgs: 0, Precedence: 55, Handler: traversePathOperator}
@@ -0,0 +1,728 @@
+package yqlib
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// errMinimalDiffUnsupported is returned for changes that can't be spliced into the original text, in which case
+// the whole file is rewritten instead. These are:
+//   - adding or removing documents, or changing their head and foot comments
+//   - changing the kind, tag, anchor or alias of a node, or between block and flow style
+//   - reordering the keys of a map
+//   - changing multiline plain scalars, or scalars that become multiline inside flow collections
+//   - changing the line comments of flow collections and block scalars
+var errMinimalDiffUnsupported = errors.New("change cannot be spliced into the original text")
+
+type textEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// minimalDiffer finds the source text of the original nodes from their line and column,
+// and records edits that replace only the nodes that differ from the updated document.
+type minimalDiffer struct {
+	source      []byte
+	lineOffsets []int
+	indent      int
+	edits       []textEdit
+}
+
+// minimalDiff returns the original yaml text, with only the parts that differ from the
+// updated yaml text rewritten. errMinimalDiffUnsupported is returned when the changes
+// can't be spliced in, in which case the updated text should be used as is.
+func minimalDiff(original []byte, updated []byte, indent int) ([]byte, error) {
+	originalDocs, err := parseYamlDocuments(original)
+	if err != nil {
+		return nil, err
+	}
+	updatedDocs, err := parseYamlDocuments(updated)
+	if err != nil {
+		return nil, err
+	}
+	if len(originalDocs) != len(updatedDocs) {
+		return nil, errMinimalDiffUnsupported
+	}
+
+	d := newMinimalDiffer(original, indent)
+	for index, originalDoc := range originalDocs {
+		if err := d.diffDocument(originalDoc, updatedDocs[index]); err != nil {
+			return nil, err
+		}
+	}
+	result := d.apply()
+
+	// check the spliced text is what we expect, otherwise it's safer to use the updated text
+	resultDocs, err := parseYamlDocuments(result)
+	if err != nil || len(resultDocs) != len(updatedDocs) {
+		return nil, errMinimalDiffUnsupported
+	}
+	for index, resultDoc := range resultDocs {
+		if !minimalDiffEqual(resultDoc, updatedDocs[index]) {
+			return nil, errMinimalDiffUnsupported
+		}
+	}
+	return result, nil
+}
+
+func parseYamlDocuments(text []byte) ([]*yaml.Node, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(text))
+	documents := make([]*yaml.Node, 0)
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		} else if err != nil {
+			return nil, err
+		}
+		documents = append(documents, &document)
+	}
+}
+
+// minimalDiffEqual checks the nodes have the same values and comments.
+func minimalDiffEqual(lhs *yaml.Node, rhs *yaml.Node) bool {
+	if lhs.Kind != rhs.Kind || lhs.Tag != rhs.Tag || lhs.Value != rhs.Value || lhs.Anchor != rhs.Anchor ||
+		lhs.HeadComment != rhs.HeadComment || lhs.LineComment != rhs.LineComment || lhs.FootComment != rhs.FootComment ||
+		len(lhs.Content) != len(rhs.Content) {
+		return false
+	}
+	for index := range lhs.Content {
+		if !minimalDiffEqual(lhs.Content[index], rhs.Content[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+func newMinimalDiffer(source []byte, indent int) *minimalDiffer {
+	lineOffsets := []int{0}
+	for index, char := range source {
+		if char == '\n' {
+			lineOffsets = append(lineOffsets, index+1)
+		}
+	}
+	return &minimalDiffer{source: source, lineOffsets: lineOffsets, indent: indent}
+}
+
+func (d *minimalDiffer) apply() []byte {
+	// apply from the end, so the offsets of earlier edits are still valid.
+	// Insertions go after replacements that start at the same place.
+	sort.SliceStable(d.edits, func(i, j int) bool {
+		if d.edits[i].start != d.edits[j].start {
+			return d.edits[i].start > d.edits[j].start
+		}
+		return d.edits[i].end > d.edits[j].end
+	})
+	result := append([]byte{}, d.source...)
+	for _, edit := range d.edits {
+		result = append(result[:edit.start], append([]byte(edit.text), result[edit.end:]...)...)
+	}
+	return result
+}
+
+func (d *minimalDiffer) addEdit(start int, end int, text string) {
+	d.edits = append(d.edits, textEdit{start: start, end: end, text: text})
+}
+
+// offset returns the position in the source of the line and (1 based, in characters) column.
+func (d *minimalDiffer) offset(line int, column int) (int, error) {
+	if line < 1 || line > len(d.lineOffsets) {
+		return 0, errMinimalDiffUnsupported
+	}
+	offset := d.lineOffsets[line-1]
+	for character := 1; character < column; character++ {
+		if offset >= len(d.source) {
+			return 0, errMinimalDiffUnsupported
+		}
+		_, size := utf8.DecodeRune(d.source[offset:])
+		offset = offset + size
+	}
+	return offset, nil
+}
+
+func (d *minimalDiffer) nodeOffset(node *yaml.Node) (int, error) {
+	return d.offset(node.Line, node.Column)
+}
+
+func (d *minimalDiffer) lineStart(offset int) int {
+	return bytes.LastIndexByte(d.source[:offset], '\n') + 1
+}
+
+// nextLineStart returns the start of the line after the offset, including the newline.
+func (d *minimalDiffer) nextLineStart(offset int) int {
+	newline := bytes.IndexByte(d.source[offset:], '\n')
+	if newline == -1 {
+		return len(d.source)
+	}
+	return offset + newline + 1
+}
+
+func (d *minimalDiffer) lineEnd(offset int) int {
+	newline := bytes.IndexByte(d.source[offset:], '\n')
+	if newline == -1 {
+		return len(d.source)
+	}
+	return offset + newline
+}
+
+// onlySpacesBefore checks there's nothing before the offset on its line.
+func (d *minimalDiffer) onlySpacesBefore(offset int) bool {
+	return strings.TrimLeft(string(d.source[d.lineStart(offset):offset]), " ") == ""
+}
+
+// commentLinesStart extends the start of the line up to include the given head comment.
+func (d *minimalDiffer) commentLinesStart(lineStart int, headComment string) int {
+	if headComment == "" {
+		return lineStart
+	}
+	commentLines := strings.Count(headComment, "\n") + 1
+	start := lineStart
+	for commentLines > 0 && start > 0 {
+		previous := d.lineStart(start - 1)
+		if !strings.HasPrefix(strings.TrimSpace(string(d.source[previous:start])), "#") {
+			break
+		}
+		start = previous
+		commentLines--
+	}
+	return start
+}
+
+func (d *minimalDiffer) scalarEnd(node *yaml.Node, start int, parentIndent int, inFlow bool) (int, error) {
+	switch {
+	case node.Style&yaml.TaggedStyle != 0:
+		return 0, errMinimalDiffUnsupported
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		for index := start + 1; index < len(d.source); index++ {
+			if d.source[index] == '\\' {
+				index++
+			} else if d.source[index] == '"' {
+				return index + 1, nil
+			}
+		}
+		return 0, errMinimalDiffUnsupported
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		for index := start + 1; index < len(d.source); index++ {
+			if d.source[index] == '\'' {
+				if index+1 < len(d.source) && d.source[index+1] == '\'' {
+					index++
+					continue
+				}
+				return index + 1, nil
+			}
+		}
+		return 0, errMinimalDiffUnsupported
+	case node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		if inFlow {
+			return 0, errMinimalDiffUnsupported
+		}
+		// the block continues while the lines are more indented than the parent (or blank)
+		end := d.lineEnd(start)
+		for lineStart := d.nextLineStart(start); lineStart < len(d.source); lineStart = d.nextLineStart(lineStart) {
+			line := string(d.source[lineStart:d.lineEnd(lineStart)])
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if len(line)-len(strings.TrimLeft(line, " ")) <= parentIndent {
+				break
+			}
+			end = d.lineEnd(lineStart)
+		}
+		return end, nil
+	}
+
+	end := start
+	for ; end < len(d.source); end++ {
+		char := d.source[end]
+		if char == '\n' || (char == '#' && end > start && (d.source[end-1] == ' ' || d.source[end-1] == '\t')) ||
+			(inFlow && (char == ',' || char == ']' || char == '}')) {
+			break
+		}
+	}
+	end = start + len(strings.TrimRight(string(d.source[start:end]), " \t\r"))
+	if string(d.source[start:end]) != node.Value {
+		// multiline plain scalars
+		return 0, errMinimalDiffUnsupported
+	}
+	return end, nil
+}
+
+func (d *minimalDiffer) flowEnd(start int) (int, error) {
+	depth := 0
+	for index := start; index < len(d.source); index++ {
+		switch d.source[index] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return index + 1, nil
+			}
+		case '"', '\'':
+			quoted := &yaml.Node{Style: yaml.DoubleQuotedStyle}
+			if d.source[index] == '\'' {
+				quoted.Style = yaml.SingleQuotedStyle
+			}
+			end, err := d.scalarEnd(quoted, index, 0, true)
+			if err != nil {
+				return 0, err
+			}
+			index = end - 1
+		}
+	}
+	return 0, errMinimalDiffUnsupported
+}
+
+// nodeEnd returns the end of the node's text in the source.
+func (d *minimalDiffer) nodeEnd(node *yaml.Node, parentIndent int) (int, error) {
+	start, err := d.nodeOffset(node)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case node.Kind == yaml.AliasNode:
+		return start + len("*"+node.Value), nil
+	case node.Kind == yaml.ScalarNode:
+		return d.scalarEnd(node, start, parentIndent, false)
+	case node.Style&yaml.FlowStyle != 0:
+		return d.flowEnd(start)
+	case len(node.Content) == 0:
+		return 0, errMinimalDiffUnsupported
+	case node.Kind == yaml.MappingNode:
+		key := node.Content[len(node.Content)-2]
+		return d.nodeEnd(node.Content[len(node.Content)-1], key.Column-1)
+	case node.Kind == yaml.SequenceNode:
+		return d.nodeEnd(node.Content[len(node.Content)-1], node.Column-1)
+	}
+	return 0, errMinimalDiffUnsupported
+}
+
+func (d *minimalDiffer) encode(node *yaml.Node) (string, error) {
+	var output bytes.Buffer
+	encoder := yaml.NewEncoder(&output)
+	encoder.SetIndent(d.indent)
+	if err := encoder.Encode(node); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
+
+// encodeLines encodes the node as whole lines, indented to the given column.
+func (d *minimalDiffer) encodeLines(node *yaml.Node, indent int) (string, error) {
+	encoded, err := d.encode(node)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.SplitAfter(encoded, "\n")
+	for index, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[index] = strings.Repeat(" ", indent) + line
+		}
+	}
+	return strings.Join(lines, ""), nil
+}
+
+func (d *minimalDiffer) diffDocument(original *yaml.Node, updated *yaml.Node) error {
+	if original.HeadComment != updated.HeadComment || original.FootComment != updated.FootComment ||
+		len(original.Content) != len(updated.Content) {
+		return errMinimalDiffUnsupported
+	}
+	if len(original.Content) == 0 {
+		return nil
+	}
+	return d.diffValue(original.Content[0], updated.Content[0], -1, false)
+}
+
+func (d *minimalDiffer) diffValue(original *yaml.Node, updated *yaml.Node, parentIndent int, inFlow bool) error {
+	if minimalDiffEqual(original, updated) {
+		return nil
+	} else if original.Kind != updated.Kind || original.Anchor != updated.Anchor || original.Kind == yaml.AliasNode ||
+		original.HeadComment != updated.HeadComment || original.FootComment != updated.FootComment {
+		return errMinimalDiffUnsupported
+	}
+
+	switch {
+	case original.Kind == yaml.ScalarNode:
+		return d.replaceScalar(original, updated, parentIndent, inFlow)
+	case original.Style&yaml.FlowStyle != 0:
+		return d.replaceFlow(original, updated)
+	case updated.Style&yaml.FlowStyle != 0:
+		return errMinimalDiffUnsupported
+	case original.Kind == yaml.MappingNode:
+		return d.diffBlockMap(original, updated)
+	case original.Kind == yaml.SequenceNode:
+		return d.diffBlockSequence(original, updated)
+	}
+	return errMinimalDiffUnsupported
+}
+
+func withoutComments(node *yaml.Node) *yaml.Node {
+	copied := *node
+	copied.HeadComment = ""
+	copied.LineComment = ""
+	copied.FootComment = ""
+	return &copied
+}
+
+func (d *minimalDiffer) replaceScalar(original *yaml.Node, updated *yaml.Node, parentIndent int, inFlow bool) error {
+	start, err := d.nodeOffset(original)
+	if err != nil {
+		return err
+	}
+	end, err := d.scalarEnd(original, start, parentIndent, inFlow)
+	if err != nil {
+		return err
+	}
+	encoded, err := d.encode(withoutComments(updated))
+	if err != nil {
+		return err
+	}
+	encoded = strings.TrimSuffix(encoded, "\n")
+	if strings.Contains(encoded, "\n") {
+		if inFlow || parentIndent < 0 {
+			return errMinimalDiffUnsupported
+		}
+		// block scalars are indented relative to their parent
+		encoded = strings.ReplaceAll(encoded, "\n", "\n"+strings.Repeat(" ", parentIndent))
+	}
+
+	if original.LineComment != updated.LineComment {
+		if inFlow || original.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+			return errMinimalDiffUnsupported
+		}
+		end = d.lineEnd(end)
+		if updated.LineComment != "" {
+			encoded = encoded + " " + updated.LineComment
+		}
+	}
+	d.addEdit(start, end, encoded)
+	return nil
+}
+
+func (d *minimalDiffer) replaceFlow(original *yaml.Node, updated *yaml.Node) error {
+	if original.LineComment != updated.LineComment {
+		return errMinimalDiffUnsupported
+	}
+	start, err := d.nodeOffset(original)
+	if err != nil {
+		return err
+	}
+	end, err := d.flowEnd(start)
+	if err != nil {
+		return err
+	}
+	flow := deepClone(withoutComments(updated))
+	flow.Style = yaml.FlowStyle
+	encoded, err := d.encode(flow)
+	if err != nil {
+		return err
+	}
+	encoded = strings.TrimSuffix(encoded, "\n")
+	if strings.Contains(encoded, "\n") {
+		return errMinimalDiffUnsupported
+	}
+	d.addEdit(start, end, encoded)
+	return nil
+}
+
+// entryRange returns the whole lines of a map entry or sequence item, including its head comment.
+// start is where the entry's text starts (the key, or the '-' of an item).
+func (d *minimalDiffer) entryRange(start int, headComment string, value *yaml.Node, valueIndent int) (int, int, error) {
+	if !d.onlySpacesBefore(start) {
+		return 0, 0, errMinimalDiffUnsupported
+	}
+	end, err := d.nodeEnd(value, valueIndent)
+	if err != nil {
+		return 0, 0, err
+	}
+	return d.commentLinesStart(d.lineStart(start), headComment), d.nextLineStart(end), nil
+}
+
+// insertLines inserts whole lines at the offset, which is at the start of a line (or the end of the text).
+func (d *minimalDiffer) insertLines(offset int, text string) {
+	if offset == len(d.source) && offset > 0 && d.source[offset-1] != '\n' {
+		text = "\n" + strings.TrimSuffix(text, "\n")
+	}
+	d.addEdit(offset, offset, text)
+}
+
+func (d *minimalDiffer) diffBlockMap(original *yaml.Node, updated *yaml.Node) error {
+	indent := original.Content[0].Column - 1
+
+	// keys that are in both need to be in the same order
+	previousIndex := -1
+	for index := 0; index < len(original.Content); index = index + 2 {
+		if updatedIndex := findJSONPatchKey(updated, original.Content[index].Value); updatedIndex != -1 {
+			if updatedIndex < previousIndex {
+				return errMinimalDiffUnsupported
+			}
+			previousIndex = updatedIndex
+		}
+	}
+
+	entryRange := func(index int) (int, int, error) {
+		key := original.Content[index]
+		keyStart, err := d.nodeOffset(key)
+		if err != nil {
+			return 0, 0, err
+		}
+		return d.entryRange(keyStart, key.HeadComment, original.Content[index+1], key.Column-1)
+	}
+	encodeEntry := func(key *yaml.Node, value *yaml.Node) (string, error) {
+		return d.encodeLines(&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{key, value}}, indent)
+	}
+
+	for index := 0; index < len(original.Content); index = index + 2 {
+		key := original.Content[index]
+		updatedIndex := findJSONPatchKey(updated, key.Value)
+		if updatedIndex == -1 {
+			start, end, err := entryRange(index)
+			if err != nil {
+				return err
+			}
+			d.addEdit(start, end, "")
+			continue
+		}
+
+		updatedKey := updated.Content[updatedIndex]
+		updatedValue := updated.Content[updatedIndex+1]
+		var err error
+		editCount := len(d.edits)
+		if minimalDiffEqual(key, updatedKey) {
+			err = d.diffValue(original.Content[index+1], updatedValue, key.Column-1, false)
+		}
+		if !minimalDiffEqual(key, updatedKey) || errors.Is(err, errMinimalDiffUnsupported) {
+			// rewrite the whole entry
+			d.edits = d.edits[:editCount]
+			start, end, err := entryRange(index)
+			if err != nil {
+				return err
+			}
+			encoded, err := encodeEntry(updatedKey, updatedValue)
+			if err != nil {
+				return err
+			}
+			d.addEdit(start, end, encoded)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	// new entries go after the entry they follow in the updated map
+	insertions := map[int]*strings.Builder{}
+	insertionOffsets := []int{}
+	for index := 0; index < len(updated.Content); index = index + 2 {
+		key := updated.Content[index]
+		if findJSONPatchKey(original, key.Value) != -1 {
+			continue
+		}
+		offset := -1
+		for previous := index - 2; previous >= 0 && offset == -1; previous = previous - 2 {
+			if originalIndex := findJSONPatchKey(original, updated.Content[previous].Value); originalIndex != -1 {
+				_, end, err := entryRange(originalIndex)
+				if err != nil {
+					return err
+				}
+				offset = end
+			}
+		}
+		if offset == -1 {
+			start, _, err := entryRange(0)
+			if err != nil {
+				return err
+			}
+			offset = start
+		}
+		encoded, err := encodeEntry(key, updated.Content[index+1])
+		if err != nil {
+			return err
+		}
+		if _, exists := insertions[offset]; !exists {
+			insertions[offset] = &strings.Builder{}
+			insertionOffsets = append(insertionOffsets, offset)
+		}
+		insertions[offset].WriteString(encoded)
+	}
+	for _, offset := range insertionOffsets {
+		d.insertLines(offset, insertions[offset].String())
+	}
+	return nil
+}
+
+// itemStart returns the position of the '-' of a sequence item.
+func (d *minimalDiffer) itemStart(item *yaml.Node) (int, error) {
+	offset, err := d.nodeOffset(item)
+	if err != nil {
+		return 0, err
+	}
+	dash := bytes.LastIndexByte(d.source[d.lineStart(offset):offset], '-')
+	if dash == -1 {
+		return 0, errMinimalDiffUnsupported
+	}
+	return d.lineStart(offset) + dash, nil
+}
+
+func (d *minimalDiffer) diffBlockSequence(original *yaml.Node, updated *yaml.Node) error {
+	firstItemStart, err := d.itemStart(original.Content[0])
+	if err != nil {
+		return err
+	}
+	indent := firstItemStart - d.lineStart(firstItemStart)
+
+	itemRange := func(index int) (int, int, error) {
+		start, err := d.itemStart(original.Content[index])
+		if err != nil {
+			return 0, 0, err
+		}
+		return d.entryRange(start, original.Content[index].HeadComment, original.Content[index], indent)
+	}
+	encodeItems := func(items []*yaml.Node) (string, error) {
+		return d.encodeLines(&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: items}, indent)
+	}
+	replaceItem := func(index int, item *yaml.Node) error {
+		start, end, err := itemRange(index)
+		if err != nil {
+			return err
+		}
+		encoded, err := encodeItems([]*yaml.Node{item})
+		if err != nil {
+			return err
+		}
+		d.addEdit(start, end, encoded)
+		return nil
+	}
+
+	pending := []*yaml.Node{}
+	insertPending := func(offset int) error {
+		if len(pending) == 0 {
+			return nil
+		}
+		encoded, err := encodeItems(pending)
+		if err != nil {
+			return err
+		}
+		d.insertLines(offset, encoded)
+		pending = []*yaml.Node{}
+		return nil
+	}
+
+	// items are added after the previous original item, or before the first one
+	insertOffset, _, err := itemRange(0)
+	if err != nil {
+		return err
+	}
+	for _, step := range alignSequences(original.Content, updated.Content) {
+		if step.original == -1 {
+			pending = append(pending, updated.Content[step.updated])
+			continue
+		}
+		if err := insertPending(insertOffset); err != nil {
+			return err
+		}
+		start, end, err := itemRange(step.original)
+		if err != nil {
+			return err
+		}
+		insertOffset = end
+
+		if step.updated == -1 {
+			d.addEdit(start, end, "")
+			continue
+		}
+		editCount := len(d.edits)
+		err = d.diffValue(original.Content[step.original], updated.Content[step.updated], indent, false)
+		if errors.Is(err, errMinimalDiffUnsupported) {
+			d.edits = d.edits[:editCount]
+			err = replaceItem(step.original, updated.Content[step.updated])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return insertPending(insertOffset)
+}
+
+// alignmentStep pairs an original item with an updated item, -1 means the item was added or removed.
+type alignmentStep struct {
+	original int
+	updated  int
+}
+
+// similarItems checks if the updated item looks like a modified version of the original.
+func similarItems(original *yaml.Node, updated *yaml.Node) bool {
+	if original.Kind != updated.Kind {
+		return false
+	} else if original.Kind == yaml.MappingNode {
+		// maps are similar if they start with the same entry, e.g. the same name
+		return len(original.Content) > 0 && len(updated.Content) > 0 &&
+			recursiveNodeEqual(original.Content[0], updated.Content[0]) && recursiveNodeEqual(original.Content[1], updated.Content[1])
+	}
+	return true
+}
+
+// alignSequences finds the cheapest way to turn the original items into the updated ones,
+// where modifying a similar item is cheaper than removing it and adding a new one.
+func alignSequences(original []*yaml.Node, updated []*yaml.Node) []alignmentStep {
+	const addOrRemoveCost = 2
+	const modifyCost = 3
+	// cost[i][j] is the cost of aligning original[i:] with updated[j:]
+	cost := make([][]int, len(original)+1)
+	for i := range cost {
+		cost[i] = make([]int, len(updated)+1)
+	}
+	for i := len(original); i >= 0; i-- {
+		for j := len(updated); j >= 0; j-- {
+			switch {
+			case i == len(original):
+				cost[i][j] = (len(updated) - j) * addOrRemoveCost
+			case j == len(updated):
+				cost[i][j] = (len(original) - i) * addOrRemoveCost
+			default:
+				cost[i][j] = addOrRemoveCost + minInt(cost[i+1][j], cost[i][j+1])
+				if minimalDiffEqual(original[i], updated[j]) {
+					cost[i][j] = minInt(cost[i][j], cost[i+1][j+1])
+				} else if similarItems(original[i], updated[j]) {
+					cost[i][j] = minInt(cost[i][j], modifyCost+cost[i+1][j+1])
+				}
+			}
+		}
+	}
+
+	steps := make([]alignmentStep, 0, len(original)+len(updated))
+	i, j := 0, 0
+	for i < len(original) || j < len(updated) {
+		switch {
+		case i < len(original) && j < len(updated) && minimalDiffEqual(original[i], updated[j]) && cost[i][j] == cost[i+1][j+1]:
+			steps = append(steps, alignmentStep{i, j})
+			i, j = i+1, j+1
+		case i < len(original) && j < len(updated) && similarItems(original[i], updated[j]) && cost[i][j] == modifyCost+cost[i+1][j+1]:
+			steps = append(steps, alignmentStep{i, j})
+			i, j = i+1, j+1
+		case i < len(original) && (j == len(updated) || cost[i][j] == addOrRemoveCost+cost[i+1][j]):
+			steps = append(steps, alignmentStep{i, -1})
+			i++
+		default:
+			steps = append(steps, alignmentStep{-1, j})
+			j++
+		}
+	}
+	return steps
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,128 @@
+package yqlib
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+type minimalDiffScenario struct {
+	description string
+	original    string
+	updated     string
+	expected    string
+}
+
+var minimalDiffScenarios = []minimalDiffScenario{
+	{
+		description: "changed scalars keep the formatting around them",
+		original:    "# config\nimage:   'nginx:1.24'   # pinned\n\nreplicas:    1\nports: [ 80,  443 ]\n",
+		updated:     "# config\nimage: 'nginx:1.25' # pinned\nreplicas: 3\nports: [80, 443]\n",
+		expected:    "# config\nimage:   'nginx:1.25'   # pinned\n\nreplicas:    3\nports: [ 80,  443 ]\n",
+	},
+	{
+		description: "changed line comments",
+		original:    "a:    1 # old\nb:  2\n",
+		updated:     "a: 1 # new\nb: 2\n",
+		expected:    "a:    1 # new\nb:  2\n",
+	},
+	{
+		description: "changed values in nested maps and sequences",
+		original:    "spec:\n    containers:\n        -   name: web\n            image: \"nginx\"\n\n        -   name: sidecar\n            image: envoy\n",
+		updated:     "spec:\n  containers:\n    - name: web\n      image: \"nginx:2\"\n    - name: sidecar\n      image: envoy\n",
+		expected:    "spec:\n    containers:\n        -   name: web\n            image: \"nginx:2\"\n\n        -   name: sidecar\n            image: envoy\n",
+	},
+	{
+		description: "added entries",
+		original:    "a:\n    b: 1\n\n    c: 2\nd: 3\n",
+		updated:     "a:\n  b: 1\n  x: {y: 1}\n  c: 2\n  z:\n    - 1\nd: 3\ne: 4\n",
+		expected:    "a:\n    b: 1\n    x: {y: 1}\n\n    c: 2\n    z:\n      - 1\nd: 3\ne: 4\n",
+	},
+	{
+		description: "deleted entries and their comments",
+		original:    "a: 1\n\n# about b\nb:\n    c: 2\n    d: 3\n\ne: 4\n",
+		updated:     "a: 1\ne: 4\n",
+		expected:    "a: 1\n\n\ne: 4\n",
+	},
+	{
+		description: "sequence items",
+		original:    "items:\n  - a\n\n  - b   # second\n  - c\n",
+		updated:     "items:\n  - a\n  - c\n  - d\n",
+		expected:    "items:\n  - a\n\n  - c\n  - d\n",
+	},
+	{
+		description: "modified items are kept apart from added and removed ones",
+		original:    "containers:\n- name: web\n  env:\n  - name: A\n\n- name: sidecar\n",
+		updated:     "containers:\n  - name: first\n  - name: web\n    env:\n      - name: A\n      - name: B\n",
+		expected:    "containers:\n- name: first\n- name: web\n  env:\n  - name: A\n  - name: B\n\n",
+	},
+	{
+		description: "changed flow collections",
+		original:    "a: [1,   2]  # numbers\nb: {c: 1}\n",
+		updated:     "a: [1, 2, 3] # numbers\nb: {c: 1}\n",
+		expected:    "a: [1, 2, 3]  # numbers\nb: {c: 1}\n",
+	},
+	{
+		description: "changed block scalars",
+		original:    "script: |\n    echo one\n    echo two\nafter: true\n",
+		updated:     "script: |\n  echo three\nafter: true\n",
+		expected:    "script: |\n  echo three\nafter: true\n",
+	},
+	{
+		description: "changed type of a value",
+		original:    "a:   1\nb:\n   c: 2\n",
+		updated:     "a: 1\nb: cat\n",
+		expected:    "a:   1\nb: cat\n",
+	},
+	{
+		description: "several documents",
+		original:    "a:   1\n---\n# second\nb:   2\n",
+		updated:     "a: 1\n---\n# second\nb: 5\n",
+		expected:    "a:   1\n---\n# second\nb:   5\n",
+	},
+	{
+		description: "no trailing newline",
+		original:    "a:   1",
+		updated:     "a: 1\nb: 2\n",
+		expected:    "a:   1\nb: 2",
+	},
+}
+
+func TestMinimalDiffScenarios(t *testing.T) {
+	for _, s := range minimalDiffScenarios {
+		result, err := minimalDiff([]byte(s.original), []byte(s.updated), 2)
+		if err != nil {
+			t.Error(s.description, err)
+			continue
+		}
+		test.AssertResultWithContext(t, s.expected, string(result), s.description)
+	}
+}
+
+func TestMinimalDiffUnsupportedChanges(t *testing.T) {
+	// reordered keys can't be spliced in
+	_, err := minimalDiff([]byte("b:   1\na:   2\n"), []byte("a: 2\nb: 1\n"), 2)
+	if !errors.Is(err, errMinimalDiffUnsupported) {
+		t.Errorf("expected the change to be unsupported, but got %v", err)
+	}
+}
+
+func TestMinimalDiffWriteInPlace(t *testing.T) {
+	file := createTestFile("a:   1  # keep\n\nb:    [x,  y]\n")
+
+	handler := NewMinimalDiffWriteInPlaceHandler(file, 2)
+	out, err := handler.CreateTempFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := out.WriteString("a: 2 # keep\nb: [x, y]\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := handler.FinishWriteInPlace(true); err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResult(t, "a:   2  # keep\n\nb:    [x,  y]\n", readFile(file))
+
+	tryRemoveTempFile(file)
+}
@@ -9,3 +9,7 @@ func NewJSONDecoder() Decoder {
 func NewJSONEncoder(indent int, colorise bool, unwrapScalar bool) Encoder {
 	return nil
 }
+
+func NewJSONStreamDecoder() Decoder {
+	return nil
+}
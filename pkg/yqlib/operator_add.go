@@ -147,7 +147,7 @@ func addScalars(context Context, target *CandidateNode, lhs *yaml.Node, rhs *yam
 
 func addDateTimes(layout string, target *CandidateNode, lhs *yaml.Node, rhs *yaml.Node) error {
 
-	duration, err := time.ParseDuration(rhs.Value)
+	duration, err := parseDateDuration(rhs.Value)
 	if err != nil {
 		return fmt.Errorf("unable to parse duration [%v]: %w", rhs.Value, err)
 	}
@@ -157,8 +157,12 @@ func addDateTimes(layout string, target *CandidateNode, lhs *yaml.Node, rhs *yam
 		return err
 	}
 
-	newTime := currentTime.Add(duration)
-	target.Node.Value = newTime.Format(layout)
+	newTime := duration.addTo(currentTime)
+	formatted, err := formatTime(newTime, layout)
+	if err != nil {
+		return err
+	}
+	target.Node.Value = formatted
 	return nil
 
 }
@@ -73,7 +73,26 @@ func compareDateTime(layout string, prefs compareTypePref, lhs *yaml.Node, rhs *
 
 }
 
+func compareSemver(prefs compareTypePref, lhs *yaml.Node, rhs *yaml.Node) (bool, error) {
+	result, err := compareSemverNodes(lhs, rhs)
+	if err != nil {
+		return false, err
+	}
+
+	if prefs.OrEqual && result == 0 {
+		return true, nil
+	}
+	if prefs.Greater {
+		return result > 0, nil
+	}
+	return result < 0, nil
+}
+
 func compareScalars(context Context, prefs compareTypePref, lhs *yaml.Node, rhs *yaml.Node) (bool, error) {
+	if lhs.Tag == semverTag || rhs.Tag == semverTag {
+		return compareSemver(prefs, lhs, rhs)
+	}
+
 	lhsTag := guessTagFromCustomType(lhs)
 	rhsTag := guessTagFromCustomType(rhs)
 
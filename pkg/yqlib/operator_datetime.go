@@ -4,7 +4,10 @@ import (
 	"container/list"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -28,6 +31,9 @@ func withDateTimeFormat(d *dataTreeNavigator, context Context, expressionNode *E
 		if err != nil {
 			return Context{}, fmt.Errorf("could not get date time format: %w", err)
 		}
+		if err := validateDateTimeLayout(layout); err != nil {
+			return Context{}, err
+		}
 		context.SetDateTimeLayout(layout)
 		return d.GetMatchingNodes(context, expressionNode.RHS.RHS)
 
@@ -53,7 +59,7 @@ func nowOp(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode
 
 func parseDateTime(layout string, datestring string) (time.Time, error) {
 
-	parsedTime, err := time.Parse(layout, datestring)
+	parsedTime, err := parseTime(layout, datestring)
 	if err != nil && layout == time.RFC3339 {
 		// try parsing the date time with only the date
 		return time.Parse("2006-01-02", datestring)
@@ -69,6 +75,9 @@ func formatDateTime(d *dataTreeNavigator, context Context, expressionNode *Expre
 	if err != nil {
 		return Context{}, err
 	}
+	if err := validateDateTimeLayout(format); err != nil {
+		return Context{}, err
+	}
 	var results = list.New()
 
 	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
@@ -78,7 +87,10 @@ func formatDateTime(d *dataTreeNavigator, context Context, expressionNode *Expre
 		if err != nil {
 			return Context{}, fmt.Errorf("could not parse datetime of [%v]: %w", candidate.GetNicePath(), err)
 		}
-		formattedTimeStr := parsedTime.Format(format)
+		formattedTimeStr, err := formatTime(parsedTime, format)
+		if err != nil {
+			return Context{}, err
+		}
 
 		node, errorReading := parseSnippet(formattedTimeStr)
 		if errorReading != nil {
@@ -119,10 +131,15 @@ func tzOp(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode)
 		}
 		tzTime := parsedTime.In(timezone)
 
+		formatted, err := formatTime(tzTime, layout)
+		if err != nil {
+			return Context{}, err
+		}
+
 		node := &yaml.Node{
 			Kind:  yaml.ScalarNode,
 			Tag:   candidate.Node.Tag,
-			Value: tzTime.Format(layout),
+			Value: formatted,
 		}
 
 		results.PushBack(candidate.CreateReplacement(node))
@@ -196,3 +213,478 @@ func toUnixOp(d *dataTreeNavigator, context Context, expressionNode *ExpressionN
 
 	return context.ChildContext(results), nil
 }
+
+type strftimeDirective struct {
+	// the equivalent golang layout
+	layout string
+	// matches the text the directive formats to, for splitting it apart when parsing
+	pattern string
+}
+
+// strftime directives, and their equivalent golang layouts
+var strftimeDirectives = map[byte]strftimeDirective{
+	'a': {"Mon", `[A-Za-z]{3}`},
+	'A': {"Monday", `[A-Za-z]+`},
+	'b': {"Jan", `[A-Za-z]{3}`},
+	'B': {"January", `[A-Za-z]+`},
+	'd': {"02", `\d{2}`},
+	'D': {"01/02/06", `\d{2}/\d{2}/\d{2}`},
+	'e': {"_2", ` ?\d{1,2}`},
+	'F': {"2006-01-02", `\d{4}-\d{2}-\d{2}`},
+	'H': {"15", `\d{1,2}`},
+	'h': {"Jan", `[A-Za-z]{3}`},
+	'I': {"03", `\d{2}`},
+	'j': {"002", `\d{3}`},
+	'm': {"01", `\d{2}`},
+	'M': {"04", `\d{2}`},
+	'p': {"PM", `[AP]M`},
+	'R': {"15:04", `\d{1,2}:\d{2}`},
+	'S': {"05", `\d{2}`},
+	'T': {"15:04:05", `\d{1,2}:\d{2}:\d{2}`},
+	'y': {"06", `\d{2}`},
+	'Y': {"2006", `\d{4}`},
+	'z': {"-0700", `[+-]\d{4}`},
+	'Z': {"MST", `[A-Z]{3,5}|[+-]\d{2}(?:\d{2})?`},
+}
+
+// strftimeLayout is a strftime/strptime style layout (e.g. "%Y-%m-%d"). Golang layouts have no way to
+// escape literal text, so the directives are formatted one at a time rather than converted into a
+// single golang layout.
+type strftimeLayout struct {
+	// the golang layout of each directive, or the literal text between them
+	segments []strftimeSegment
+	// captures the text of each directive
+	parser *regexp.Regexp
+	// the layouts of the directives, separated so that they parse the captured text
+	parseLayout string
+	// separates the directives in parseLayout, and isn't read as part of any golang layout
+	separator string
+}
+
+type strftimeSegment struct {
+	layout  string
+	literal string
+}
+
+// strftime layouts are kept in the context as given, so they're compiled once as they're used
+var strftimeLayouts sync.Map
+
+// isStrftimeLayout checks if the layout is strftime/strptime style, anything without a % is a golang layout.
+func isStrftimeLayout(layout string) bool {
+	return strings.Contains(layout, "%")
+}
+
+func compileStrftimeLayout(layout string) (*strftimeLayout, error) {
+	if compiled, ok := strftimeLayouts.Load(layout); ok {
+		return compiled.(*strftimeLayout), nil
+	}
+	compiled := &strftimeLayout{separator: "\x00"}
+	var pattern strings.Builder
+	var parseLayouts []string
+	var literal strings.Builder
+	pattern.WriteString("^")
+	addLiteral := func() {
+		if literal.Len() > 0 {
+			compiled.segments = append(compiled.segments, strftimeSegment{literal: literal.String()})
+			pattern.WriteString(regexp.QuoteMeta(literal.String()))
+			literal.Reset()
+		}
+	}
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' {
+			literal.WriteByte(layout[i])
+			continue
+		}
+		if i+1 >= len(layout) {
+			return nil, fmt.Errorf("incomplete strftime directive at the end of layout [%v]", layout)
+		}
+		i++
+		if layout[i] == '%' {
+			literal.WriteByte('%')
+			continue
+		}
+		directive, ok := strftimeDirectives[layout[i]]
+		if !ok {
+			return nil, fmt.Errorf("unknown strftime directive '%%%c' in layout [%v]", layout[i], layout)
+		}
+		addLiteral()
+		compiled.segments = append(compiled.segments, strftimeSegment{layout: directive.layout})
+		pattern.WriteString("(" + directive.pattern + ")")
+		parseLayouts = append(parseLayouts, directive.layout)
+	}
+	addLiteral()
+	pattern.WriteString("$")
+	compiled.parser = regexp.MustCompile(pattern.String())
+	compiled.parseLayout = strings.Join(parseLayouts, compiled.separator)
+	strftimeLayouts.Store(layout, compiled)
+	return compiled, nil
+}
+
+func (l *strftimeLayout) format(t time.Time) string {
+	var formatted strings.Builder
+	for _, segment := range l.segments {
+		if segment.layout != "" {
+			formatted.WriteString(t.Format(segment.layout))
+		} else {
+			formatted.WriteString(segment.literal)
+		}
+	}
+	return formatted.String()
+}
+
+func (l *strftimeLayout) parse(value string) (time.Time, error) {
+	match := l.parser.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("[%v] does not match the layout", value)
+	}
+	return time.Parse(l.parseLayout, strings.Join(match[1:], l.separator))
+}
+
+// validateDateTimeLayout checks that the directives of strftime layouts are known.
+func validateDateTimeLayout(layout string) error {
+	if !isStrftimeLayout(layout) {
+		return nil
+	}
+	_, err := compileStrftimeLayout(layout)
+	return err
+}
+
+// formatTime formats the time with a golang or strftime style layout.
+func formatTime(t time.Time, layout string) (string, error) {
+	if !isStrftimeLayout(layout) {
+		return t.Format(layout), nil
+	}
+	compiled, err := compileStrftimeLayout(layout)
+	if err != nil {
+		return "", err
+	}
+	return compiled.format(t), nil
+}
+
+// parseTime parses the time with a golang or strftime style layout.
+func parseTime(layout string, value string) (time.Time, error) {
+	if !isStrftimeLayout(layout) {
+		return time.Parse(layout, value)
+	}
+	compiled, err := compileStrftimeLayout(layout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return compiled.parse(value)
+}
+
+// dateDuration is a duration that may include calendar units (years,
+// months, days) that cannot be represented by a fixed time.Duration.
+type dateDuration struct {
+	years    int
+	months   int
+	days     int
+	duration time.Duration
+}
+
+func (dd dateDuration) addTo(t time.Time) time.Time {
+	return t.AddDate(dd.years, dd.months, dd.days).Add(dd.duration)
+}
+
+func (dd dateDuration) negate() dateDuration {
+	return dateDuration{years: -dd.years, months: -dd.months, days: -dd.days, duration: -dd.duration}
+}
+
+var isoDurationRegex = regexp.MustCompile(`^([-+])?P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+var dayDurationRegex = regexp.MustCompile(`^([-+]?\d+)([dw])(.*)$`)
+
+// parseDateDuration parses golang durations (e.g. "72h30m"), golang
+// durations with days or weeks (e.g. "3d12h", "2w") and ISO-8601
+// durations (e.g. "P1Y2M3DT4H", "-P3D").
+func parseDateDuration(durationStr string) (dateDuration, error) {
+	if match := isoDurationRegex.FindStringSubmatch(durationStr); match != nil && durationStr != "P" && !strings.HasSuffix(durationStr, "T") {
+		return parseISODuration(match)
+	}
+
+	if match := dayDurationRegex.FindStringSubmatch(durationStr); match != nil {
+		amount, err := strconv.Atoi(match[1])
+		if err != nil {
+			return dateDuration{}, err
+		}
+		if match[2] == "w" {
+			amount = amount * 7
+		}
+		result := dateDuration{days: amount}
+		if match[3] != "" {
+			duration, err := time.ParseDuration(match[3])
+			if err != nil {
+				return dateDuration{}, err
+			}
+			if strings.HasPrefix(match[1], "-") {
+				duration = -duration
+			}
+			result.duration = duration
+		}
+		return result, nil
+	}
+
+	duration, err := time.ParseDuration(durationStr)
+	if err != nil {
+		return dateDuration{}, err
+	}
+	return dateDuration{duration: duration}, nil
+}
+
+func parseISODuration(match []string) (dateDuration, error) {
+	parts := make([]int, 7)
+	for i, part := range match[2:8] {
+		if part == "" {
+			continue
+		}
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			return dateDuration{}, err
+		}
+		parts[i] = value
+	}
+	result := dateDuration{
+		years:    parts[0],
+		months:   parts[1],
+		days:     parts[2]*7 + parts[3],
+		duration: time.Duration(parts[4])*time.Hour + time.Duration(parts[5])*time.Minute,
+	}
+	if match[8] != "" {
+		seconds, err := strconv.ParseFloat(strings.Replace(match[8], ",", ".", 1), 64)
+		if err != nil {
+			return dateDuration{}, err
+		}
+		result.duration = result.duration + time.Duration(seconds*float64(time.Second))
+	}
+	if match[1] == "-" {
+		result = result.negate()
+	}
+	return result, nil
+}
+
+func dateAddOp(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	durationStr, err := getStringParamter("duration", d, context, expressionNode.RHS)
+	if err != nil {
+		return Context{}, err
+	}
+	duration, err := parseDateDuration(durationStr)
+	if err != nil {
+		return Context{}, fmt.Errorf("unable to parse duration [%v]: %w", durationStr, err)
+	}
+	layout := context.GetDateTimeLayout()
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+
+		parsedTime, err := parseDateTime(layout, candidate.Node.Value)
+		if err != nil {
+			return Context{}, fmt.Errorf("could not parse datetime of [%v] using layout [%v]: %w", candidate.GetNicePath(), layout, err)
+		}
+
+		formatted, err := formatTime(duration.addTo(parsedTime), layout)
+		if err != nil {
+			return Context{}, err
+		}
+
+		node := &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Tag:   candidate.Node.Tag,
+			Value: formatted,
+		}
+
+		results.PushBack(candidate.CreateReplacement(node))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+var dateDiffUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+func dateDiffOp(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	otherExp := expressionNode.RHS
+	unit := ""
+	if expressionNode.RHS.Operation.OperationType == blockOpType || expressionNode.RHS.Operation.OperationType == unionOpType {
+		otherExp = expressionNode.RHS.LHS
+		var err error
+		unit, err = getStringParamter("unit", d, context, expressionNode.RHS.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+		if _, ok := dateDiffUnits[unit]; !ok {
+			return Context{}, fmt.Errorf("unknown date_diff unit [%v], expected one of s, m, h, d or w", unit)
+		}
+	}
+	otherStr, err := getStringParamter("datetime", d, context, otherExp)
+	if err != nil {
+		return Context{}, err
+	}
+	layout := context.GetDateTimeLayout()
+	otherTime, err := parseDateTime(layout, otherStr)
+	if err != nil {
+		return Context{}, fmt.Errorf("could not parse datetime [%v] using layout [%v]: %w", otherStr, layout, err)
+	}
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+
+		parsedTime, err := parseDateTime(layout, candidate.Node.Value)
+		if err != nil {
+			return Context{}, fmt.Errorf("could not parse datetime of [%v] using layout [%v]: %w", candidate.GetNicePath(), layout, err)
+		}
+		difference := parsedTime.Sub(otherTime)
+
+		var node *yaml.Node
+		if unit == "" {
+			node = createStringScalarNode(difference.String())
+		} else {
+			node = &yaml.Node{
+				Kind:  yaml.ScalarNode,
+				Tag:   "!!int",
+				Value: fmt.Sprintf("%v", int64(difference/dateDiffUnits[unit])),
+			}
+		}
+
+		results.PushBack(candidate.CreateReplacement(node))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+func truncateDateTime(t time.Time, unit string) (time.Time, error) {
+	switch unit {
+	case "second":
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, t.Location()), nil
+	case "minute":
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location()), nil
+	case "hour":
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location()), nil
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()), nil
+	case "week":
+		// ISO weeks start on Monday
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, t.Location()), nil
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()), nil
+	case "year":
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location()), nil
+	}
+	return t, fmt.Errorf("unknown date_trunc unit [%v], expected one of second, minute, hour, day, week, month or year", unit)
+}
+
+func dateTruncOp(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	unit, err := getStringParamter("unit", d, context, expressionNode.RHS)
+	if err != nil {
+		return Context{}, err
+	}
+	layout := context.GetDateTimeLayout()
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+
+		parsedTime, err := parseDateTime(layout, candidate.Node.Value)
+		if err != nil {
+			return Context{}, fmt.Errorf("could not parse datetime of [%v] using layout [%v]: %w", candidate.GetNicePath(), layout, err)
+		}
+		truncatedTime, err := truncateDateTime(parsedTime, unit)
+		if err != nil {
+			return Context{}, err
+		}
+
+		formatted, err := formatTime(truncatedTime, layout)
+		if err != nil {
+			return Context{}, err
+		}
+
+		node := &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Tag:   candidate.Node.Tag,
+			Value: formatted,
+		}
+
+		results.PushBack(candidate.CreateReplacement(node))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+type dateTimePartPrefs struct {
+	Part func(time.Time) int
+}
+
+func weekdayPart(t time.Time) int {
+	return int(t.Weekday())
+}
+
+func weekNumberPart(t time.Time) int {
+	_, week := t.ISOWeek()
+	return week
+}
+
+func dateTimePartOp(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	prefs := expressionNode.Operation.Preferences.(dateTimePartPrefs)
+	layout := context.GetDateTimeLayout()
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+
+		parsedTime, err := parseDateTime(layout, candidate.Node.Value)
+		if err != nil {
+			return Context{}, fmt.Errorf("could not parse datetime of [%v] using layout [%v]: %w", candidate.GetNicePath(), layout, err)
+		}
+
+		node := &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Tag:   "!!int",
+			Value: fmt.Sprintf("%v", prefs.Part(parsedTime)),
+		}
+
+		results.PushBack(candidate.CreateReplacement(node))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+func strptimeOp(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	format, err := getStringParamter("format", d, context, expressionNode.RHS)
+	if err != nil {
+		return Context{}, err
+	}
+	if err := validateDateTimeLayout(format); err != nil {
+		return Context{}, err
+	}
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+
+		parsedTime, err := parseTime(format, candidate.Node.Value)
+		if err != nil {
+			return Context{}, fmt.Errorf("could not parse datetime of [%v] using layout [%v]: %w", candidate.GetNicePath(), format, err)
+		}
+
+		node := &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Tag:   "!!timestamp",
+			Value: parsedTime.Format(time.RFC3339),
+		}
+
+		results.PushBack(candidate.CreateReplacement(node))
+	}
+
+	return context.ChildContext(results), nil
+}
@@ -117,6 +117,157 @@ var dateTimeOperatorScenarios = []expressionScenario{
 			"D0, P[], (doc)::a: Saturday, 15-Dec-01 at 2:00PM AWST\n",
 		},
 	},
+	{
+		description:    "Date addition with ISO-8601 durations",
+		subdescription: "Durations can be golang durations (`72h`), golang durations with days or weeks (`3d12h`, `2w`) or ISO-8601 durations (`P1Y2M3DT4H`).",
+		document:       `a: 2021-01-31T10:00:00Z`,
+		expression:     `.a += "P1M3DT2H"`,
+		expected: []string{
+			"D0, P[], (doc)::a: 2021-03-06T12:00:00Z\n",
+		},
+	},
+	{
+		description: "Date add",
+		document:    `a: 2021-01-01T00:00:00Z`,
+		expression:  `.a |= date_add("3d")`,
+		expected: []string{
+			"D0, P[], (doc)::a: 2021-01-04T00:00:00Z\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: 2021-01-04T00:00:00Z`,
+		expression: `.a |= date_add("-P3D")`,
+		expected: []string{
+			"D0, P[], (doc)::a: 2021-01-01T00:00:00Z\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: 2021-01-04T00:00:00Z`,
+		expression: `.a -= "1w12h"`,
+		expected: []string{
+			"D0, P[], (doc)::a: 2020-12-27T12:00:00Z\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `a: 2021-01-04T00:00:00Z`,
+		expression:    `.a |= date_add("3 days")`,
+		expectedError: "unable to parse duration [3 days]: time: unknown unit \" days\" in duration \"3 days\"",
+	},
+	{
+		description:    "Date difference",
+		subdescription: "Returns the duration between the datetime and the given datetime.",
+		document:       "expires: 2021-06-01T00:00:00Z\nissued: 2021-05-19T12:00:00Z",
+		expression:     `.expires | date_diff(now)`,
+		expected: []string{
+			"D0, P[expires], (!!str)::310h57m57s\n",
+		},
+	},
+	{
+		description:    "Date difference in units",
+		subdescription: "Pass a unit (s, m, h, d or w) to get the whole number of units between the two datetimes.",
+		document:       "expires: 2021-06-01T00:00:00Z\nissued: 2021-05-19T12:00:00Z",
+		expression:     `.expires | date_diff(now; "d")`,
+		expected: []string{
+			"D0, P[expires], (!!int)::12\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "expires: 2021-06-01T00:00:00Z\nissued: 2021-05-19T12:00:00Z",
+		expression: `.issued | date_diff(parent | .expires; "h")`,
+		expected: []string{
+			"D0, P[issued], (!!int)::-300\n",
+		},
+	},
+	{
+		description:    "Date truncate",
+		subdescription: "Truncates to the start of the second, minute, hour, day, week (Monday), month or year.",
+		document:       `a: 2021-05-19T13:14:15+10:00`,
+		expression:     `.a | [date_trunc("day"), date_trunc("week"), date_trunc("month")]`,
+		expected: []string{
+			"D0, P[a], (!!seq)::- 2021-05-19T00:00:00+10:00\n- 2021-05-17T00:00:00+10:00\n- 2021-05-01T00:00:00+10:00\n",
+		},
+	},
+	{
+		description:    "Weekday and week number",
+		subdescription: "`weekday` returns the day of the week, where Sunday is 0. `week_number` returns the ISO-8601 week number.",
+		document:       `a: 2021-01-03`,
+		expression:     `.a | [weekday, week_number]`,
+		expected: []string{
+			"D0, P[a], (!!seq)::- 0\n- 53\n",
+		},
+	},
+	{
+		description:    "Format using strftime",
+		subdescription: "strftime style layouts work for `format_datetime` and `with_dtf` too. Text outside of the directives is kept as it is.",
+		document:       `a: 2001-12-15T02:59:43.1Z`,
+		expression:     `.a |= strftime("%A, %d %B %Y %H:%M")`,
+		expected: []string{
+			"D0, P[], (doc)::a: Saturday, 15 December 2001 02:59\n",
+		},
+	},
+	{
+		description:    "Parse using strptime",
+		subdescription: "Parses a string using the strftime style layout into a RFC3339 timestamp.",
+		document:       `a: 15/12/2001 02:59`,
+		expression:     `.a |= strptime("%d/%m/%Y %H:%M")`,
+		expected: []string{
+			"D0, P[], (doc)::a: 2001-12-15T02:59:00Z\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: 2024-03-05`,
+		expression: `.a |= format_datetime("%Y-%m-%d (week 1, Monday) 100%% Jan")`,
+		expected: []string{
+			"D0, P[], (doc)::a: 2024-03-05 (week 1, Monday) 100% Jan\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: "Monday 2006: 15 Dec 2001, at 02:59PM"`,
+		expression: `.a |= strptime("Monday 2006: %d %b %Y, at %I:%M%p")`,
+		expected: []string{
+			"D0, P[], (doc)::a: 2001-12-15T14:59:00Z\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: "Monday 15/12/2001"`,
+		expression: `.a |= with_dtf("Monday %d/%m/%Y"; . + "P1D")`,
+		expected: []string{
+			"D0, P[], (doc)::a: \"Monday 16/12/2001\"\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `a: 2024-03-05`,
+		expression:    `.a |= format_datetime("%Y %Q")`,
+		expectedError: "unknown strftime directive '%Q' in layout [%Y %Q]",
+	},
+	{
+		skipDoc:       true,
+		document:      `a: 2024-03-05`,
+		expression:    `.a |= format_datetime("%Y %")`,
+		expectedError: "incomplete strftime directive at the end of layout [%Y %]",
+	},
+	{
+		skipDoc:       true,
+		document:      `a: 15/12/2001`,
+		expression:    `.a |= strptime("%d/%m/%Y %H")`,
+		expectedError: "could not parse datetime of [a] using layout [%d/%m/%Y %H]: [15/12/2001] does not match the layout",
+	},
+	{
+		skipDoc:    true,
+		document:   `a: 15/12/2001`,
+		expression: `.a |= with_dtf("%d/%m/%Y"; . + "P1D")`,
+		expected: []string{
+			"D0, P[], (doc)::a: 16/12/2001\n",
+		},
+	},
 	{
 		description: "allow comma",
 		skipDoc:     true,
@@ -0,0 +1,360 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+type diffPreferences struct {
+	Format           string
+	IgnoreArrayOrder bool
+	IgnoreComments   bool
+}
+
+type diffChange struct {
+	Change   string // added, removed, changed or comment
+	Path     []interface{}
+	OldValue *yaml.Node
+	NewValue *yaml.Node
+}
+
+// diffOperator compares each matching node with the given document, returning the
+// differences as a change set, a json patch or a human readable listing.
+// DIFF(other) or DIFF(other; options)
+func diffOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- diffOperator")
+
+	otherExp := expressionNode.RHS
+	var optionsExp *ExpressionNode
+	if expressionNode.RHS.Operation.OperationType == blockOpType {
+		otherExp = expressionNode.RHS.LHS
+		optionsExp = expressionNode.RHS.RHS
+	}
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+
+		prefs, err := getDiffPreferences(d, context.SingleReadonlyChildContext(candidate), optionsExp)
+		if err != nil {
+			return Context{}, err
+		}
+
+		otherContext, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), otherExp)
+		if err != nil {
+			return Context{}, err
+		}
+		if otherContext.MatchingNodes.Len() != 1 {
+			return Context{}, fmt.Errorf("DIFF: expected a single document to compare with but found %v", otherContext.MatchingNodes.Len())
+		}
+		other := unwrapDoc(otherContext.MatchingNodes.Front().Value.(*CandidateNode).Node)
+
+		changes := diffNodes([]interface{}{}, unwrapDoc(candidate.Node), other, nil, nil, prefs)
+
+		var resultNode *yaml.Node
+		switch prefs.Format {
+		case "patch":
+			resultNode = diffChangesToJSONPatch(changes)
+		case "text":
+			resultNode = createStringScalarNode(diffChangesToText(changes))
+		default:
+			resultNode = diffChangesToChangeSet(changes)
+		}
+		results.PushBack(candidate.CreateReplacement(resultNode))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+func getDiffPreferences(d *dataTreeNavigator, context Context, optionsExp *ExpressionNode) (diffPreferences, error) {
+	prefs := diffPreferences{Format: "changes"}
+	if optionsExp == nil {
+		return prefs, nil
+	}
+	optionsContext, err := d.GetMatchingNodes(context, optionsExp)
+	if err != nil {
+		return prefs, err
+	}
+	if optionsContext.MatchingNodes.Len() != 1 {
+		return prefs, fmt.Errorf("DIFF: expected a single options map but found %v", optionsContext.MatchingNodes.Len())
+	}
+	options := unwrapDoc(optionsContext.MatchingNodes.Front().Value.(*CandidateNode).Node)
+	if options.Kind != yaml.MappingNode {
+		return prefs, fmt.Errorf("DIFF: options must be a map, got %v", options.Tag)
+	}
+	for index := 0; index < len(options.Content); index = index + 2 {
+		key := options.Content[index].Value
+		value := options.Content[index+1].Value
+		switch key {
+		case "format":
+			if value != "changes" && value != "patch" && value != "text" {
+				return prefs, fmt.Errorf("DIFF: unknown format '%v', expected one of changes, patch or text", value)
+			}
+			prefs.Format = value
+		case "ignore_array_order":
+			prefs.IgnoreArrayOrder = value == "true"
+		case "ignore_comments":
+			prefs.IgnoreComments = value == "true"
+		default:
+			return prefs, fmt.Errorf("DIFF: unknown option '%v', expected one of format, ignore_array_order or ignore_comments", key)
+		}
+	}
+	return prefs, nil
+}
+
+func appendDiffPath(path []interface{}, element interface{}) []interface{} {
+	newPath := make([]interface{}, len(path), len(path)+1)
+	copy(newPath, path)
+	return append(newPath, element)
+}
+
+func diffComments(nodes ...*yaml.Node) string {
+	comments := make([]string, 0)
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		for _, comment := range []string{node.HeadComment, node.LineComment, node.FootComment} {
+			if comment != "" {
+				comments = append(comments, comment)
+			}
+		}
+	}
+	return strings.Join(comments, "\n")
+}
+
+// diffNodes returns the changes needed to go from oldNode to newNode. Changes to arrays are
+// ordered so that they can be applied one after the other (as a json patch would be):
+// removals are given from the highest index down, followed by additions.
+func diffNodes(path []interface{}, oldNode *yaml.Node, newNode *yaml.Node, oldKey *yaml.Node, newKey *yaml.Node, prefs diffPreferences) []diffChange {
+	if oldNode.Kind == yaml.AliasNode {
+		oldNode = oldNode.Alias
+	}
+	if newNode.Kind == yaml.AliasNode {
+		newNode = newNode.Alias
+	}
+
+	changes := make([]diffChange, 0)
+
+	if !prefs.IgnoreComments {
+		oldComments := diffComments(oldKey, oldNode)
+		newComments := diffComments(newKey, newNode)
+		if oldComments != newComments {
+			changes = append(changes, diffChange{
+				Change:   "comment",
+				Path:     path,
+				OldValue: createStringScalarNode(oldComments),
+				NewValue: createStringScalarNode(newComments),
+			})
+		}
+	}
+
+	if oldNode.Kind != newNode.Kind || (oldNode.Kind == yaml.ScalarNode && !recursiveNodeEqual(oldNode, newNode)) {
+		return append(changes, diffChange{Change: "changed", Path: path, OldValue: oldNode, NewValue: newNode})
+	}
+
+	switch oldNode.Kind {
+	case yaml.MappingNode:
+		changes = append(changes, diffMaps(path, oldNode, newNode, prefs)...)
+	case yaml.SequenceNode:
+		if prefs.IgnoreArrayOrder {
+			changes = append(changes, diffArraysIgnoringOrder(path, oldNode, newNode)...)
+		} else {
+			changes = append(changes, diffArrays(path, oldNode, newNode, prefs)...)
+		}
+	}
+	return changes
+}
+
+func diffMaps(path []interface{}, oldNode *yaml.Node, newNode *yaml.Node, prefs diffPreferences) []diffChange {
+	changes := make([]diffChange, 0)
+	for index := 0; index < len(oldNode.Content); index = index + 2 {
+		key := oldNode.Content[index]
+		childPath := appendDiffPath(path, key.Value)
+		newIndex := findJSONPatchKey(newNode, key.Value)
+		if newIndex == -1 {
+			changes = append(changes, diffChange{Change: "removed", Path: childPath, OldValue: oldNode.Content[index+1]})
+		} else {
+			changes = append(changes, diffNodes(childPath, oldNode.Content[index+1], newNode.Content[newIndex+1], key, newNode.Content[newIndex], prefs)...)
+		}
+	}
+	for index := 0; index < len(newNode.Content); index = index + 2 {
+		key := newNode.Content[index]
+		if findJSONPatchKey(oldNode, key.Value) == -1 {
+			changes = append(changes, diffChange{Change: "added", Path: appendDiffPath(path, key.Value), NewValue: newNode.Content[index+1]})
+		}
+	}
+	return changes
+}
+
+func diffArrays(path []interface{}, oldNode *yaml.Node, newNode *yaml.Node, prefs diffPreferences) []diffChange {
+	changes := make([]diffChange, 0)
+	for index := 0; index < len(oldNode.Content) && index < len(newNode.Content); index++ {
+		changes = append(changes, diffNodes(appendDiffPath(path, index), oldNode.Content[index], newNode.Content[index], nil, nil, prefs)...)
+	}
+	for index := len(oldNode.Content) - 1; index >= len(newNode.Content); index-- {
+		changes = append(changes, diffChange{Change: "removed", Path: appendDiffPath(path, index), OldValue: oldNode.Content[index]})
+	}
+	for index := len(oldNode.Content); index < len(newNode.Content); index++ {
+		changes = append(changes, diffChange{Change: "added", Path: appendDiffPath(path, index), NewValue: newNode.Content[index]})
+	}
+	return changes
+}
+
+// diffArraysIgnoringOrder treats the arrays as bags of values, only reporting
+// values that are in one array but not the other.
+func diffArraysIgnoringOrder(path []interface{}, oldNode *yaml.Node, newNode *yaml.Node) []diffChange {
+	matched := make([]bool, len(newNode.Content))
+	removed := make([]int, 0)
+	for oldIndex, oldChild := range oldNode.Content {
+		found := false
+		for newIndex, newChild := range newNode.Content {
+			if !matched[newIndex] && recursiveNodeEqual(oldChild, newChild) {
+				matched[newIndex] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			removed = append(removed, oldIndex)
+		}
+	}
+
+	changes := make([]diffChange, 0)
+	for i := len(removed) - 1; i >= 0; i-- {
+		changes = append(changes, diffChange{Change: "removed", Path: appendDiffPath(path, removed[i]), OldValue: oldNode.Content[removed[i]]})
+	}
+	for newIndex, newChild := range newNode.Content {
+		if !matched[newIndex] {
+			changes = append(changes, diffChange{Change: "added", Path: appendDiffPath(path, newIndex), NewValue: newChild})
+		}
+	}
+	return changes
+}
+
+func diffPathNode(path []interface{}) *yaml.Node {
+	pathNode := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+	for _, element := range path {
+		pathNode.Content = append(pathNode.Content, createPathNodeFor(element))
+	}
+	return pathNode
+}
+
+func diffChangesToChangeSet(changes []diffChange) *yaml.Node {
+	changeSet := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, change := range changes {
+		changeNode := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		changeNode.Content = append(changeNode.Content,
+			createStringScalarNode("path"), diffPathNode(change.Path),
+			createStringScalarNode("change"), createStringScalarNode(change.Change),
+		)
+		if change.OldValue != nil {
+			changeNode.Content = append(changeNode.Content, createStringScalarNode("old"), diffValueNode(change.OldValue))
+		}
+		if change.NewValue != nil {
+			changeNode.Content = append(changeNode.Content, createStringScalarNode("new"), diffValueNode(change.NewValue))
+		}
+		changeSet.Content = append(changeSet.Content, changeNode)
+	}
+	return changeSet
+}
+
+// diffChangesToJSONPatch converts the changes to an RFC 6902 json patch.
+// Comment changes cannot be represented in a json patch, and are skipped.
+func diffChangesToJSONPatch(changes []diffChange) *yaml.Node {
+	patch := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, change := range changes {
+		var op string
+		switch change.Change {
+		case "added":
+			op = "add"
+		case "removed":
+			op = "remove"
+		case "changed":
+			op = "replace"
+		default:
+			continue
+		}
+		tokens := make([]string, len(change.Path))
+		for i, element := range change.Path {
+			tokens[i] = fmt.Sprintf("%v", element)
+		}
+		operation := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		operation.Content = append(operation.Content,
+			createStringScalarNode("op"), createStringScalarNode(op),
+			createStringScalarNode("path"), createStringScalarNode(jsonPointerString(tokens)),
+		)
+		if change.NewValue != nil {
+			operation.Content = append(operation.Content, createStringScalarNode("value"), diffValueNode(change.NewValue))
+		}
+		patch.Content = append(patch.Content, operation)
+	}
+	return patch
+}
+
+// diffValueNode copies the value without its comments, changes to comments are reported separately.
+func diffValueNode(node *yaml.Node) *yaml.Node {
+	value := deepClone(node)
+	clearDiffComments(value)
+	return value
+}
+
+func clearDiffComments(node *yaml.Node) {
+	node.HeadComment = ""
+	node.LineComment = ""
+	node.FootComment = ""
+	for _, child := range node.Content {
+		clearDiffComments(child)
+	}
+}
+
+func diffValueText(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	flowNode := diffValueNode(node)
+	setDiffFlowStyle(flowNode)
+	bytes, err := yaml.Marshal(flowNode)
+	if err != nil {
+		return fmt.Sprintf("<%v>", node.Tag)
+	}
+	return strings.TrimSpace(string(bytes))
+}
+
+func setDiffFlowStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style = yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		setDiffFlowStyle(child)
+	}
+}
+
+func diffChangesToText(changes []diffChange) string {
+	lines := make([]string, len(changes))
+	for i, change := range changes {
+		pathElements := make([]string, len(change.Path))
+		for j, element := range change.Path {
+			pathElements[j] = fmt.Sprintf("%v", element)
+		}
+		path := strings.Join(pathElements, ".")
+		if path == "" {
+			path = "."
+		}
+		switch change.Change {
+		case "added":
+			lines[i] = fmt.Sprintf("+ %v: %v", path, diffValueText(change.NewValue))
+		case "removed":
+			lines[i] = fmt.Sprintf("- %v: %v", path, diffValueText(change.OldValue))
+		case "changed":
+			lines[i] = fmt.Sprintf("~ %v: %v -> %v", path, diffValueText(change.OldValue), diffValueText(change.NewValue))
+		default:
+			lines[i] = fmt.Sprintf("# %v: %q -> %q", path, change.OldValue.Value, change.NewValue.Value)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,90 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var diffOperatorScenarios = []expressionScenario{
+	{
+		description:    "Diff two documents",
+		subdescription: "Returns a change set listing the added, removed and changed paths. Paths are given in the same format as the `path` operator.",
+		document:       "a: {b: 1, c: cat}\nd: [1, 2]\nother: {a: {b: 2}, d: [1, 2, 3], e: new}\n",
+		expression:     `.other as $new | del(.other) | diff($new)`,
+		expected: []string{
+			"D0, P[], (!!seq)::- path: [a, b]\n  change: changed\n  old: 1\n  new: 2\n- path: [a, c]\n  change: removed\n  old: cat\n- path: [d, 2]\n  change: added\n  new: 3\n- path: [e]\n  change: added\n  new: new\n",
+		},
+	},
+	{
+		description:    "Diff as a json patch",
+		subdescription: "Pass `format: patch` in the options to get an RFC 6902 json patch, that can be applied with the `patch` operator.",
+		document:       "a: {b: 1, c: cat}\nd: [1, 2, 3]\n",
+		expression:     `diff({"a": {"b": 2}, "d": [1]}; {"format": "patch"})`,
+		expected: []string{
+			"D0, P[], (!!seq)::- op: replace\n  path: /a/b\n  value: 2\n- op: remove\n  path: /a/c\n- op: remove\n  path: /d/2\n- op: remove\n  path: /d/1\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "a: {b: 1, c: cat}\nd: [1, 2, 3]\ne: [x, y]\n",
+		expression: `. as $old | {"a": {"b": 2, "x": [1]}, "d": [3, 1], "e": ["z"]} as $new | $old | patch(diff($new; {"format": "patch"})) | diff($new)`,
+		expected: []string{
+			"D0, P[], (!!seq)::[]\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "d: [1, 2, 3, 2]\n",
+		expression: `. as $old | {"d": [2, 4, 1, 2]} as $new | $old | patch(diff($new; {"format": "patch", "ignore_array_order": true})) | .d`,
+		expected: []string{
+			"D0, P[d], (!!seq)::[1, 4, 2, 2]\n",
+		},
+	},
+	{
+		description:    "Diff as text",
+		subdescription: "Pass `format: text` in the options for a human readable listing.",
+		document:       "name: app\nspec: {replicas: 1, image: nginx}\n",
+		expression:     `diff({"name": "app", "spec": {"replicas": 3, "ports": [80]}}; {"format": "text"})`,
+		expected: []string{
+			"D0, P[], (!!str)::~ spec.replicas: 1 -> 3\n- spec.image: nginx\n+ spec.ports: [80]\n",
+		},
+	},
+	{
+		description:    "Ignore array order",
+		subdescription: "Arrays are compared as bags of values, only values that are in one and not the other are reported.",
+		document:       "a: [1, 2, 3]\n",
+		expression:     `diff({"a": [3, 1, 4]}; {"ignore_array_order": true})`,
+		expected: []string{
+			"D0, P[], (!!seq)::- path: [a, 1]\n  change: removed\n  old: 2\n- path: [a, 2]\n  change: added\n  new: 4\n",
+		},
+	},
+	{
+		description:    "Comment changes",
+		subdescription: "Changes to comments are reported, unless `ignore_comments` is set.",
+		document:       "a: cat # meow\nb: dog\n",
+		expression:     `[diff({"a": "cat", "b": "dog"}), diff({"a": "cat", "b": "dog"}; {"ignore_comments": true})]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- - path: [a]\n    change: comment\n    old: '# meow'\n    new: \"\"\n- []\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "a: 1\n",
+		expression: `diff([1]; {"format": "text"})`,
+		expected: []string{
+			"D0, P[], (!!str)::~ .: {a: 1} -> [1]\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      "a: 1\n",
+		expression:    `diff(.; {"format": "unified"})`,
+		expectedError: "DIFF: unknown format 'unified', expected one of changes, patch or text",
+	},
+}
+
+func TestDiffOperatorScenarios(t *testing.T) {
+	for _, tt := range diffOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "diff", diffOperatorScenarios)
+}
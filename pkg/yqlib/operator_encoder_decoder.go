@@ -31,6 +31,30 @@ func configureEncoder(format PrinterOutputFormat, indent int) Encoder {
 		return NewUriEncoder()
 	case ShOutputFormat:
 		return NewShEncoder()
+	case CanonicalJSONOutputFormat:
+		return NewCanonicalJSONEncoder()
+	case Sha256OutputFormat:
+		return NewSha256Encoder()
+	case Sha1OutputFormat:
+		return NewSha1Encoder()
+	case Md5OutputFormat:
+		return NewMd5Encoder()
+	case Crc32OutputFormat:
+		return NewCrc32Encoder()
+	case Base32OutputFormat:
+		return NewBase32Encoder()
+	case HexOutputFormat:
+		return NewHexEncoder()
+	case GzipOutputFormat:
+		return NewGzipEncoder()
+	case HtmlOutputFormat:
+		return NewHtmlEncoder()
+	case JSONEscapeOutputFormat:
+		return NewJSONEscapeEncoder()
+	case TextOutputFormat:
+		return NewTextEncoder()
+	case JSONSchemaOutputFormat:
+		return NewJSONSchemaEncoder(indent, false)
 	}
 	panic("invalid encoder")
 }
@@ -90,6 +114,8 @@ func encodeOperator(d *dataTreeNavigator, context Context, expressionNode *Expre
 			preferences.format == CSVOutputFormat ||
 			preferences.format == TSVOutputFormat {
 			stringValue = chomper.ReplaceAllString(stringValue, "")
+		} else if isStringEncoding(preferences.format) {
+			stringValue = strings.TrimSuffix(stringValue, "\n")
 		}
 
 		stringContentNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: stringValue}
@@ -98,6 +124,14 @@ func encodeOperator(d *dataTreeNavigator, context Context, expressionNode *Expre
 	return context.ChildContext(results), nil
 }
 
+func isStringEncoding(format PrinterOutputFormat) bool {
+	switch format {
+	case Base32OutputFormat, HexOutputFormat, GzipOutputFormat, HtmlOutputFormat, JSONEscapeOutputFormat, TextOutputFormat:
+		return true
+	}
+	return false
+}
+
 type decoderPreferences struct {
 	format InputFormat
 }
@@ -121,6 +155,18 @@ func createDecoder(format InputFormat) Decoder {
 		decoder = NewCSVObjectDecoder('\t')
 	case UriInputFormat:
 		decoder = NewUriDecoder()
+	case Base32InputFormat:
+		decoder = NewBase32Decoder()
+	case HexInputFormat:
+		decoder = NewHexDecoder()
+	case GzipInputFormat:
+		decoder = NewGzipDecoder()
+	case HtmlInputFormat:
+		decoder = NewHtmlDecoder()
+	case JSONEscapeInputFormat:
+		decoder = NewJSONUnescapeDecoder()
+	case TextInputFormat:
+		decoder = NewTextDecoder()
 	}
 	return decoder
 }
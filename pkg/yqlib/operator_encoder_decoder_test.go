@@ -284,6 +284,136 @@ var encoderDecoderOperatorScenarios = []expressionScenario{
 		},
 		skipDoc: true,
 	},
+	{
+		description:    "Encode a value as canonical json",
+		subdescription: "Serialises as [RFC 8785](https://rfc-editor.org/rfc/rfc8785.html) canonical json: no whitespace, sorted keys and normalised numbers.",
+		document:       "b: 0x10\na: [1.50, 1e30, 'cat']\n",
+		expression:     `@canonical_json`,
+		expected: []string{
+			"D0, P[], (!!str)::{\"a\":[1.5,1e+30,\"cat\"],\"b\":16}\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		expression: `{"é": 1, "z": 0.0000001, "à": [true, null]} | to_canonical_json`,
+		expected: []string{
+			"D0, P[], (!!str)::{\"z\":1e-7,\"à\":[true,null],\"é\":1}\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      "a: .inf",
+		expression:    `@canonical_json`,
+		expectedError: "cannot encode .inf as canonical json, infinity and NaN are not valid json numbers",
+	},
+	{
+		description:    "Hash a string",
+		subdescription: "Strings are hashed as they are, and the hex digest is returned. `@sha256`, `@sha1`, `@md5` and `@crc32` are supported.",
+		document:       "password: cat",
+		expression:     `.password | [@sha256, @sha1, @md5, @crc32]`,
+		expected: []string{
+			"D0, P[password], (!!seq)::- 77af778b51abd4a3c51c5ddd97204a9c3ae614ebccb75a606c3b6865aed6744e\n- 9d989e8d27dc9e0ec3389fc855f142c3d40f0c50\n- d077f244def8a70e5ea758bd8352fcd8\n- 9e5e43a8\n",
+		},
+	},
+	{
+		description:    "Checksum a map",
+		subdescription: "Maps and arrays are hashed in their canonical json form, so the digest does not depend on key order or yaml style. Useful for rollout annotations on Kubernetes deployments.",
+		document:       "config:\n  b: 2\n  a: hello\nsameConfig: {\"a\": \"hello\", b: 0x2}\n",
+		expression:     `[.config, .sameConfig] | .[] |= @sha256`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 6426244abf46381e23c71216ce12bd29bcc395ef7fe08958fe90ae3ffdbc533b\n- 6426244abf46381e23c71216ce12bd29bcc395ef7fe08958fe90ae3ffdbc533b\n",
+		},
+	},
+	{
+		description: "Encode a string to base32 and hex",
+		document:    "secret: cat",
+		expression:  `.secret | [@base32, @hex]`,
+		expected: []string{
+			"D0, P[secret], (!!seq)::- MNQXI===\n- \"636174\"\n",
+		},
+	},
+	{
+		description: "Decode base32 and hex encoded strings",
+		document:    "a: MNQXI===\nb: \"636174\"",
+		expression:  `[.a | @base32d, .b | @hexd]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- cat\n- cat\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		expression: `"MNQXI" | @base32d`,
+		expected: []string{
+			"D0, P[], (!!str)::cat\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		expression:    `"zz" | @hexd`,
+		expectedError: "encoding/hex: invalid byte: U+007A 'z'",
+	},
+	{
+		description:    "Compress a string with gzip",
+		subdescription: "The compressed bytes are base64 encoded. Handy for keeping large values under size limits, like Kubernetes annotations.",
+		document:       "config: a long config string",
+		expression:     `.config |= @gzip | .config_length = (.config | length) | .config |= @gunzip`,
+		expected: []string{
+			"D0, P[], (doc)::config: a long config string\nconfig_length: 60\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		expression: `"" | @gunzip`,
+		expected: []string{
+			"D0, P[], (!!str)::\n",
+		},
+	},
+	{
+		description: "Escape html",
+		document:    `html: <b>"cats" & 'dogs'</b>`,
+		expression:  `.html | @html`,
+		expected: []string{
+			"D0, P[html], (!!str)::&lt;b&gt;&#34;cats&#34; &amp; &#39;dogs&#39;&lt;/b&gt;\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		expression: `"&lt;b&gt;&amp;&quot;&#39;" | @htmld`,
+		expected: []string{
+			"D0, P[], (!!str)::<b>&\"'\n",
+		},
+	},
+	{
+		description:    "Escape a string for json",
+		subdescription: "Escapes the string so it can be embedded in a json string. Use `@json_unescape` to go the other way.",
+		document:       "message: |\n  say \"hi\"\n",
+		expression:     `.message | @json_escape`,
+		expected: []string{
+			"D0, P[message], (!!str)::say \\\"hi\\\"\\n\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "message: |\n  say \"hi\"\n",
+		expression: `.message | @json-escape | @json-unescape`,
+		expected: []string{
+			"D0, P[message], (!!str)::say \"hi\"\n\n",
+		},
+	},
+	{
+		description:    "Convert to text",
+		subdescription: "Scalars are printed as they are, maps and arrays are printed as canonical json.",
+		document:       "a: {b: [1, cat]}\nc: 3",
+		expression:     `[.a, .c] | .[] |= @text`,
+		expected: []string{
+			"D0, P[], (!!seq)::- '{\"b\":[1,\"cat\"]}'\n- \"3\"\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		expression:    `5 | @hex`,
+		expectedError: "cannot encode !!int as hex, can only operate on strings. Please first pipe through another encoding operator to convert the value to a string",
+	},
 	{
 		description:    "Decode a base64 encoded string",
 		subdescription: "Decoded data is assumed to be a string.",
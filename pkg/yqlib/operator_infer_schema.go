@@ -0,0 +1,153 @@
+package yqlib
+
+import (
+	"container/list"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// strings with at most this many distinct values are inferred as an enum,
+// as long as some values were seen more than once.
+const maxInferredEnumValues = 5
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+var inferredTypeOrder = []string{"object", "array", "string", "integer", "number", "boolean", "null"}
+
+// schemaShape accumulates the shapes of the values seen at a path.
+type schemaShape struct {
+	types map[string]bool
+
+	objectCount    int
+	propertyKeys   []string
+	properties     map[string]*schemaShape
+	propertyCounts map[string]int
+
+	items *schemaShape
+
+	stringCount  int
+	stringValues []string
+}
+
+func newSchemaShape() *schemaShape {
+	return &schemaShape{types: map[string]bool{}, properties: map[string]*schemaShape{}, propertyCounts: map[string]int{}}
+}
+
+func (s *schemaShape) add(node *yaml.Node) {
+	node = resolveMergeAlias(unwrapDoc(node))
+	nodeType := jsonSchemaTypeOf(node)
+	s.types[nodeType] = true
+
+	switch nodeType {
+	case "object":
+		s.objectCount++
+		for index := 0; index < len(node.Content); index = index + 2 {
+			key := node.Content[index].Value
+			property, exists := s.properties[key]
+			if !exists {
+				property = newSchemaShape()
+				s.properties[key] = property
+				s.propertyKeys = append(s.propertyKeys, key)
+			}
+			s.propertyCounts[key]++
+			property.add(node.Content[index+1])
+		}
+	case "array":
+		for _, item := range node.Content {
+			if s.items == nil {
+				s.items = newSchemaShape()
+			}
+			s.items.add(item)
+		}
+	case "string":
+		s.stringCount++
+		if len(s.stringValues) <= maxInferredEnumValues && !containsString(s.stringValues, node.Value) {
+			s.stringValues = append(s.stringValues, node.Value)
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *schemaShape) toSchema() *yaml.Node {
+	schema := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	addKeyword := func(keyword string, value *yaml.Node) {
+		schema.Content = append(schema.Content, createStringScalarNode(keyword), value)
+	}
+
+	types := make([]*yaml.Node, 0)
+	for _, schemaType := range inferredTypeOrder {
+		// integers are numbers, so only list number if both were seen
+		if s.types[schemaType] && !(schemaType == "integer" && s.types["number"]) {
+			types = append(types, createStringScalarNode(schemaType))
+		}
+	}
+	if len(types) == 1 {
+		addKeyword("type", types[0])
+	} else if len(types) > 1 {
+		addKeyword("type", &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle, Content: types})
+	}
+
+	if len(s.propertyKeys) > 0 {
+		properties := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		required := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+		for _, key := range s.propertyKeys {
+			properties.Content = append(properties.Content, createStringScalarNode(key), s.properties[key].toSchema())
+			if s.propertyCounts[key] == s.objectCount {
+				required.Content = append(required.Content, createStringScalarNode(key))
+			}
+		}
+		addKeyword("properties", properties)
+		if len(required.Content) > 0 {
+			addKeyword("required", required)
+		}
+	}
+
+	if s.items != nil {
+		addKeyword("items", s.items.toSchema())
+	}
+
+	if len(types) == 1 && s.types["string"] && len(s.stringValues) <= maxInferredEnumValues && len(s.stringValues) < s.stringCount {
+		enum := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+		for _, value := range s.stringValues {
+			enum.Content = append(enum.Content, createStringScalarNode(value))
+		}
+		addKeyword("enum", enum)
+	}
+	return schema
+}
+
+// inferSchema returns a JSON schema that all the nodes match.
+func inferSchema(nodes ...*yaml.Node) *yaml.Node {
+	shape := newSchemaShape()
+	for _, node := range nodes {
+		shape.add(node)
+	}
+	schema := shape.toSchema()
+	schema.Content = append([]*yaml.Node{createStringScalarNode("$schema"), createStringScalarNode(jsonSchemaDialect)}, schema.Content...)
+	return schema
+}
+
+func inferSchemaOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- inferSchemaOperator")
+
+	if context.MatchingNodes.Len() == 0 {
+		return context, nil
+	}
+
+	nodes := make([]*yaml.Node, 0, context.MatchingNodes.Len())
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		nodes = append(nodes, el.Value.(*CandidateNode).Node)
+	}
+
+	results := list.New()
+	results.PushBack(context.MatchingNodes.Front().Value.(*CandidateNode).CreateReplacement(inferSchema(nodes...)))
+	return context.ChildContext(results), nil
+}
@@ -0,0 +1,58 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var inferSchemaOperatorScenarios = []expressionScenario{
+	{
+		description: "Infer a schema from a document",
+		document:    "name: web\nreplicas: 2\nports: [80, 443]\nlabels: {tier: frontend}\n",
+		expression:  `infer_schema`,
+		expected: []string{
+			"D0, P[], (!!map)::$schema: https://json-schema.org/draft/2020-12/schema\ntype: object\nproperties:\n    name:\n        type: string\n    replicas:\n        type: integer\n    ports:\n        type: array\n        items:\n            type: integer\n    labels:\n        type: object\n        properties:\n            tier:\n                type: string\n        required: [tier]\nrequired: [name, replicas, ports, labels]\n",
+		},
+	},
+	{
+		description:    "Infer a schema from several documents",
+		subdescription: "Types are merged, only the keys found in every document are required, and repeated strings become an enum.",
+		document:       "env: prod\nreplicas: 2\n",
+		document2:      "env: dev\nreplicas: 0.5\ndebug: true\n---\nenv: dev\nreplicas: null\n",
+		expression:     `infer_schema`,
+		expected: []string{
+			"D0, P[], (!!map)::$schema: https://json-schema.org/draft/2020-12/schema\ntype: object\nproperties:\n    env:\n        type: string\n        enum: [prod, dev]\n    replicas:\n        type: [number, \"null\"]\n    debug:\n        type: boolean\nrequired: [env, replicas]\n",
+		},
+	},
+	{
+		description:    "Infer the shape of array items",
+		subdescription: "The shapes of all the items are merged.",
+		document:       "- {name: a, tags: [x]}\n- {name: b}\n",
+		expression:     `infer_schema | .items`,
+		expected: []string{
+			"D0, P[items], (!!map)::type: object\nproperties:\n    name:\n        type: string\n    tags:\n        type: array\n        items:\n            type: string\nrequired: [name]\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "a: 1\n",
+		expression: `infer_schema as $schema | validate($schema)`,
+		expected: []string{
+			"D0, P[], (!!seq)::[]\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "[]\n",
+		expression: `infer_schema`,
+		expected: []string{
+			"D0, P[], (!!map)::$schema: https://json-schema.org/draft/2020-12/schema\ntype: array\n",
+		},
+	},
+}
+
+func TestInferSchemaOperatorScenarios(t *testing.T) {
+	for _, tt := range inferSchemaOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "infer-schema", inferSchemaOperatorScenarios)
+}
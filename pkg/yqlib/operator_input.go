@@ -0,0 +1,45 @@
+package yqlib
+
+import (
+	"container/list"
+	"errors"
+	"io"
+)
+
+// documentInputs reads the documents that are still to be evaluated, returning io.EOF when there are none left.
+type documentInputs interface {
+	nextInput() (*CandidateNode, error)
+}
+
+var errNoMoreInputs = errors.New("no more inputs")
+
+func inputOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("Input")
+	if d.inputs == nil {
+		return Context{}, errNoMoreInputs
+	}
+	candidate, err := d.inputs.nextInput()
+	if errors.Is(err, io.EOF) {
+		return Context{}, errNoMoreInputs
+	} else if err != nil {
+		return Context{}, err
+	}
+	return context.SingleChildContext(candidate), nil
+}
+
+func inputsOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("Inputs")
+	results := list.New()
+	if d.inputs == nil {
+		return context.ChildContext(results), nil
+	}
+	for {
+		candidate, err := d.inputs.nextInput()
+		if errors.Is(err, io.EOF) {
+			return context.ChildContext(results), nil
+		} else if err != nil {
+			return Context{}, err
+		}
+		results.PushBack(candidate)
+	}
+}
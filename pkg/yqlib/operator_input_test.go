@@ -0,0 +1,94 @@
+package yqlib
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+type inputScenario struct {
+	description   string
+	expression    string
+	files         []string
+	expected      string
+	expectedError string
+}
+
+var inputOperatorScenarios = []inputScenario{
+	{
+		description: "Pair each document with the next",
+		expression:  `{"this": .a, "next": (input | .a)}`,
+		files:       []string{"a: 1\n---\na: 2\n---\na: 3\n---\na: 4\n"},
+		expected:    "{\"this\":1,\"next\":2}\n{\"this\":3,\"next\":4}\n",
+	},
+	{
+		description: "Input continues into the next file",
+		expression:  `[.a, (input | .a)]`,
+		files:       []string{"a: 1\n", "a: 2\n"},
+		expected:    "[1,2]\n",
+	},
+	{
+		description: "Reduce all the documents",
+		expression:  `(., inputs) as $doc ireduce (0; . + $doc.a)`,
+		files:       []string{"a: 1\n---\na: 2\n", "a: 3\n"},
+		expected:    "6\n",
+	},
+	{
+		description: "Inputs is empty after the last document",
+		expression:  `[inputs]`,
+		files:       []string{"a: 1\n"},
+		expected:    "[]\n",
+	},
+	{
+		description:   "Input after the last document",
+		expression:    `input`,
+		files:         []string{"a: 1\n"},
+		expectedError: "no more inputs",
+	},
+}
+
+func testInputScenario(t *testing.T, s inputScenario) {
+	var filenames []string
+	for _, content := range s.files {
+		filename := createTestFile(content)
+		defer tryRemoveTempFile(filename)
+		filenames = append(filenames, filename)
+	}
+
+	var output bytes.Buffer
+	writer := bufio.NewWriter(&output)
+	printer := NewPrinter(NewJSONEncoder(0, false, false), NewSinglePrinterWriter(writer))
+	err := NewStreamEvaluator().EvaluateFiles(s.expression, filenames, printer, NewYamlDecoder(ConfiguredYamlPreferences))
+	if s.expectedError != "" {
+		if err == nil {
+			t.Fatalf("%v: expected error '%v'", s.description, s.expectedError)
+		}
+		test.AssertResultWithContext(t, s.expectedError, err.Error(), s.description)
+		return
+	} else if err != nil {
+		t.Fatalf("%v: %v", s.description, err)
+	}
+	writer.Flush()
+	test.AssertResultWithContext(t, s.expected, output.String(), s.description)
+}
+
+func TestInputOperatorScenarios(t *testing.T) {
+	InitExpressionParser()
+	for _, s := range inputOperatorScenarios {
+		testInputScenario(t, s)
+	}
+}
+
+func TestInputsWhenEvaluatingAll(t *testing.T) {
+	node, err := parseSnippet("a: 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := NewAllAtOnceEvaluator().EvaluateNodes(`[inputs] | length`, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResult(t, "0", results.Front().Value.(*CandidateNode).Node.Value)
+}
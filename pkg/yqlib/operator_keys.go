@@ -83,3 +83,66 @@ func getIndicies(node *yaml.Node) *yaml.Node {
 
 	return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: contents}
 }
+
+func renameKeysOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- renameKeysOperator")
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		targetNode := deepClone(unwrapDoc(candidate.Node))
+
+		err := renameKeys(d, context, candidate, targetNode, expressionNode.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+
+		results.PushBack(candidate.CreateReplacementWithDocWrappers(targetNode))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+// renameKeys recursively runs the given expression against every string
+// map key under node, updating the key values in place. Map values,
+// comments and key order are left untouched. Renaming two keys of a map to
+// the same name is an error.
+func renameKeys(d *dataTreeNavigator, context Context, candidate *CandidateNode, node *yaml.Node, renameExp *ExpressionNode) error {
+	if node.Kind == yaml.MappingNode {
+		renamedKeys := make(map[string]string, len(node.Content)/2)
+		for index := 0; index < len(node.Content); index = index + 2 {
+			keyNode := node.Content[index]
+			if guessTagFromCustomType(keyNode) != "!!str" {
+				renamedKeys[keyNode.Value] = keyNode.Value
+				continue
+			}
+			keyCandidate := candidate.CreateReplacement(keyNode)
+			keyCandidate.IsMapKey = true
+
+			renamed, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(keyCandidate), renameExp)
+			if err != nil {
+				return err
+			}
+			if renamed.MatchingNodes.Front() == nil {
+				return fmt.Errorf("rename_keys expression returned no result for key '%v'", keyNode.Value)
+			}
+			renamedNode := unwrapDoc(renamed.MatchingNodes.Front().Value.(*CandidateNode).Node)
+			if renamedNode.Kind != yaml.ScalarNode {
+				return fmt.Errorf("rename_keys expression must return a scalar for key '%v', got %v", keyNode.Value, renamedNode.Tag)
+			}
+			if original, exists := renamedKeys[renamedNode.Value]; exists {
+				return fmt.Errorf("rename_keys renamed both '%v' and '%v' to '%v'", original, keyNode.Value, renamedNode.Value)
+			}
+			renamedKeys[renamedNode.Value] = keyNode.Value
+			keyNode.Value = renamedNode.Value
+		}
+	}
+
+	for _, child := range node.Content {
+		if err := renameKeys(d, context, candidate, child, renameExp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
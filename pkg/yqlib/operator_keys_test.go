@@ -109,6 +109,74 @@ var keysOperatorScenarios = []expressionScenario{
 			expectedIsKey,
 		},
 	},
+	{
+		description:    "Rename keys recursively",
+		subdescription: "Runs the expression against every map key in the tree, keeping values, comments and key order.",
+		document:       "replicaCount: 1 # how many\nimagePullSecrets:\n  - secretName: regcred\nserviceAccount:\n  createToken: true\n",
+		expression:     `rename_keys(snake_case | upcase)`,
+		expected: []string{
+			"D0, P[], (!!map)::REPLICA_COUNT: 1 # how many\nIMAGE_PULL_SECRETS:\n    - SECRET_NAME: regcred\nSERVICE_ACCOUNT:\n    CREATE_TOKEN: true\n",
+		},
+	},
+	{
+		description:    "Rename keys with an expression",
+		subdescription: "`with_keys` is an alias of `rename_keys`.",
+		document:       "a:\n  b: 1\n",
+		expression:     `with_keys("x_" + .)`,
+		expected: []string{
+			"D0, P[], (!!map)::x_a:\n    x_b: 1\n",
+		},
+	},
+	{
+		skipDoc:     true,
+		description: "rename keys only updates string keys",
+		document:    "1: {fooBar: 2}\n",
+		expression:  `rename_keys(kebab_case)`,
+		expected: []string{
+			"D0, P[], (!!map)::1: {foo-bar: 2}\n",
+		},
+	},
+	{
+		skipDoc:     true,
+		description: "rename keys works with update",
+		document:    "a: {fooBar: 2}\nsomeThing: {fooBar: 3}\n",
+		expression:  `.a |= rename_keys(snake_case)`,
+		expected: []string{
+			"D0, P[], (doc)::a: {foo_bar: 2}\nsomeThing: {fooBar: 3}\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		description:   "rename keys to the same name",
+		document:      "{a_b: 1, aB: 2}\n",
+		expression:    `rename_keys(camel_case)`,
+		expectedError: "rename_keys renamed both 'a_b' and 'aB' to 'aB'",
+	},
+	{
+		skipDoc:     true,
+		description: "rename keys can swap keys",
+		document:    "{a: 1, b: 2}\n",
+		expression:  `renameKeys(sub("^a$"; "x") | sub("^b$"; "a") | sub("^x$"; "b"))`,
+		expected: []string{
+			"D0, P[], (!!map)::{b: 1, a: 2}\n",
+		},
+	},
+	{
+		skipDoc:     true,
+		description: "withKeys alias",
+		document:    "{a: 1}\n",
+		expression:  `withKeys(upcase)`,
+		expected: []string{
+			"D0, P[], (!!map)::{A: 1}\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		description:   "rename keys must return scalars",
+		document:      "a: 1\n",
+		expression:    `rename_keys([.])`,
+		expectedError: "rename_keys expression must return a scalar for key 'a', got !!seq",
+	},
 }
 
 func TestKeysOperatorScenarios(t *testing.T) {
@@ -0,0 +1,362 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+type patchPreferences struct {
+	MergePatch bool
+}
+
+// patchOperator applies an RFC 6902 json patch, or an RFC 7386 json merge patch,
+// to a copy of each matching node. Patches are atomic - if any operation fails
+// (including a 'test' operation) the whole patch fails.
+func patchOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- patchOperator")
+	prefs := expressionNode.Operation.Preferences.(patchPreferences)
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+
+		patchContext, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), expressionNode.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+		if patchContext.MatchingNodes.Len() != 1 {
+			return Context{}, fmt.Errorf("%v: expected a single patch document but found %v", expressionNode.Operation.OperationType.Type, patchContext.MatchingNodes.Len())
+		}
+		patchNode := unwrapDoc(patchContext.MatchingNodes.Front().Value.(*CandidateNode).Node)
+
+		var patched *yaml.Node
+		if prefs.MergePatch {
+			patched = applyMergePatch(deepClone(unwrapDoc(candidate.Node)), patchNode)
+		} else {
+			patched, err = applyJSONPatch(deepClone(unwrapDoc(candidate.Node)), patchNode)
+			if err != nil {
+				return Context{}, err
+			}
+		}
+
+		results.PushBack(candidate.CreateReplacementWithDocWrappers(patched))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+// applyMergePatch implements the MergePatch function from RFC 7386.
+func applyMergePatch(target *yaml.Node, patch *yaml.Node) *yaml.Node {
+	if patch.Kind == yaml.AliasNode {
+		patch = patch.Alias
+	}
+	if patch.Kind != yaml.MappingNode {
+		return deepClone(patch)
+	}
+	if target == nil || target.Kind != yaml.MappingNode {
+		target = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	for index := 0; index < len(patch.Content); index = index + 2 {
+		key := patch.Content[index].Value
+		value := patch.Content[index+1]
+		existing := findJSONPatchKey(target, key)
+		if value.Tag == "!!null" {
+			if existing != -1 {
+				target.Content = append(target.Content[:existing], target.Content[existing+2:]...)
+			}
+		} else if existing != -1 {
+			merged := applyMergePatch(target.Content[existing+1], value)
+			keepJSONPatchComments(target.Content[existing+1], merged)
+			target.Content[existing+1] = merged
+		} else {
+			keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+			target.Content = append(target.Content, keyNode, applyMergePatch(nil, value))
+		}
+	}
+	return target
+}
+
+// applyJSONPatch applies the RFC 6902 operations in patch to root, returning the new root.
+func applyJSONPatch(root *yaml.Node, patch *yaml.Node) (*yaml.Node, error) {
+	if patch.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("json patch must be an array of operations, got %v", patch.Tag)
+	}
+	for index, operation := range patch.Content {
+		var err error
+		root, err = applyJSONPatchOperation(root, operation)
+		if err != nil {
+			return nil, fmt.Errorf("json patch operation [%v] failed: %w", index, err)
+		}
+	}
+	return root, nil
+}
+
+func getJSONPatchMember(operation *yaml.Node, name string, required bool) (*yaml.Node, error) {
+	index := findJSONPatchKey(operation, name)
+	if index == -1 {
+		if required {
+			return nil, fmt.Errorf("missing '%v' member", name)
+		}
+		return nil, nil
+	}
+	return operation.Content[index+1], nil
+}
+
+func applyJSONPatchOperation(root *yaml.Node, operation *yaml.Node) (*yaml.Node, error) {
+	if operation.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected an operation object, got %v", operation.Tag)
+	}
+	opNode, err := getJSONPatchMember(operation, "op", true)
+	if err != nil {
+		return nil, err
+	}
+	pathNode, err := getJSONPatchMember(operation, "path", true)
+	if err != nil {
+		return nil, err
+	}
+	path, err := parseJSONPointer(pathNode.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opNode.Value {
+	case "add", "replace", "test":
+		valueNode, err := getJSONPatchMember(operation, "value", true)
+		if err != nil {
+			return nil, err
+		}
+		switch opNode.Value {
+		case "add":
+			return jsonPatchAdd(root, path, deepClone(valueNode))
+		case "replace":
+			return jsonPatchReplace(root, path, deepClone(valueNode))
+		default:
+			existing, err := jsonPointerGet(root, path)
+			if err != nil {
+				return nil, err
+			}
+			if !jsonValuesEqual(existing, valueNode) {
+				return nil, fmt.Errorf("test operation failed, value at '%v' does not equal the expected value", pathNode.Value)
+			}
+			return root, nil
+		}
+	case "remove":
+		root, _, err = jsonPatchRemove(root, path)
+		return root, err
+	case "move", "copy":
+		fromNode, err := getJSONPatchMember(operation, "from", true)
+		if err != nil {
+			return nil, err
+		}
+		from, err := parseJSONPointer(fromNode.Value)
+		if err != nil {
+			return nil, err
+		}
+		if opNode.Value == "copy" {
+			value, err := jsonPointerGet(root, from)
+			if err != nil {
+				return nil, err
+			}
+			return jsonPatchAdd(root, path, deepClone(value))
+		}
+		if fromNode.Value == pathNode.Value {
+			return root, nil
+		}
+		if strings.HasPrefix(pathNode.Value, fromNode.Value+"/") {
+			return nil, fmt.Errorf("cannot move '%v' into one of its children '%v'", fromNode.Value, pathNode.Value)
+		}
+		root, value, err := jsonPatchRemove(root, from)
+		if err != nil {
+			return nil, err
+		}
+		return jsonPatchAdd(root, path, value)
+	}
+	return nil, fmt.Errorf("unknown op '%v', expected one of add, remove, replace, move, copy or test", opNode.Value)
+}
+
+// parseJSONPointer splits an RFC 6901 json pointer into its unescaped reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid json pointer '%v', must be empty or start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func findJSONPatchKey(node *yaml.Node, key string) int {
+	for index := 0; index < len(node.Content); index = index + 2 {
+		if node.Content[index].Value == key {
+			return index
+		}
+	}
+	return -1
+}
+
+// parseJSONPointerIndex parses an array index token, which must not have leading zeros.
+func parseJSONPointerIndex(token string, length int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index '%v'", token)
+	}
+	if index >= length {
+		return 0, fmt.Errorf("array index %v is out of bounds", index)
+	}
+	return index, nil
+}
+
+func jsonPointerGet(root *yaml.Node, path []string) (*yaml.Node, error) {
+	current := root
+	for i, token := range path {
+		if current.Kind == yaml.AliasNode {
+			current = current.Alias
+		}
+		switch current.Kind {
+		case yaml.MappingNode:
+			index := findJSONPatchKey(current, token)
+			if index == -1 {
+				return nil, fmt.Errorf("path '%v' does not exist", jsonPointerString(path[:i+1]))
+			}
+			current = current.Content[index+1]
+		case yaml.SequenceNode:
+			index, err := parseJSONPointerIndex(token, len(current.Content))
+			if err != nil {
+				return nil, fmt.Errorf("path '%v' does not exist: %w", jsonPointerString(path[:i+1]), err)
+			}
+			current = current.Content[index]
+		default:
+			return nil, fmt.Errorf("path '%v' does not exist, cannot traverse into %v", jsonPointerString(path[:i+1]), current.Tag)
+		}
+	}
+	return current, nil
+}
+
+func jsonPatchAdd(root *yaml.Node, path []string, value *yaml.Node) (*yaml.Node, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := jsonPointerGet(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch parent.Kind {
+	case yaml.MappingNode:
+		index := findJSONPatchKey(parent, token)
+		if index != -1 {
+			keepJSONPatchComments(parent.Content[index+1], value)
+			parent.Content[index+1] = value
+		} else {
+			keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: token}
+			parent.Content = append(parent.Content, keyNode, value)
+		}
+	case yaml.SequenceNode:
+		index := len(parent.Content)
+		if token != "-" {
+			index, err = parseJSONPointerIndex(token, len(parent.Content)+1)
+			if err != nil {
+				return nil, err
+			}
+		}
+		parent.Content = append(parent.Content[:index], append([]*yaml.Node{value}, parent.Content[index:]...)...)
+	default:
+		return nil, fmt.Errorf("cannot add '%v' to %v", jsonPointerString(path), parent.Tag)
+	}
+	return root, nil
+}
+
+// jsonPatchReplace replaces an existing value, keeping its position in the parent map.
+func jsonPatchReplace(root *yaml.Node, path []string, value *yaml.Node) (*yaml.Node, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	if _, err := jsonPointerGet(root, path); err != nil {
+		return nil, err
+	}
+	parent, err := jsonPointerGet(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	index := 0
+	if parent.Kind == yaml.MappingNode {
+		index = findJSONPatchKey(parent, token) + 1
+	} else {
+		index, err = parseJSONPointerIndex(token, len(parent.Content))
+		if err != nil {
+			return nil, err
+		}
+	}
+	keepJSONPatchComments(parent.Content[index], value)
+	parent.Content[index] = value
+	return root, nil
+}
+
+// keepJSONPatchComments copies the comments of a replaced node onto its replacement.
+func keepJSONPatchComments(original *yaml.Node, replacement *yaml.Node) {
+	if replacement.HeadComment == "" && replacement.LineComment == "" && replacement.FootComment == "" {
+		replacement.HeadComment = original.HeadComment
+		replacement.LineComment = original.LineComment
+		replacement.FootComment = original.FootComment
+	}
+}
+
+// jsonPatchRemove removes the value at path, returning the new root and the removed value.
+func jsonPatchRemove(root *yaml.Node, path []string) (*yaml.Node, *yaml.Node, error) {
+	if len(path) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}, root, nil
+	}
+	parent, err := jsonPointerGet(root, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	token := path[len(path)-1]
+	var removed *yaml.Node
+	switch parent.Kind {
+	case yaml.MappingNode:
+		index := findJSONPatchKey(parent, token)
+		if index == -1 {
+			return nil, nil, fmt.Errorf("path '%v' does not exist", jsonPointerString(path))
+		}
+		removed = parent.Content[index+1]
+		parent.Content = append(parent.Content[:index], parent.Content[index+2:]...)
+	case yaml.SequenceNode:
+		index, err := parseJSONPointerIndex(token, len(parent.Content))
+		if err != nil {
+			return nil, nil, fmt.Errorf("path '%v' does not exist: %w", jsonPointerString(path), err)
+		}
+		removed = parent.Content[index]
+		parent.Content = append(parent.Content[:index], parent.Content[index+1:]...)
+	default:
+		return nil, nil, fmt.Errorf("path '%v' does not exist, cannot traverse into %v", jsonPointerString(path), parent.Tag)
+	}
+	return root, removed, nil
+}
+
+func jsonPointerString(path []string) string {
+	var builder strings.Builder
+	for _, token := range path {
+		builder.WriteString("/")
+		builder.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return builder.String()
+}
+
+// jsonValuesEqual compares two nodes as json values, ignoring key order and yaml styling.
+func jsonValuesEqual(lhs *yaml.Node, rhs *yaml.Node) bool {
+	var lhsJSON, rhsJSON strings.Builder
+	if writeCanonicalJSON(&lhsJSON, lhs) != nil || writeCanonicalJSON(&rhsJSON, rhs) != nil {
+		return recursiveNodeEqual(lhs, rhs)
+	}
+	return lhsJSON.String() == rhsJSON.String()
+}
@@ -0,0 +1,110 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var patchOperatorScenarios = []expressionScenario{
+	{
+		description:    "Apply a json patch",
+		subdescription: "Applies an [RFC 6902](https://rfc-editor.org/rfc/rfc6902.html) json patch. Paths are json pointers, and all of add, remove, replace, move, copy and test are supported.",
+		document:       "spec:\n  replicas: 1\n  image: nginx:1.24\n  ports: [80]\n",
+		expression:     `patch([{"op": "replace", "path": "/spec/replicas", "value": 3}, {"op": "add", "path": "/spec/ports/-", "value": 443}, {"op": "move", "from": "/spec/image", "path": "/image"}])`,
+		expected: []string{
+			"D0, P[], (!!map)::spec:\n    replicas: 3\n    ports: [80, 443]\nimage: nginx:1.24\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "a: cat\n",
+		expression: `patch(load("../../examples/patch.json"))`,
+		expected: []string{
+			"D0, P[], (!!map)::a: \"dog\"\nb: [1, 2]\n",
+		},
+	},
+	{
+		description:    "Failed test operations",
+		subdescription: "If any operation fails, including a `test`, the whole patch fails and nothing is changed.",
+		document:       "version: 1\n",
+		expression:     `patch([{"op": "replace", "path": "/version", "value": 2}, {"op": "test", "path": "/version", "value": 1}])`,
+		expectedError:  "json patch operation [1] failed: test operation failed, value at '/version' does not equal the expected value",
+	},
+	{
+		skipDoc:    true,
+		document:   "a: {b: 1.0, c: [x]}\n",
+		expression: `patch([{"op": "test", "path": "/a", "value": {"c": ["x"], "b": 1}}, {"op": "copy", "from": "/a/c", "path": "/d"}, {"op": "add", "path": "/d/0", "value": "y"}, {"op": "remove", "path": "/a/b"}])`,
+		expected: []string{
+			"D0, P[], (!!map)::a: {c: [x]}\nd: [y, x]\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "a~b: {c/d: 1}\nz: 2\n",
+		expression: `patch([{"op": "replace", "path": "/a~0b/c~1d", "value": 3}, {"op": "replace", "path": "/a~0b", "value": 4}])`,
+		expected: []string{
+			"D0, P[], (!!map)::a~b: 4\nz: 2\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "a: 1\n",
+		expression: `patch([{"op": "replace", "path": "", "value": [1]}])`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 1\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      "a: [1]\n",
+		expression:    `patch([{"op": "add", "path": "/a/01", "value": 2}])`,
+		expectedError: "json patch operation [0] failed: invalid array index '01'",
+	},
+	{
+		skipDoc:       true,
+		document:      "a: [1]\n",
+		expression:    `patch([{"op": "remove", "path": "/b"}])`,
+		expectedError: "json patch operation [0] failed: path '/b' does not exist",
+	},
+	{
+		skipDoc:       true,
+		document:      "a: {b: 1}\n",
+		expression:    `patch([{"op": "move", "from": "/a", "path": "/a/b/c"}])`,
+		expectedError: "json patch operation [0] failed: cannot move '/a' into one of its children '/a/b/c'",
+	},
+	{
+		skipDoc:       true,
+		document:      "a: 1\n",
+		expression:    `patch([{"op": "frob", "path": "/a"}])`,
+		expectedError: "json patch operation [0] failed: unknown op 'frob', expected one of add, remove, replace, move, copy or test",
+	},
+	{
+		skipDoc:       true,
+		document:      "a: 1\n",
+		expression:    `patch({"op": "remove", "path": "/a"})`,
+		expectedError: "json patch must be an array of operations, got !!map",
+	},
+	{
+		description:    "Apply a json merge patch",
+		subdescription: "Applies an [RFC 7386](https://rfc-editor.org/rfc/rfc7386.html) merge patch. Maps are merged recursively, null values remove keys and everything else is replaced.",
+		document:       "a: cat\nb: {c: 1, d: 2}\ne: [1, 2]\n",
+		expression:     `merge_patch({"a": "dog", "b": {"c": null, "f": 3}, "e": [3]})`,
+		expected: []string{
+			"D0, P[], (!!map)::a: dog\nb: {d: 2, f: 3}\ne:\n    - 3\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "a: cat\n",
+		expression: `.a |= merge_patch({"b": {"c": null, "d": 1}})`,
+		expected: []string{
+			"D0, P[], (doc)::a:\n    b:\n        d: 1\n",
+		},
+	},
+}
+
+func TestPatchOperatorScenarios(t *testing.T) {
+	for _, tt := range patchOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "patch", patchOperatorScenarios)
+}
@@ -0,0 +1,36 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+)
+
+func renderOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- renderOperator")
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+
+		templateContext, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), expressionNode.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+
+		for templateEl := templateContext.MatchingNodes.Front(); templateEl != nil; templateEl = templateEl.Next() {
+			templateNode := unwrapDoc(templateEl.Value.(*CandidateNode).Node)
+			if templateNode.Tag != "!!str" {
+				return Context{}, fmt.Errorf("cannot render a template from %v, the template must be a string", templateNode.Tag)
+			}
+			var output strings.Builder
+			if err := renderTemplate("render", templateNode.Value, candidate, &output); err != nil {
+				return Context{}, err
+			}
+			results.PushBack(candidate.CreateReplacement(createStringScalarNode(output.String())))
+		}
+	}
+
+	return context.ChildContext(results), nil
+}
@@ -0,0 +1,47 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var renderOperatorScenarios = []expressionScenario{
+	{
+		description: "Render a template",
+		document:    "name: web\nports: [80, 443]\n",
+		expression:  `render("{{ .name }} listens on{{ range .ports }} {{ . }}{{ end }}")`,
+		expected: []string{
+			"D0, P[], (!!str)::web listens on 80 443\n",
+		},
+	},
+	{
+		description:    "Evaluate yq expressions in a template",
+		subdescription: "The `yq` function evaluates an expression against the data, or against the value given as its second parameter.",
+		document:       "servers: [{name: a, port: 80}, {name: b, port: 8080}]\n",
+		expression:     `render("{{ yq \".servers | length\" }} servers:{{ range .servers }} {{ yq \".name\" . }}{{ end }}")`,
+		expected: []string{
+			"D0, P[], (!!str)::2 servers: a b\n",
+		},
+	},
+	{
+		skipDoc:     true,
+		description: "merge anchors are applied",
+		document:    "base: &base {a: 1, b: 2}\nthing: {<<: *base, b: 3}\n",
+		expression:  `render("{{ .thing.a }} {{ .thing.b }}")`,
+		expected: []string{
+			"D0, P[], (!!str)::1 3\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      "a: 1\n",
+		expression:    `render("{{ .a ")`,
+		expectedError: `template: render:1: unclosed action`,
+	},
+}
+
+func TestRenderOperatorScenarios(t *testing.T) {
+	for _, tt := range renderOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "render", renderOperatorScenarios)
+}
@@ -0,0 +1,471 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// nodes tagged with this are compared (and sorted) using semantic version ordering
+const semverTag = "!semver"
+
+type semVersion struct {
+	Prefix     string
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+	Build      string
+}
+
+// partial versions are used in constraints, where "1.2", "1.2.x" and "*" are valid
+type partialSemVersion struct {
+	semVersion
+	// the number of major.minor.patch parts given, wildcards are not counted
+	Parts int
+}
+
+var semverRegex = regexp.MustCompile(`^([vV]?)(0|[1-9]\d*|[xX*])(?:\.(0|[1-9]\d*|[xX*]))?(?:\.(0|[1-9]\d*|[xX*]))?(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
+
+func parsePartialSemVersion(value string) (partialSemVersion, error) {
+	match := semverRegex.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return partialSemVersion{}, fmt.Errorf("could not parse '%v' as a semantic version", value)
+	}
+	result := partialSemVersion{semVersion: semVersion{Prefix: match[1], Build: match[6]}}
+	if match[5] != "" {
+		result.Prerelease = strings.Split(match[5], ".")
+	}
+
+	numbers := []*uint64{&result.Major, &result.Minor, &result.Patch}
+	for i, part := range match[2:5] {
+		if part == "" || part == "x" || part == "X" || part == "*" {
+			break
+		}
+		number, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return partialSemVersion{}, fmt.Errorf("could not parse '%v' as a semantic version: %w", value, err)
+		}
+		*numbers[i] = number
+		result.Parts = i + 1
+	}
+	return result, nil
+}
+
+// parseSemVersion parses a version, missing minor and patch parts default to 0
+// so that loose versions like "v1.2" can be used.
+func parseSemVersion(value string) (semVersion, error) {
+	partial, err := parsePartialSemVersion(value)
+	if err != nil {
+		return semVersion{}, err
+	}
+	if partial.Parts == 0 {
+		return semVersion{}, fmt.Errorf("could not parse '%v' as a semantic version", value)
+	}
+	return partial.semVersion, nil
+}
+
+func (v semVersion) String() string {
+	result := fmt.Sprintf("%v%v.%v.%v", v.Prefix, v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		result = result + "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		result = result + "+" + v.Build
+	}
+	return result
+}
+
+func compareUint(lhs uint64, rhs uint64) int {
+	if lhs < rhs {
+		return -1
+	} else if lhs > rhs {
+		return 1
+	}
+	return 0
+}
+
+func comparePrereleaseIdentifier(lhs string, rhs string) int {
+	lhsNum, lhsErr := strconv.ParseUint(lhs, 10, 64)
+	rhsNum, rhsErr := strconv.ParseUint(rhs, 10, 64)
+	if lhsErr == nil && rhsErr == nil {
+		return compareUint(lhsNum, rhsNum)
+	} else if lhsErr == nil {
+		// numeric identifiers have lower precedence
+		return -1
+	} else if rhsErr == nil {
+		return 1
+	}
+	return strings.Compare(lhs, rhs)
+}
+
+// compare follows the semver 2.0 precedence rules, build metadata is ignored.
+func (v semVersion) compare(other semVersion) int {
+	if result := compareUint(v.Major, other.Major); result != 0 {
+		return result
+	}
+	if result := compareUint(v.Minor, other.Minor); result != 0 {
+		return result
+	}
+	if result := compareUint(v.Patch, other.Patch); result != 0 {
+		return result
+	}
+
+	// a version without a prerelease has higher precedence
+	if len(v.Prerelease) == 0 && len(other.Prerelease) == 0 {
+		return 0
+	} else if len(v.Prerelease) == 0 {
+		return 1
+	} else if len(other.Prerelease) == 0 {
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if result := comparePrereleaseIdentifier(v.Prerelease[i], other.Prerelease[i]); result != 0 {
+			return result
+		}
+	}
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(other.Prerelease)))
+}
+
+func (v semVersion) bump(part string) (semVersion, error) {
+	bumped := semVersion{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	isPrerelease := len(v.Prerelease) > 0
+
+	switch part {
+	case "major":
+		// 2.0.0-rc.1 bumps to 2.0.0
+		if !isPrerelease || v.Minor != 0 || v.Patch != 0 {
+			bumped.Major++
+			bumped.Minor = 0
+			bumped.Patch = 0
+		}
+	case "minor":
+		if !isPrerelease || v.Patch != 0 {
+			bumped.Minor++
+			bumped.Patch = 0
+		}
+	case "patch":
+		if !isPrerelease {
+			bumped.Patch++
+		}
+	case "prerelease":
+		if !isPrerelease {
+			bumped.Patch++
+			bumped.Prerelease = []string{"0"}
+			return bumped, nil
+		}
+		bumped.Prerelease = append([]string{}, v.Prerelease...)
+		last := len(bumped.Prerelease) - 1
+		if number, err := strconv.ParseUint(bumped.Prerelease[last], 10, 64); err == nil {
+			bumped.Prerelease[last] = fmt.Sprintf("%v", number+1)
+		} else {
+			bumped.Prerelease = append(bumped.Prerelease, "1")
+		}
+	default:
+		return v, fmt.Errorf("unknown semver part '%v', expected one of major, minor, patch or prerelease", part)
+	}
+	return bumped, nil
+}
+
+type semverComparator struct {
+	Operator string
+	Version  semVersion
+}
+
+func (c semverComparator) matches(version semVersion) bool {
+	result := version.compare(c.Version)
+	switch c.Operator {
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	}
+	return result == 0
+}
+
+// upperBound returns the lowest version that is above every version
+// matching the given partial version, e.g. 1.2 gives 1.3.0-0
+func upperBound(partial partialSemVersion, parts int) semVersion {
+	bound := semVersion{Prerelease: []string{"0"}}
+	switch parts {
+	case 1:
+		bound.Major = partial.Major + 1
+	case 2:
+		bound.Major = partial.Major
+		bound.Minor = partial.Minor + 1
+	default:
+		bound.Major = partial.Major
+		bound.Minor = partial.Minor
+		bound.Patch = partial.Patch + 1
+	}
+	return bound
+}
+
+func xRangeComparators(partial partialSemVersion) []semverComparator {
+	if partial.Parts == 3 {
+		return []semverComparator{{"=", partial.semVersion}}
+	}
+	return []semverComparator{
+		{">=", partial.semVersion},
+		{"<", upperBound(partial, partial.Parts)},
+	}
+}
+
+var semverComparatorRegex = regexp.MustCompile(`^(>=|<=|>|<|=|~>|~|\^)?(.*)$`)
+
+func parseSemverComparator(value string) ([]semverComparator, error) {
+	match := semverComparatorRegex.FindStringSubmatch(value)
+	operator := match[1]
+	partial, err := parsePartialSemVersion(match[2])
+	if err != nil {
+		return nil, err
+	}
+	lower := partial.semVersion
+	if partial.Parts == 0 {
+		// wildcards match everything
+		return []semverComparator{}, nil
+	}
+
+	switch operator {
+	case "", "=":
+		return xRangeComparators(partial), nil
+	case "~", "~>":
+		if partial.Parts <= 2 {
+			return xRangeComparators(partial), nil
+		}
+		return []semverComparator{{">=", lower}, {"<", upperBound(partial, 2)}}, nil
+	case "^":
+		// the upper bound is set by the left most non zero part
+		parts := 1
+		if partial.Major == 0 && partial.Parts >= 2 {
+			parts = 2
+			if partial.Minor == 0 && partial.Parts == 3 {
+				parts = 3
+			}
+		}
+		return []semverComparator{{">=", lower}, {"<", upperBound(partial, parts)}}, nil
+	case ">":
+		if partial.Parts < 3 {
+			return []semverComparator{{">=", upperBound(partial, partial.Parts)}}, nil
+		}
+	case "<=":
+		if partial.Parts < 3 {
+			return []semverComparator{{"<", upperBound(partial, partial.Parts)}}, nil
+		}
+	case "<":
+		if partial.Parts < 3 {
+			lower.Prerelease = []string{"0"}
+		}
+	}
+	return []semverComparator{{operator, lower}}, nil
+}
+
+var semverHyphenRangeRegex = regexp.MustCompile(`^\s*(\S+)\s+-\s+(\S+)\s*$`)
+var semverOperatorSpaceRegex = regexp.MustCompile(`(>=|<=|>|<|=|~>|~|\^)\s+`)
+
+// parseSemverConstraint parses npm style constraints (e.g. "^1.2", ">=1.0 <2.0",
+// "1.2.x || 2.0.0 - 2.3") into a list of alternatives, each of which is a list of
+// comparators that all need to match.
+func parseSemverConstraint(constraint string) ([][]semverComparator, error) {
+	alternatives := make([][]semverComparator, 0)
+	for _, alternative := range strings.Split(constraint, "||") {
+		comparators := make([]semverComparator, 0)
+
+		if match := semverHyphenRangeRegex.FindStringSubmatch(alternative); match != nil {
+			lower, err := parseSemverComparator(">=" + match[1])
+			if err != nil {
+				return nil, err
+			}
+			upper, err := parseSemverComparator("<=" + match[2])
+			if err != nil {
+				return nil, err
+			}
+			alternatives = append(alternatives, append(lower, upper...))
+			continue
+		}
+
+		alternative = semverOperatorSpaceRegex.ReplaceAllString(alternative, "$1")
+		for _, part := range strings.Fields(strings.ReplaceAll(alternative, ",", " ")) {
+			parsed, err := parseSemverComparator(part)
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, parsed...)
+		}
+		alternatives = append(alternatives, comparators)
+	}
+	return alternatives, nil
+}
+
+// semverSatisfies checks if the version matches all the comparators of any of the alternatives. Like npm,
+// a prerelease version only matches when a comparator has a prerelease of the same major.minor.patch.
+func semverSatisfies(version semVersion, constraint [][]semverComparator) bool {
+	for _, comparators := range constraint {
+		allMatch := true
+		prereleaseAllowed := len(version.Prerelease) == 0
+		for _, comparator := range comparators {
+			if !comparator.matches(version) {
+				allMatch = false
+				break
+			}
+			prereleaseAllowed = prereleaseAllowed || (len(comparator.Version.Prerelease) > 0 &&
+				comparator.Version.Major == version.Major && comparator.Version.Minor == version.Minor &&
+				comparator.Version.Patch == version.Patch)
+		}
+		if allMatch && prereleaseAllowed {
+			return true
+		}
+	}
+	return false
+}
+
+func getSemVersion(candidate *CandidateNode) (semVersion, error) {
+	node := unwrapDoc(candidate.Node)
+	if node.Kind != yaml.ScalarNode {
+		return semVersion{}, fmt.Errorf("cannot parse %v at [%v] as a semantic version, can only parse scalars", node.Tag, candidate.GetNicePath())
+	}
+	return parseSemVersion(node.Value)
+}
+
+func semverOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		if _, err := getSemVersion(candidate); err != nil {
+			return Context{}, err
+		}
+		node := unwrapDoc(candidate.Node)
+		results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: semverTag, Value: node.Value, Style: node.Style}))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+func semverParseOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		version, err := getSemVersion(candidate)
+		if err != nil {
+			return Context{}, err
+		}
+
+		prerelease := createScalarNode(nil, "null")
+		if len(version.Prerelease) > 0 {
+			prerelease = createStringScalarNode(strings.Join(version.Prerelease, "."))
+		}
+		build := createScalarNode(nil, "null")
+		if version.Build != "" {
+			build = createStringScalarNode(version.Build)
+		}
+
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+			createStringScalarNode("major"), createScalarNode(int64(version.Major), fmt.Sprintf("%v", version.Major)),
+			createStringScalarNode("minor"), createScalarNode(int64(version.Minor), fmt.Sprintf("%v", version.Minor)),
+			createStringScalarNode("patch"), createScalarNode(int64(version.Patch), fmt.Sprintf("%v", version.Patch)),
+			createStringScalarNode("prerelease"), prerelease,
+			createStringScalarNode("build"), build,
+		}}
+		results.PushBack(candidate.CreateReplacement(node))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+func semverCompareOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	otherStr, err := getStringParamter("version", d, context, expressionNode.RHS)
+	if err != nil {
+		return Context{}, err
+	}
+	other, err := parseSemVersion(otherStr)
+	if err != nil {
+		return Context{}, err
+	}
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		version, err := getSemVersion(candidate)
+		if err != nil {
+			return Context{}, err
+		}
+		result := version.compare(other)
+		results.PushBack(candidate.CreateReplacement(createScalarNode(result, fmt.Sprintf("%v", result))))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+func semverSatisfiesOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	constraintStr, err := getStringParamter("constraint", d, context, expressionNode.RHS)
+	if err != nil {
+		return Context{}, err
+	}
+	constraint, err := parseSemverConstraint(constraintStr)
+	if err != nil {
+		return Context{}, fmt.Errorf("could not parse semver constraint '%v': %w", constraintStr, err)
+	}
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		version, err := getSemVersion(candidate)
+		if err != nil {
+			return Context{}, err
+		}
+		results.PushBack(createBooleanCandidate(candidate, semverSatisfies(version, constraint)))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+func semverBumpOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	part, err := getStringParamter("part", d, context, expressionNode.RHS)
+	if err != nil {
+		return Context{}, err
+	}
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		version, err := getSemVersion(candidate)
+		if err != nil {
+			return Context{}, err
+		}
+		bumped, err := version.bump(part)
+		if err != nil {
+			return Context{}, err
+		}
+		node := unwrapDoc(candidate.Node)
+		results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: node.Tag, Style: node.Style, Value: bumped.String()}))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+// compareSemverNodes is used by the compare and sort operators when either node is tagged as a semver.
+func compareSemverNodes(lhs *yaml.Node, rhs *yaml.Node) (int, error) {
+	lhsVersion, err := parseSemVersion(lhs.Value)
+	if err != nil {
+		return 0, err
+	}
+	rhsVersion, err := parseSemVersion(rhs.Value)
+	if err != nil {
+		return 0, err
+	}
+	return lhsVersion.compare(rhsVersion), nil
+}
@@ -0,0 +1,145 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var semverOperatorScenarios = []expressionScenario{
+	{
+		description:    "Parse a semantic version",
+		subdescription: "Missing minor and patch versions default to 0, and a leading `v` is allowed.",
+		document:       `version: v1.2.3-rc.1+build.5`,
+		expression:     `.version | semver_parse`,
+		expected: []string{
+			"D0, P[version], (!!map)::major: 1\nminor: 2\npatch: 3\nprerelease: rc.1\nbuild: build.5\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `version: "1.2"`,
+		expression: `.version | semver_parse`,
+		expected: []string{
+			"D0, P[version], (!!map)::major: 1\nminor: 2\npatch: 0\nprerelease: null\nbuild: null\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `version: cat`,
+		expression:    `.version | semver_parse`,
+		expectedError: "could not parse 'cat' as a semantic version",
+	},
+	{
+		description:    "Compare semantic versions",
+		subdescription: "Returns -1, 0 or 1 if the version is less than, equal to or greater than the given version.",
+		document:       `[1.9.0, 1.10.0, 1.10.0-rc.1]`,
+		expression:     `[.[] | semver_compare("1.10.0")]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- -1\n- 0\n- -1\n",
+		},
+	},
+	{
+		description:    "Compare operators with semantic versions",
+		subdescription: "Use `semver` to compare with semantic version ordering rather than as strings.",
+		document:       `[1.9.0, 1.10.0, 2.0.0-beta]`,
+		expression:     `.[] | select(semver >= "1.10.0")`,
+		expected: []string{
+			"D0, P[1], (!!str)::1.10.0\n",
+			"D0, P[2], (!!str)::2.0.0-beta\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: 1.0.0-alpha.beta`,
+		expression: `.a | [semver < "1.0.0-alpha.1", semver > "1.0.0-alpha", semver < "1.0.0-beta"]`,
+		expected: []string{
+			"D0, P[a], (!!seq)::- false\n- true\n- true\n",
+		},
+	},
+	{
+		description:    "Sort by semantic version",
+		subdescription: "Sorting as strings would put 1.10.0 before 1.9.0.",
+		document:       `[1.10.0, 1.9.0, 1.10.0-rc.2, 1.10.0-rc.10, v0.9.1]`,
+		expression:     `sort_by(semver)`,
+		expected: []string{
+			"D0, P[], (!!seq)::[v0.9.1, 1.9.0, 1.10.0-rc.2, 1.10.0-rc.10, 1.10.0]\n",
+		},
+	},
+	{
+		description: "Sort maps by a semantic version field",
+		document:    `[{name: b, version: 1.10.0}, {name: a, version: 1.9.3}]`,
+		expression:  `sort_by(.version | semver) | .[].name`,
+		expected: []string{
+			"D0, P[0 name], (!!str)::a\n",
+			"D0, P[1 name], (!!str)::b\n",
+		},
+	},
+	{
+		description:    "Check a version satisfies a constraint",
+		subdescription: "Supports npm style constraints: `^`, `~`, x-ranges (`1.2.x`), hyphen ranges (`1.2 - 2.3`), comparators separated by spaces (and) or `||` (or).",
+		document:       `[0.9.0, 1.2.0, 1.9.3, 2.0.0-rc.1, 2.1.0]`,
+		expression:     `[.[] | select(semver_satisfies("^1.2 || >=2.1"))]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 1.2.0\n- 1.9.3\n- 2.1.0\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[0.9.0, 1.0.0, 1.9.3, 2.0.0-rc.1, 2.0.0]`,
+		expression: `[.[] | select(semver_satisfies(">= 1.0 <2.0"))]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 1.0.0\n- 1.9.3\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[1.2.2, 1.2.3, 1.2.9, 1.3.0, 0.2.5, 0.3.0]`,
+		expression: `[([.[] | select(semver_satisfies("~1.2.3"))] | length), ([.[] | select(semver_satisfies("^0.2.3"))] | length), ([.[] | select(semver_satisfies("1.2.x"))] | length), ([.[] | select(semver_satisfies("1.2.3 - 1.3"))] | length), ([.[] | select(semver_satisfies("*"))] | length)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 2\n- 1\n- 3\n- 3\n- 6\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[1.2.3, 1.3.0, 1.2.4]`,
+		expression: `[.[] | select(semver_satisfies(">1.2 || <=1.2.3"))]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 1.2.3\n- 1.3.0\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[1.2.4-beta, 1.2.3-beta, 1.2.3-alpha, 1.2.3, 2.0.0-rc.1]`,
+		expression: `[([.[] | select(semver_satisfies("^1.2.3"))] | join(",")), ([.[] | select(semver_satisfies(">=1.2.3-beta <2"))] | join(",")), ([.[] | select(semver_satisfies("*"))] | join(","))]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 1.2.3\n- 1.2.3-beta,1.2.3\n- 1.2.3\n",
+		},
+	},
+	{
+		description:    "Bump a version",
+		subdescription: "Bumps the major, minor, patch or prerelease part of the version.",
+		document:       "version: v1.9.3\nappVersion: 2.0.0-rc.1",
+		expression:     `.version |= semver_bump("minor") | .appVersion |= semver_bump("prerelease")`,
+		expected: []string{
+			"D0, P[], (doc)::version: v1.10.0\nappVersion: 2.0.0-rc.2\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		expression: `("1.2.3" | [semver_bump("major"), semver_bump("patch"), semver_bump("prerelease")]) + ("2.0.0-rc" | [semver_bump("major"), semver_bump("prerelease")])`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 2.0.0\n- 1.2.4\n- 1.2.4-0\n- 2.0.0\n- 2.0.0-rc.1\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		expression:    `"1.2.3" | semver_bump("huge")`,
+		expectedError: "unknown semver part 'huge', expected one of major, minor, patch or prerelease",
+	},
+}
+
+func TestSemverOperatorScenarios(t *testing.T) {
+	for _, tt := range semverOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "semver", semverOperatorScenarios)
+}
@@ -12,9 +12,9 @@ import (
 )
 
 func sortOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	// the parsed expression may be evaluated concurrently, so sort by self without changing it
 	selfExpression := &ExpressionNode{Operation: &Operation{OperationType: selfReferenceOpType}}
-	expressionNode.RHS = selfExpression
-	return sortByOperator(d, context, expressionNode)
+	return sortByOperator(d, context, &ExpressionNode{Operation: expressionNode.Operation, LHS: expressionNode.LHS, RHS: selfExpression})
 }
 
 // context represents the current matching nodes in the expression pipeline
@@ -96,6 +96,15 @@ func (a sortableNodeArray) compare(lhs *yaml.Node, rhs *yaml.Node, dateTimeLayou
 	lhsTag := lhs.Tag
 	rhsTag := rhs.Tag
 
+	if lhsTag == semverTag && rhsTag == semverTag {
+		result, err := compareSemverNodes(lhs, rhs)
+		if err == nil {
+			return result
+		}
+		log.Warningf("Could not compare %v and %v as semantic versions, sorting by string instead: %v", lhs.Value, rhs.Value, err)
+		return strings.Compare(lhs.Value, rhs.Value)
+	}
+
 	if !strings.HasPrefix(lhsTag, "!!") {
 		// custom tag - we have to have a guess
 		lhsTag = guessTagFromCustomType(lhs)
@@ -0,0 +1,187 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// createStreamEvent creates a [path, leaf] event, or a [path] event that closes the collection containing the path
+// when the leaf is nil.
+func createStreamEvent(path []*yaml.Node, leaf *yaml.Node) *yaml.Node {
+	pathNode := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle, Content: path}
+	event := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle, Content: []*yaml.Node{pathNode}}
+	if leaf != nil {
+		event.Content = append(event.Content, leaf)
+	}
+	return event
+}
+
+func createIndexNode(index int) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprintf("%v", index)}
+}
+
+func appendStreamPath(path []*yaml.Node, key *yaml.Node) []*yaml.Node {
+	childPath := make([]*yaml.Node, len(path), len(path)+1)
+	copy(childPath, path)
+	return append(childPath, key)
+}
+
+// appendStreamEvents adds the events of the node at the given path: one for each leaf (scalars and empty
+// collections), followed by one closing each non-empty collection.
+func appendStreamEvents(events []*yaml.Node, path []*yaml.Node, node *yaml.Node) []*yaml.Node {
+	node = unwrapDoc(node)
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	var lastPath []*yaml.Node
+	switch node.Kind {
+	case yaml.MappingNode:
+		for index := 0; index+1 < len(node.Content); index = index + 2 {
+			lastPath = appendStreamPath(path, node.Content[index])
+			events = appendStreamEvents(events, lastPath, node.Content[index+1])
+		}
+	case yaml.SequenceNode:
+		for index, child := range node.Content {
+			lastPath = appendStreamPath(path, createIndexNode(index))
+			events = appendStreamEvents(events, lastPath, child)
+		}
+	}
+	if lastPath == nil {
+		return append(events, createStreamEvent(path, node))
+	}
+	return append(events, createStreamEvent(lastPath, nil))
+}
+
+func parseStreamEvent(event *yaml.Node) ([]*yaml.Node, *yaml.Node, error) {
+	event = unwrapDoc(event)
+	if event.Kind != yaml.SequenceNode || len(event.Content) == 0 || len(event.Content) > 2 ||
+		unwrapDoc(event.Content[0]).Kind != yaml.SequenceNode {
+		return nil, nil, fmt.Errorf("invalid stream event, expected [path, leaf] or [path]")
+	}
+	path := unwrapDoc(event.Content[0]).Content
+	if len(event.Content) == 1 {
+		return path, nil, nil
+	}
+	return path, event.Content[1], nil
+}
+
+// setStreamValue sets the value at the path, creating the collections along the way.
+func setStreamValue(node *yaml.Node, path []*yaml.Node, value *yaml.Node) (*yaml.Node, error) {
+	if len(path) == 0 {
+		return deepClone(value), nil
+	}
+	key := path[0]
+	absent := node == nil || node.Tag == "!!null"
+	if key.Tag == "!!int" {
+		index, err := parseInt(key.Value)
+		if err != nil {
+			return nil, err
+		}
+		if absent {
+			node = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		} else if node.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("cannot index %v with %v", node.Tag, key.Value)
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("invalid stream path index %v", index)
+		}
+		for len(node.Content) <= index {
+			node.Content = append(node.Content, createScalarNode(nil, "null"))
+		}
+		node.Content[index], err = setStreamValue(node.Content[index], path[1:], value)
+		return node, err
+	}
+
+	if absent {
+		node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	} else if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("cannot index %v with '%v'", node.Tag, key.Value)
+	}
+	for index := 0; index+1 < len(node.Content); index = index + 2 {
+		if node.Content[index].Value == key.Value {
+			child, err := setStreamValue(node.Content[index+1], path[1:], value)
+			node.Content[index+1] = child
+			return node, err
+		}
+	}
+	child, err := setStreamValue(nil, path[1:], value)
+	node.Content = append(node.Content, deepClone(key), child)
+	return node, err
+}
+
+func toStreamOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- toStreamOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		for _, event := range appendStreamEvents(nil, []*yaml.Node{}, candidate.Node) {
+			results.PushBack(candidate.CreateReplacement(event))
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+func fromStreamOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- fromStreamOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		events, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), expressionNode.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+
+		var value *yaml.Node
+		for eventEl := events.MatchingNodes.Front(); eventEl != nil; eventEl = eventEl.Next() {
+			event := eventEl.Value.(*CandidateNode)
+			path, leaf, err := parseStreamEvent(event.Node)
+			if err != nil {
+				return Context{}, err
+			}
+			complete := len(path) == 1
+			if leaf != nil {
+				complete = len(path) == 0
+				if value, err = setStreamValue(value, path, leaf); err != nil {
+					return Context{}, err
+				}
+			}
+			if complete {
+				if value == nil {
+					value = createScalarNode(nil, "null")
+				}
+				results.PushBack(candidate.CreateReplacement(value))
+				value = nil
+			}
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+func truncateStreamOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- truncateStreamOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		depth, err := parseInt(unwrapDoc(candidate.Node).Value)
+		if err != nil {
+			return Context{}, fmt.Errorf("truncate_stream needs a depth as input: %w", err)
+		}
+		events, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), expressionNode.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+		for eventEl := events.MatchingNodes.Front(); eventEl != nil; eventEl = eventEl.Next() {
+			event := eventEl.Value.(*CandidateNode)
+			path, leaf, err := parseStreamEvent(event.Node)
+			if err != nil {
+				return Context{}, err
+			}
+			if len(path) > depth {
+				results.PushBack(candidate.CreateReplacement(createStreamEvent(path[depth:], leaf)))
+			}
+		}
+	}
+	return context.ChildContext(results), nil
+}
@@ -0,0 +1,82 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var streamOperatorScenarios = []expressionScenario{
+	{
+		description: "To stream",
+		document:    `{a: [1, {b: cat}], c: {}}`,
+		expression:  `[tostream]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- [[a, 0], 1]\n- [[a, 1, b], cat]\n- [[a, 1, b]]\n- [[a, 1]]\n- [[c], {}]\n- [[c]]\n",
+		},
+	},
+	{
+		description: "To stream a scalar",
+		document:    `cat`,
+		expression:  `tostream`,
+		expected: []string{
+			"D0, P[], (!!seq)::[[], cat]\n",
+		},
+	},
+	{
+		description: "From stream",
+		document:    `{a: [1, {b: cat}], c: {}}`,
+		expression:  `fromstream(tostream)`,
+		expected: []string{
+			"D0, P[], (!!map)::a:\n    - 1\n    - b: cat\nc: {}\n",
+		},
+	},
+	{
+		description: "From stream of events",
+		document:    `[[[0], a], [[1, b], c], [[1, b]], [[1]]]`,
+		expression:  `fromstream(.[])`,
+		expected: []string{
+			"D0, P[], (!!seq)::- a\n- b: c\n",
+		},
+	},
+	{
+		skipDoc:     true,
+		description: "From stream of many values",
+		document:    `[[[], 1], [[a], 2], [[a]]]`,
+		expression:  `fromstream(.[])`,
+		expected: []string{
+			"D0, P[], (!!int)::1\n",
+			"D0, P[], (!!map)::a: 2\n",
+		},
+	},
+	{
+		description:    "Truncate stream",
+		subdescription: "Removes the given number of path elements from the start of the events, dropping those that are too short.",
+		document:       `{a: [1, {b: cat}]}`,
+		expression:     `[. as $doc | 1 | truncate_stream($doc | tostream)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- [[0], 1]\n- [[1, b], cat]\n- [[1, b]]\n- [[1]]\n",
+		},
+	},
+	{
+		description: "Rebuild the children",
+		document:    `{a: [1, 2], b: {c: 3}}`,
+		expression:  `. as $doc | fromstream(1 | truncate_stream($doc | tostream))`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 1\n- 2\n",
+			"D0, P[], (!!map)::c: 3\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		description:   "From stream of an invalid event",
+		document:      `[a]`,
+		expression:    `fromstream(.[])`,
+		expectedError: "invalid stream event, expected [path, leaf] or [path]",
+	},
+}
+
+func TestStreamOperatorScenarios(t *testing.T) {
+	for _, tt := range streamOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "stream", streamOperatorScenarios)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,6 +14,20 @@ type changeCasePrefs struct {
 	ToUpperCase bool
 }
 
+type convertCaseStyle int
+
+const (
+	camelCaseStyle convertCaseStyle = iota
+	pascalCaseStyle
+	snakeCaseStyle
+	screamingSnakeCaseStyle
+	kebabCaseStyle
+)
+
+type convertCasePrefs struct {
+	Style convertCaseStyle
+}
+
 func trimSpaceOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
 	results := list.New()
 	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
@@ -60,49 +75,188 @@ func changeCaseOperator(d *dataTreeNavigator, context Context, expressionNode *E
 
 }
 
-func getSubstituteParameters(d *dataTreeNavigator, block *ExpressionNode, context Context) (string, string, error) {
-	regEx := ""
-	replacementText := ""
+// splitWords breaks a string into its words, splitting on any
+// non alphanumeric character as well as on case changes - so that
+// "helmValues", "HelmValues", "helm_values" and "HELM-VALUES"
+// all result in the same words. Acronyms are kept together,
+// e.g. "HTTPServer" becomes "HTTP" and "Server".
+func splitWords(value string) []string {
+	words := make([]string, 0)
+	runes := []rune(value)
+	current := make([]rune, 0)
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = make([]rune, 0)
+		}
+	}
 
-	regExNodes, err := d.GetMatchingNodes(context.ReadOnlyClone(), block.LHS)
-	if err != nil {
-		return "", "", err
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if len(current) > 0 && unicode.IsUpper(r) {
+			previous := current[len(current)-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
 	}
-	if regExNodes.MatchingNodes.Front() != nil {
-		regEx = regExNodes.MatchingNodes.Front().Value.(*CandidateNode).Node.Value
+	flush()
+	return words
+}
+
+func capitalise(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func convertCase(value string, style convertCaseStyle) string {
+	words := splitWords(value)
+	for i, word := range words {
+		switch style {
+		case camelCaseStyle:
+			if i == 0 {
+				words[i] = strings.ToLower(word)
+			} else {
+				words[i] = capitalise(word)
+			}
+		case pascalCaseStyle:
+			words[i] = capitalise(word)
+		case screamingSnakeCaseStyle:
+			words[i] = strings.ToUpper(word)
+		default:
+			words[i] = strings.ToLower(word)
+		}
+	}
+
+	switch style {
+	case snakeCaseStyle, screamingSnakeCaseStyle:
+		return strings.Join(words, "_")
+	case kebabCaseStyle:
+		return strings.Join(words, "-")
+	default:
+		return strings.Join(words, "")
+	}
+}
+
+func convertCaseOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	results := list.New()
+	prefs := expressionNode.Operation.Preferences.(convertCasePrefs)
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+
+		node := unwrapDoc(candidate.Node)
+
+		if guessTagFromCustomType(node) != "!!str" {
+			return Context{}, fmt.Errorf("cannot convert case of %v, can only operate on strings. ", node.Tag)
+		}
+
+		newStringNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: node.Tag, Style: node.Style}
+		newStringNode.Value = convertCase(node.Value, prefs.Style)
+		results.PushBack(candidate.CreateReplacement(newStringNode))
+
 	}
 
-	log.Debug("regEx %v", regEx)
+	return context.ChildContext(results), nil
+}
+
+func getSubstituteParameters(d *dataTreeNavigator, block *ExpressionNode, context Context) (*regexp.Regexp, *ExpressionNode, matchPreferences, error) {
+	regExExpNode := block.LHS
+	replacementExpNode := block.RHS
+	var flagsExpNode *ExpressionNode
+
+	// we got given flags e.g. sub(regex; replacement; flags)
+	// blocks nest to the right, unions to the left.
+	if block.RHS.Operation.OperationType == block.Operation.OperationType {
+		replacementExpNode = block.RHS.LHS
+		flagsExpNode = block.RHS.RHS
+	} else if block.LHS.Operation.OperationType == block.Operation.OperationType {
+		regExExpNode = block.LHS.LHS
+		replacementExpNode = block.LHS.RHS
+		flagsExpNode = block.RHS
+	}
 
-	replacementNodes, err := d.GetMatchingNodes(context, block.RHS)
+	regEx, matchPrefs, err := compileRegexWithFlags(d, context, regExExpNode, flagsExpNode)
 	if err != nil {
-		return "", "", err
+		return nil, nil, matchPrefs, err
 	}
-	if replacementNodes.MatchingNodes.Front() != nil {
-		replacementText = replacementNodes.MatchingNodes.Front().Value.(*CandidateNode).Node.Value
+	if flagsExpNode == nil {
+		// for backwards compatibility, sub without flags replaces every match
+		matchPrefs.Global = true
 	}
 
-	return regEx, replacementText, nil
+	return regEx, replacementExpNode, matchPrefs, nil
 }
 
-func substitute(original string, regex *regexp.Regexp, replacement string) *yaml.Node {
-	replacedString := regex.ReplaceAllString(original, replacement)
-	return &yaml.Node{Kind: yaml.ScalarNode, Value: replacedString, Tag: "!!str"}
+// substitute evaluates the replacement expression for each match, with the
+// capture groups available as variables: $0 for the whole match, $1..$n by
+// position and $name for named groups. A literal string replacement may
+// instead refer to groups using the ${1} template syntax of Go's regexp.Expand,
+// the result of any other expression is used as is.
+func substitute(d *dataTreeNavigator, context Context, candidate *CandidateNode, regEx *regexp.Regexp, replacementExp *ExpressionNode, matchPrefs matchPreferences) (*yaml.Node, error) {
+	original := unwrapDoc(candidate.Node).Value
+	_, allIndices := getMatches(matchPrefs, regEx, original)
+	subNames := regEx.SubexpNames()
+	isTemplate := replacementExp.Operation.OperationType == valueOpType
+
+	var replaced strings.Builder
+	lastIndex := 0
+	for _, indices := range allIndices {
+		if len(indices) == 0 {
+			continue
+		}
+		replacementContext := context.SingleReadonlyChildContext(candidate)
+		for group := 0; group < len(indices)/2; group++ {
+			groupNode := createScalarNode(nil, "null")
+			if indices[group*2] >= 0 {
+				groupValue := original[indices[group*2]:indices[group*2+1]]
+				groupNode = createScalarNode(groupValue, groupValue)
+			}
+			groupValues := candidate.CreateReplacement(groupNode).AsList()
+			replacementContext.SetVariable(fmt.Sprintf("%v", group), groupValues)
+			if subNames[group] != "" {
+				replacementContext.SetVariable(subNames[group], groupValues)
+			}
+		}
+
+		replacementNodes, err := d.GetMatchingNodes(replacementContext, replacementExp)
+		if err != nil {
+			return nil, err
+		}
+		replacementText := ""
+		if replacementNodes.MatchingNodes.Front() != nil {
+			replacementText = replacementNodes.MatchingNodes.Front().Value.(*CandidateNode).Node.Value
+		}
+
+		replaced.WriteString(original[lastIndex:indices[0]])
+		if isTemplate {
+			replaced.Write(regEx.ExpandString(nil, replacementText, original, indices))
+		} else {
+			replaced.WriteString(replacementText)
+		}
+		lastIndex = indices[1]
+	}
+	replaced.WriteString(original[lastIndex:])
+
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: replaced.String(), Tag: "!!str"}, nil
 }
 
 func substituteStringOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
 	//rhs  block operator
 	//lhs of block = regex
 	//rhs of block = replacement expression
+	//optionally, the lhs of block is another block of regex and replacement,
+	//and the rhs is the flags
 	block := expressionNode.RHS
 
-	regExStr, replacementText, err := getSubstituteParameters(d, block, context)
-
-	if err != nil {
-		return Context{}, err
-	}
-
-	regEx, err := regexp.Compile(regExStr)
+	regEx, replacementExp, matchPrefs, err := getSubstituteParameters(d, block, context)
 	if err != nil {
 		return Context{}, err
 	}
@@ -117,7 +271,10 @@ func substituteStringOperator(d *dataTreeNavigator, context Context, expressionN
 			return Context{}, fmt.Errorf("cannot substitute with %v, can only substitute strings. Hint: Most often you'll want to use '|=' over '=' for this operation", node.Tag)
 		}
 
-		targetNode := substitute(node.Value, regEx, replacementText)
+		targetNode, err := substitute(d, context, candidate, regEx, replacementExp, matchPrefs)
+		if err != nil {
+			return Context{}, err
+		}
 		result := candidate.CreateReplacement(targetNode)
 		results.PushBack(result)
 	}
@@ -158,7 +315,8 @@ func addMatch(original []*yaml.Node, match string, offset int, name string) []*y
 }
 
 type matchPreferences struct {
-	Global bool
+	Global   bool
+	Extended bool
 }
 
 func getMatches(matchPrefs matchPreferences, regEx *regexp.Regexp, value string) ([][]string, [][]int) {
@@ -245,32 +403,74 @@ func capture(matchPrefs matchPreferences, regEx *regexp.Regexp, candidate *Candi
 
 }
 
-func extractMatchArguments(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (*regexp.Regexp, matchPreferences, error) {
-	regExExpNode := expressionNode.RHS
+// stripExtendedRegex removes whitespace and # comments from a regex,
+// emulating the 'x' flag (which golang's RE2 does not support natively).
+// Escaped characters and character classes are left untouched.
+func stripExtendedRegex(regExStr string) string {
+	var result strings.Builder
+	inClass := false
+	inComment := false
+	for i := 0; i < len(regExStr); i++ {
+		c := regExStr[i]
+		switch {
+		case inComment:
+			inComment = c != '\n'
+		case c == '\\' && i+1 < len(regExStr):
+			result.WriteByte(c)
+			result.WriteByte(regExStr[i+1])
+			i++
+		case inClass:
+			inClass = c != ']'
+			result.WriteByte(c)
+		case c == '[':
+			inClass = true
+			result.WriteByte(c)
+		case c == '#':
+			inComment = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		default:
+			result.WriteByte(c)
+		}
+	}
+	return result.String()
+}
+
+func parseRegexFlags(paramText string) (string, matchPreferences, error) {
+	matchPrefs := matchPreferences{}
+	goFlags := ""
+	for _, flag := range paramText {
+		switch flag {
+		case 'g':
+			matchPrefs.Global = true
+		case 'i', 's':
+			if !strings.ContainsRune(goFlags, flag) {
+				goFlags = goFlags + string(flag)
+			}
+		case 'x':
+			matchPrefs.Extended = true
+		default:
+			return "", matchPrefs, fmt.Errorf(`Unrecognised regex flags '%v', please see docs at https://mikefarah.gitbook.io/yq/operators/string-operators`, paramText)
+		}
+	}
+	return goFlags, matchPrefs, nil
+}
 
+func compileRegexWithFlags(d *dataTreeNavigator, context Context, regExExpNode *ExpressionNode, flagsExpNode *ExpressionNode) (*regexp.Regexp, matchPreferences, error) {
+	goFlags := ""
 	matchPrefs := matchPreferences{}
 
-	// we got given parameters e.g. match(exp; params)
-	if expressionNode.RHS.Operation.OperationType == blockOpType {
-		block := expressionNode.RHS
-		regExExpNode = block.LHS
-		replacementNodes, err := d.GetMatchingNodes(context, block.RHS)
+	if flagsExpNode != nil {
+		flagNodes, err := d.GetMatchingNodes(context.ReadOnlyClone(), flagsExpNode)
 		if err != nil {
 			return nil, matchPrefs, err
 		}
 		paramText := ""
-		if replacementNodes.MatchingNodes.Front() != nil {
-			paramText = replacementNodes.MatchingNodes.Front().Value.(*CandidateNode).Node.Value
-		}
-		if strings.Contains(paramText, "g") {
-			paramText = strings.ReplaceAll(paramText, "g", "")
-			matchPrefs.Global = true
+		if flagNodes.MatchingNodes.Front() != nil {
+			paramText = flagNodes.MatchingNodes.Front().Value.(*CandidateNode).Node.Value
 		}
-		if strings.Contains(paramText, "i") {
-			return nil, matchPrefs, fmt.Errorf(`'i' is not a valid option for match. To ignore case, use an expression like match("(?i)cat")`)
-		}
-		if len(paramText) > 0 {
-			return nil, matchPrefs, fmt.Errorf(`Unrecognised match params '%v', please see docs at https://mikefarah.gitbook.io/yq/operators/string-operators`, paramText)
+		goFlags, matchPrefs, err = parseRegexFlags(paramText)
+		if err != nil {
+			return nil, matchPrefs, err
 		}
 	}
 
@@ -283,11 +483,31 @@ func extractMatchArguments(d *dataTreeNavigator, context Context, expressionNode
 	if regExNodes.MatchingNodes.Front() != nil {
 		regExStr = regExNodes.MatchingNodes.Front().Value.(*CandidateNode).Node.Value
 	}
+	if matchPrefs.Extended {
+		regExStr = stripExtendedRegex(regExStr)
+	}
+	if goFlags != "" {
+		regExStr = fmt.Sprintf("(?%v)%v", goFlags, regExStr)
+	}
 	log.Debug("regEx %v", regExStr)
 	regEx, err := regexp.Compile(regExStr)
 	return regEx, matchPrefs, err
 }
 
+func extractMatchArguments(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (*regexp.Regexp, matchPreferences, error) {
+	regExExpNode := expressionNode.RHS
+	var flagsExpNode *ExpressionNode
+
+	// we got given parameters e.g. match(exp; params)
+	if expressionNode.RHS.Operation.OperationType == blockOpType {
+		block := expressionNode.RHS
+		regExExpNode = block.LHS
+		flagsExpNode = block.RHS
+	}
+
+	return compileRegexWithFlags(d, context, regExExpNode, flagsExpNode)
+}
+
 func matchOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
 	regEx, matchPrefs, err := extractMatchArguments(d, context, expressionNode)
 	if err != nil {
@@ -355,6 +575,73 @@ func testOperator(d *dataTreeNavigator, context Context, expressionNode *Express
 	return context.ChildContext(results), nil
 }
 
+func scanOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	regEx, _, err := extractMatchArguments(d, context, expressionNode)
+	if err != nil {
+		return Context{}, err
+	}
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+
+		if guessTagFromCustomType(node) != "!!str" {
+			return Context{}, fmt.Errorf("cannot scan %v, can only scan strings. Hint: Most often you'll want to use '|=' over '=' for this operation", node.Tag)
+		}
+
+		// like jq, scan always matches globally
+		for _, matches := range regEx.FindAllStringSubmatchIndex(node.Value, -1) {
+			if len(matches) == 2 {
+				matchedString := node.Value[matches[0]:matches[1]]
+				results.PushBack(candidate.CreateReplacement(createScalarNode(matchedString, matchedString)))
+				continue
+			}
+			// when there are capture groups, return an array of them
+			capturesNode := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			for group := 1; group < len(matches)/2; group++ {
+				if matches[group*2] < 0 {
+					capturesNode.Content = append(capturesNode.Content, createScalarNode(nil, "null"))
+				} else {
+					groupValue := node.Value[matches[group*2]:matches[group*2+1]]
+					capturesNode.Content = append(capturesNode.Content, createScalarNode(groupValue, groupValue))
+				}
+			}
+			results.PushBack(candidate.CreateReplacement(capturesNode))
+		}
+	}
+
+	return context.ChildContext(results), nil
+}
+
+func splitsOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	regEx, _, err := extractMatchArguments(d, context, expressionNode)
+	if err != nil {
+		return Context{}, err
+	}
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if node.Tag == "!!null" {
+			continue
+		}
+
+		if guessTagFromCustomType(node) != "!!str" {
+			return Context{}, fmt.Errorf("cannot split %v, can only split strings", node.Tag)
+		}
+
+		for _, str := range regEx.Split(node.Value, -1) {
+			results.PushBack(candidate.CreateReplacement(createStringScalarNode(str)))
+		}
+	}
+
+	return context.ChildContext(results), nil
+}
+
 func joinStringOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
 	log.Debugf("-- joinStringOperator")
 	joinStr := ""
@@ -39,6 +39,61 @@ var stringsOperatorScenarios = []expressionScenario{
 			"D0, P[], (!camel)::água\n",
 		},
 	},
+	{
+		description:    "To camel case",
+		subdescription: "Words are split on any non alphanumeric character and on case changes.",
+		document:       `[helm_values, Image-Pull-Policy, HTTPServer, service account]`,
+		expression:     ".[] |= camel_case",
+		expected: []string{
+			"D0, P[], (doc)::[helmValues, imagePullPolicy, httpServer, serviceAccount]\n",
+		},
+	},
+	{
+		description: "To pascal case",
+		document:    `[helm_values, imagePullPolicy]`,
+		expression:  ".[] |= pascal_case",
+		expected: []string{
+			"D0, P[], (doc)::[HelmValues, ImagePullPolicy]\n",
+		},
+	},
+	{
+		description: "To snake case",
+		document:    `[helmValues, Image-Pull-Policy, HTTPServer, base64Value]`,
+		expression:  ".[] |= snake_case",
+		expected: []string{
+			"D0, P[], (doc)::[helm_values, image_pull_policy, http_server, base64_value]\n",
+		},
+	},
+	{
+		description: "To screaming snake case",
+		document:    `[helmValues, image-pull-policy]`,
+		expression:  ".[] |= screaming_snake_case",
+		expected: []string{
+			"D0, P[], (doc)::[HELM_VALUES, IMAGE_PULL_POLICY]\n",
+		},
+	},
+	{
+		description: "To kebab case",
+		document:    `[helmValues, IMAGE_PULL_POLICY]`,
+		expression:  ".[] |= kebab_case",
+		expected: []string{
+			"D0, P[], (doc)::[helm-values, image-pull-policy]\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `!camel helm_values`,
+		expression: "camelCase",
+		expected: []string{
+			"D0, P[], (!camel)::helmValues\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `3`,
+		expression:    "snake_case",
+		expectedError: "cannot convert case of !!int, can only operate on strings. ",
+	},
 	{
 		description: "Join strings",
 		document:    `[cat, meow, 1, null, true]`,
@@ -230,6 +285,144 @@ var stringsOperatorScenarios = []expressionScenario{
 			"D0, P[], (doc)::a: !horse cart\nb: !goat heart\n",
 		},
 	},
+	{
+		description:    "Substitute with flags",
+		subdescription: "Flags are given as a third parameter, `i` for case insensitive, `x` for extended, `s` to let `.` match new lines and `g` to replace every match. Note that without flags `sub` replaces every match, but when flags are given only the first match is replaced unless `g` is set.",
+		document:       `a: Cats and cats`,
+		expression:     `.a |= sub("cat"; "dog"; "ig")`,
+		expected: []string{
+			"D0, P[], (doc)::a: dogs and dogs\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: Cats and cats`,
+		expression: `.a |= sub("cat"; "dog"; "i")`,
+		expected: []string{
+			"D0, P[], (doc)::a: dogs and cats\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: Cats and cats`,
+		expression: `.a |= sub("cat", "dog", "g")`,
+		expected: []string{
+			"D0, P[], (doc)::a: Cats and dogs\n",
+		},
+	},
+	{
+		description:    "Substitute with a replacement expression",
+		subdescription: "The replacement expression is evaluated for each match. Capture groups are available as variables: `$0` is the whole match, `$1`..`$n` the groups by position, and named groups by their name.",
+		document:       `a: "version 1.2, build 34"`,
+		expression:     `.a |= sub("(?P<num>[0-9]+)"; "<" + $num + ">")`,
+		expected: []string{
+			"D0, P[], (doc)::a: \"version <1>.<2>, build <34>\"\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: "price: $5"`,
+		expression: `.a |= sub("(?P<all>.+)"; $all + "!")`,
+		expected: []string{
+			"D0, P[], (doc)::a: \"price: $5!\"\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: "price: 5"`,
+		expression: `.a |= sub("(?P<n>[0-9]+)"; "$" + $n)`,
+		expected: []string{
+			"D0, P[], (doc)::a: \"price: $5\"\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: "cat hat"`,
+		expression: `.a |= sub("(.)at"; $1 | upcase)`,
+		expected: []string{
+			"D0, P[], (doc)::a: \"C H\"\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: "cat"`,
+		expression: `.a |= sub("(?P<x>c)(d)?"; $x + ($2 // "-"))`,
+		expected: []string{
+			"D0, P[], (doc)::a: \"c-at\"\n",
+		},
+	},
+	{
+		description:    "Extended regex flag",
+		subdescription: "Whitespace and `#` comments in the regex are ignored.",
+		document:       `a: "2023-05-04"`,
+		expression:     `.a |= sub("(\d+) - (\d+) # year and month"; "$2/$1"; "x")`,
+		expected: []string{
+			"D0, P[], (doc)::a: \"05/2023-04\"\n",
+		},
+	},
+	{
+		description: "Match case insensitive",
+		document:    `Cat cat`,
+		expression:  `[match("cat"; "gi") | .string]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- Cat\n- cat\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `cat`,
+		expression:    `match("cat"; "gz")`,
+		expectedError: "Unrecognised regex flags 'gz', please see docs at https://mikefarah.gitbook.io/yq/operators/string-operators",
+	},
+	{
+		description:    "Scan for matches",
+		subdescription: "Returns each match of the regex as a string.",
+		document:       `"cat hat cats"`,
+		expression:     `[scan("c?at")]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- cat\n- at\n- cat\n",
+		},
+	},
+	{
+		description:    "Scan with capture groups",
+		subdescription: "When the regex has capture groups, each match is returned as an array of the captured strings.",
+		document:       `"a=1, b=2"`,
+		expression:     `[scan("(\w)=(\d)")]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- - a\n  - \"1\"\n- - b\n  - \"2\"\n",
+		},
+	},
+	{
+		description: "Scan with flags",
+		document:    `"Cat cAT"`,
+		expression:  `[scan("cat"; "i")]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- Cat\n- cAT\n",
+		},
+	},
+	{
+		description:    "Split with a regex",
+		subdescription: "Returns each part separately.",
+		document:       `"cat, dog ;cow"`,
+		expression:     `[splits(", *| *; *")]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- cat\n- dog\n- cow\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `"aXbxc"`,
+		expression: `[splits("x"; "i")]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- a\n- b\n- c\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `3`,
+		expression:    `scan("3")`,
+		expectedError: "cannot scan !!int, can only scan strings. Hint: Most often you'll want to use '|=' over '=' for this operation",
+	},
 	{
 		description: "Split strings",
 		document:    `"cat; meow; 1; ; true"`,
@@ -139,13 +139,7 @@ func subtractScalars(context Context, target *CandidateNode, lhs *yaml.Node, rhs
 }
 
 func subtractDateTime(layout string, target *CandidateNode, lhs *yaml.Node, rhs *yaml.Node) error {
-	var durationStr string
-	if strings.HasPrefix(rhs.Value, "-") {
-		durationStr = rhs.Value[1:]
-	} else {
-		durationStr = "-" + rhs.Value
-	}
-	duration, err := time.ParseDuration(durationStr)
+	duration, err := parseDateDuration(rhs.Value)
 
 	if err != nil {
 		return fmt.Errorf("unable to parse duration [%v]: %w", rhs.Value, err)
@@ -156,7 +150,11 @@ func subtractDateTime(layout string, target *CandidateNode, lhs *yaml.Node, rhs
 		return err
 	}
 
-	newTime := currentTime.Add(duration)
-	target.Node.Value = newTime.Format(layout)
+	newTime := duration.negate().addTo(currentTime)
+	formatted, err := formatTime(newTime, layout)
+	if err != nil {
+		return err
+	}
+	target.Node.Value = formatted
 	return nil
 }
@@ -0,0 +1,53 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func validateOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- validateOperator")
+
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+
+		schemaContext, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), expressionNode.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+		if schemaContext.MatchingNodes.Len() != 1 {
+			return Context{}, fmt.Errorf("VALIDATE: expected a single schema but found %v", schemaContext.MatchingNodes.Len())
+		}
+		schema := schemaContext.MatchingNodes.Front().Value.(*CandidateNode)
+
+		validator, err := newJSONSchemaValidatorForNode(schema.Node, schema.Filename)
+		if err != nil {
+			return Context{}, err
+		}
+		violations, err := validator.Validate(candidate.Node)
+		if err != nil {
+			return Context{}, err
+		}
+		results.PushBack(candidate.CreateReplacement(schemaViolationsToNode(violations)))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+func schemaViolationsToNode(violations []*SchemaViolation) *yaml.Node {
+	violationsNode := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, violation := range violations {
+		violationsNode.Content = append(violationsNode.Content, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+			createStringScalarNode("path"), diffPathNode(violation.Path),
+			createStringScalarNode("message"), createStringScalarNode(violation.Message),
+			createStringScalarNode("line"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(violation.Line)},
+			createStringScalarNode("column"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(violation.Column)},
+		}})
+	}
+	return violationsNode
+}
@@ -0,0 +1,76 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var validateOperatorScenarios = []expressionScenario{
+	{
+		description:    "Validate against a schema",
+		subdescription: "Returns the list of violations, or an empty list if the document is valid.",
+		document:       "name: frodo\nage: -1\n",
+		expression:     `validate({"type": "object", "required": ["name", "email"], "properties": {"age": {"type": "integer", "minimum": 0}}})`,
+		expected: []string{
+			"D0, P[], (!!seq)::- path: []\n  message: missing required property 'email'\n  line: 1\n  column: 1\n- path: [age]\n  message: -1 is less than the minimum of 0\n  line: 2\n  column: 6\n",
+		},
+	},
+	{
+		description:    "Check a document is valid",
+		subdescription: "Combine with `-e` to set the exit status.",
+		document:       "replicas: 3\n",
+		expression:     `validate({"properties": {"replicas": {"type": "integer"}}}) | length == 0`,
+		expected: []string{
+			"D0, P[], (!!bool)::true\n",
+		},
+	},
+	{
+		description:    "Using $ref and $defs",
+		subdescription: "References within the schema, and to other schema files, are resolved.",
+		document:       "ports: [80, http]\n",
+		expression:     `validate({"$defs": {"port": {"type": "integer", "maximum": 65535}}, "properties": {"ports": {"type": "array", "items": {"$ref": "#/$defs/port"}}}})`,
+		expected: []string{
+			"D0, P[], (!!seq)::- path: [ports, 1]\n  message: expected integer but found string\n  line: 1\n  column: 13\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "a: cat\nb: [1, 1]\nc: {x: 1, y: 2}\nd: 2.5\n",
+		expression: `validate({"properties": {"a": {"enum": ["dog", "bird"]}, "b": {"uniqueItems": true, "contains": {"const": 2}}, "c": {"additionalProperties": false, "properties": {"x": true}}, "d": {"multipleOf": 1}}}) | .[] | .message`,
+		expected: []string{
+			"D0, P[0 message], (!!str)::cat is not one of the allowed values [dog, bird]\n",
+			"D0, P[1 message], (!!str)::items must be unique, but this is the same as item 0\n",
+			"D0, P[2 message], (!!str)::must contain at least 1 item(s) matching 'contains', but found 0\n",
+			"D0, P[3 message], (!!str)::value is not allowed\n",
+			"D0, P[4 message], (!!str)::2.5 is not a multiple of 1\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "kind: service\nport: 80\n",
+		expression: `validate({"oneOf": [{"properties": {"kind": {"const": "service"}}, "required": ["port"]}, {"properties": {"kind": {"const": "job"}}}], "if": {"properties": {"kind": {"const": "service"}}}, "then": {"required": ["name"]}}) | .[] | .message`,
+		expected: []string{
+			"D0, P[0 message], (!!str)::missing required property 'name'\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   "a: 1\nb: 2\n",
+		expression: `validate({"allOf": [{"properties": {"a": true}}], "unevaluatedProperties": false}) | .[] | .path`,
+		expected: []string{
+			"D0, P[0 path], (!!seq)::[b]\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      "a: 1\n",
+		expression:    `validate({"$ref": "#/$defs/missing"})`,
+		expectedError: "could not resolve $ref '#/$defs/missing': path '/$defs' does not exist",
+	},
+}
+
+func TestValidateOperatorScenarios(t *testing.T) {
+	for _, tt := range validateOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "validate", validateOperatorScenarios)
+}
@@ -0,0 +1,152 @@
+package yqlib
+
+import (
+	"bufio"
+	"container/list"
+	"io"
+	"os"
+)
+
+// bufferedPrinter keeps the results of each document, so they can be printed once the files before them have been.
+type bufferedPrinter struct {
+	results []*list.List
+}
+
+func (p *bufferedPrinter) PrintResults(matchingNodes *list.List) error {
+	p.results = append(p.results, matchingNodes)
+	return nil
+}
+
+func (p *bufferedPrinter) PrintedAnything() bool {
+	return len(p.results) > 0
+}
+
+func (p *bufferedPrinter) SetAppendix(reader io.Reader) {}
+
+func (p *bufferedPrinter) SetNulSepOutput(nulSepOutput bool) {}
+
+// fileEvaluation is a file to be evaluated by a worker, which sends the outcome on the results channel.
+type fileEvaluation struct {
+	fileIndex int
+	filename  string
+	results   chan fileResults
+}
+
+type fileResults struct {
+	results   []*list.List
+	documents uint
+	err       error
+}
+
+type parallelStreamEvaluator struct {
+	workers       int
+	createDecoder func() (Decoder, error)
+}
+
+// NewParallelStreamEvaluator creates a StreamEvaluator that decodes and evaluates the files on the given number
+// of workers. The results are printed in the same order as they would be when evaluating the files in sequence.
+// Each worker after the first creates its own decoder, and the input operators only read from the file being
+// evaluated.
+func NewParallelStreamEvaluator(workers int, createDecoder func() (Decoder, error)) StreamEvaluator {
+	if workers < 1 {
+		workers = 1
+	}
+	return &parallelStreamEvaluator{workers: workers, createDecoder: createDecoder}
+}
+
+func (p *parallelStreamEvaluator) EvaluateNew(expression string, printer Printer) error {
+	return NewStreamEvaluator().EvaluateNew(expression, printer)
+}
+
+func (p *parallelStreamEvaluator) Evaluate(filename string, reader io.Reader, node *ExpressionNode, printer Printer, decoder Decoder) (uint, error) {
+	return NewStreamEvaluator().Evaluate(filename, reader, node, printer, decoder)
+}
+
+func (p *parallelStreamEvaluator) EvaluateFiles(expression string, filenames []string, printer Printer, decoder Decoder) error {
+	node, err := ExpressionParser.ParseExpression(expression)
+	if err != nil {
+		return err
+	}
+
+	decoders := []Decoder{decoder}
+	for len(decoders) < p.workers && len(decoders) < len(filenames) {
+		workerDecoder, err := p.createDecoder()
+		if err != nil {
+			return err
+		}
+		decoders = append(decoders, workerDecoder)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	evaluations := make(chan fileEvaluation)
+	// limits how many files are evaluated ahead of the one being printed
+	ordered := make(chan fileEvaluation, p.workers)
+
+	go func() {
+		defer close(evaluations)
+		defer close(ordered)
+		for fileIndex, filename := range filenames {
+			evaluation := fileEvaluation{fileIndex: fileIndex, filename: filename, results: make(chan fileResults, 1)}
+			select {
+			case ordered <- evaluation:
+			case <-done:
+				return
+			}
+			select {
+			case evaluations <- evaluation:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for _, workerDecoder := range decoders {
+		go p.evaluateFiles(node, workerDecoder, evaluations)
+	}
+
+	var totalProcessDocs uint
+	for evaluation := range ordered {
+		results := <-evaluation.results
+		totalProcessDocs = totalProcessDocs + results.documents
+		for _, matchingNodes := range results.results {
+			if err := printer.PrintResults(matchingNodes); err != nil {
+				return err
+			}
+		}
+		if results.err != nil {
+			return results.err
+		}
+	}
+
+	if totalProcessDocs == 0 {
+		return p.EvaluateNew(expression, printer)
+	}
+	return nil
+}
+
+func (p *parallelStreamEvaluator) evaluateFiles(node *ExpressionNode, decoder Decoder, evaluations <-chan fileEvaluation) {
+	for evaluation := range evaluations {
+		evaluation.results <- p.evaluateFile(node, decoder, evaluation)
+	}
+}
+
+func (p *parallelStreamEvaluator) evaluateFile(node *ExpressionNode, decoder Decoder, evaluation fileEvaluation) fileResults {
+	var reader io.Reader
+	if evaluation.filename == "-" {
+		reader = stdinStream()
+	} else {
+		// ignore CWE-22 gosec issue - that's more targeted for http based apps that run in a public directory,
+		// and ensuring that it's not possible to give a path to a file outside that directory.
+		file, err := os.Open(evaluation.filename) // #nosec
+		if err != nil {
+			return fileResults{err: err}
+		}
+		defer safelyCloseFile(file)
+		reader = bufio.NewReader(file)
+	}
+
+	printer := &bufferedPrinter{}
+	documents, err := newStreamEvaluator(evaluation.fileIndex).Evaluate(evaluation.filename, reader, node, printer, decoder)
+	return fileResults{results: printer.results, documents: documents, err: err}
+}
@@ -0,0 +1,113 @@
+package yqlib
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+func createYamlDecoder() (Decoder, error) {
+	return NewYamlDecoder(ConfiguredYamlPreferences), nil
+}
+
+func evaluateFilesWith(t *testing.T, evaluator StreamEvaluator, expression string, filenames []string) (string, error) {
+	var output bytes.Buffer
+	writer := bufio.NewWriter(&output)
+	printer := NewPrinter(NewYamlEncoder(2, false, ConfiguredYamlPreferences), NewSinglePrinterWriter(writer))
+	decoder, err := createYamlDecoder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = evaluator.EvaluateFiles(expression, filenames, printer, decoder)
+	writer.Flush()
+	return output.String(), err
+}
+
+func createTestFiles(count int, content func(index int) string) []string {
+	var filenames []string
+	for index := 0; index < count; index++ {
+		filenames = append(filenames, createTestFile(content(index)))
+	}
+	return filenames
+}
+
+func removeTestFiles(filenames []string) {
+	for _, filename := range filenames {
+		tryRemoveTempFile(filename)
+	}
+}
+
+func TestParallelStreamEvaluatorKeepsOrder(t *testing.T) {
+	InitExpressionParser()
+	filenames := createTestFiles(50, func(index int) string {
+		return fmt.Sprintf("a: %v\nb: [3, 1, 2]\n---\na: %v\n", index, index*10)
+	})
+	defer removeTestFiles(filenames)
+
+	expression := `{"a": .a, "b": (.b // [] | sort), "file": file_index, "doc": document_index}`
+	expected, err := evaluateFilesWith(t, NewStreamEvaluator(), expression, filenames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := evaluateFilesWith(t, NewParallelStreamEvaluator(4, createYamlDecoder), expression, filenames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResult(t, expected, actual)
+}
+
+func TestParallelStreamEvaluatorInputsStayInFile(t *testing.T) {
+	InitExpressionParser()
+	filenames := createTestFiles(3, func(index int) string {
+		return fmt.Sprintf("a: %v\n---\na: %v\n", index, index)
+	})
+	defer removeTestFiles(filenames)
+
+	actual, err := evaluateFilesWith(t, NewParallelStreamEvaluator(2, createYamlDecoder), `[., inputs] | length`, filenames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResult(t, "2\n---\n2\n---\n2\n", actual)
+}
+
+func TestParallelStreamEvaluatorPrintsResultsBeforeError(t *testing.T) {
+	InitExpressionParser()
+	filenames := createTestFiles(4, func(index int) string {
+		if index == 2 {
+			return "a: [\n"
+		}
+		return fmt.Sprintf("a: %v\n", index)
+	})
+	defer removeTestFiles(filenames)
+
+	actual, err := evaluateFilesWith(t, NewParallelStreamEvaluator(4, createYamlDecoder), `.a`, filenames)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	test.AssertResult(t, "0\n---\n1\n", actual)
+	test.AssertResult(t, fmt.Sprintf("bad file '%v': yaml: line 1: did not find expected node content", filenames[2]), err.Error())
+}
+
+func TestParallelStreamEvaluatorMissingFile(t *testing.T) {
+	InitExpressionParser()
+	_, err := evaluateFilesWith(t, NewParallelStreamEvaluator(2, createYamlDecoder), `.a`, []string{"does-not-exist.yaml"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	test.AssertResult(t, "open does-not-exist.yaml: no such file or directory", err.Error())
+}
+
+func TestParallelStreamEvaluatorEmptyFiles(t *testing.T) {
+	InitExpressionParser()
+	filenames := createTestFiles(2, func(index int) string { return "" })
+	defer removeTestFiles(filenames)
+
+	actual, err := evaluateFilesWith(t, NewParallelStreamEvaluator(2, createYamlDecoder), `"new"`, filenames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResult(t, "new\n", actual)
+}
@@ -7,8 +7,6 @@ import (
 	"fmt"
 	"io"
 	"regexp"
-
-	yaml "gopkg.in/yaml.v3"
 )
 
 type Printer interface {
@@ -33,6 +31,18 @@ const (
 	ShOutputFormat
 	TomlOutputFormat
 	ShellVariablesOutputFormat
+	CanonicalJSONOutputFormat
+	Sha256OutputFormat
+	Sha1OutputFormat
+	Md5OutputFormat
+	Crc32OutputFormat
+	Base32OutputFormat
+	HexOutputFormat
+	GzipOutputFormat
+	HtmlOutputFormat
+	JSONEscapeOutputFormat
+	TextOutputFormat
+	JSONSchemaOutputFormat
 )
 
 func OutputFormatFromString(format string) (PrinterOutputFormat, error) {
@@ -53,8 +63,22 @@ func OutputFormatFromString(format string) (PrinterOutputFormat, error) {
 		return TomlOutputFormat, nil
 	case "shell", "s", "sh":
 		return ShellVariablesOutputFormat, nil
+	case "base32":
+		return Base32OutputFormat, nil
+	case "hex":
+		return HexOutputFormat, nil
+	case "gzip":
+		return GzipOutputFormat, nil
+	case "html":
+		return HtmlOutputFormat, nil
+	case "json-escape":
+		return JSONEscapeOutputFormat, nil
+	case "text":
+		return TextOutputFormat, nil
+	case "jsonschema":
+		return JSONSchemaOutputFormat, nil
 	default:
-		return 0, fmt.Errorf("unknown format '%v' please use [yaml|json|props|csv|tsv|xml|toml|shell]", format)
+		return 0, fmt.Errorf("unknown format '%v' please use [yaml|json|props|csv|tsv|xml|toml|shell|base32|hex|gzip|html|json-escape|text|jsonschema]", format)
 	}
 }
 
@@ -94,9 +118,13 @@ func (p *resultsPrinter) PrintedAnything() bool {
 	return p.printedMatches
 }
 
-func (p *resultsPrinter) printNode(node *yaml.Node, writer io.Writer) error {
+func (p *resultsPrinter) printNode(candidate *CandidateNode, writer io.Writer) error {
+	node := candidate.Node
 	p.printedMatches = p.printedMatches || (node.Tag != "!!null" &&
 		(node.Tag != "!!bool" || node.Value != "false"))
+	if _, isYaml := p.encoder.(*yamlEncoder); isYaml && len(candidate.BlankLines) > 0 {
+		node = withBlankLines(node, candidate.BlankLines)
+	}
 	return p.encoder.Encode(writer, node)
 }
 
@@ -164,7 +192,7 @@ func (p *resultsPrinter) PrintResults(matchingNodes *list.List) error {
 			return err
 		}
 
-		if err := p.printNode(mappedDoc.Node, destination); err != nil {
+		if err := p.printNode(mappedDoc, destination); err != nil {
 			return err
 		}
 
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/mikefarah/yq/v4/test"
+	yaml "gopkg.in/yaml.v3"
 )
 
 var multiDocSample = `a: banana
@@ -390,3 +391,35 @@ func TestPrinterNulSeparatorWithJson(t *testing.T) {
 	writer.Flush()
 	test.AssertResult(t, expected, output.String())
 }
+
+func TestFileRoutingPrinter(t *testing.T) {
+	var output1, output2 bytes.Buffer
+	var writer1 = bufio.NewWriter(&output1)
+	var writer2 = bufio.NewWriter(&output2)
+	printer := NewFileRoutingPrinter([]Printer{
+		NewSimpleYamlPrinter(writer1, YamlOutputFormat, true, false, 2, true),
+		NewSimpleYamlPrinter(writer2, YamlOutputFormat, true, false, 2, true),
+	})
+
+	inputs, err := readDocuments(strings.NewReader("a: 1\n---\na: 2\n"), "sample.yml", 0, NewYamlDecoder(ConfiguredYamlPreferences))
+	if err != nil {
+		panic(err)
+	}
+	secondFile, err := readDocuments(strings.NewReader("b: 3\n"), "sample2.yml", 1, NewYamlDecoder(ConfiguredYamlPreferences))
+	if err != nil {
+		panic(err)
+	}
+	inputs.PushBackList(secondFile)
+	// new documents go to the first file
+	inputs.PushBack(&CandidateNode{Node: &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "new"}, FileIndex: -1})
+
+	err = printer.PrintResults(inputs)
+	if err != nil {
+		panic(err)
+	}
+
+	writer1.Flush()
+	writer2.Flush()
+	test.AssertResult(t, "a: 1\n---\na: 2\n---\nnew\n", output1.String())
+	test.AssertResult(t, "b: 3\n", output2.String())
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 
 	"gopkg.in/yaml.v3"
@@ -28,31 +29,70 @@ func (sp *singlePrinterWriter) GetWriter(node *CandidateNode) (*bufio.Writer, er
 	return sp.bufferedWriter, nil
 }
 
+// SplitFilePreferences are the options for writing results into separate files.
+type SplitFilePreferences struct {
+	// Append writes the results that are named the same file into that file one after another,
+	// instead of each one replacing the last.
+	Append bool
+	// NoOverwrite fails instead of replacing files that already exist.
+	NoOverwrite bool
+}
+
+func NewDefaultSplitFilePreferences() SplitFilePreferences {
+	return SplitFilePreferences{
+		Append:      false,
+		NoOverwrite: false,
+	}
+}
+
+var ConfiguredSplitFilePreferences = NewDefaultSplitFilePreferences()
+
 type multiPrintWriter struct {
 	treeNavigator  DataTreeNavigator
 	nameExpression *ExpressionNode
 	extension      string
 	index          int
+	prefs          SplitFilePreferences
+	// the writers of the files written so far, when appending
+	writers map[string]*bufio.Writer
 }
 
-func NewMultiPrinterWriter(expression *ExpressionNode, format PrinterOutputFormat) PrinterWriter {
-	extension := "yml"
-
-	switch format {
-	case JSONOutputFormat:
-		extension = "json"
-	case PropsOutputFormat:
-		extension = "properties"
-	}
-
+func NewMultiPrinterWriter(expression *ExpressionNode, format PrinterOutputFormat, prefs SplitFilePreferences) PrinterWriter {
 	return &multiPrintWriter{
 		nameExpression: expression,
-		extension:      extension,
+		extension:      outputFormatExtension(format),
 		treeNavigator:  NewDataTreeNavigator(),
 		index:          0,
+		prefs:          prefs,
+		writers:        map[string]*bufio.Writer{},
 	}
 }
 
+// outputFormatExtension is the file extension of files in the given format.
+func outputFormatExtension(format PrinterOutputFormat) string {
+	switch format {
+	case JSONOutputFormat, JSONSchemaOutputFormat:
+		return "json"
+	case PropsOutputFormat:
+		return "properties"
+	case CSVOutputFormat:
+		return "csv"
+	case TSVOutputFormat:
+		return "tsv"
+	case XMLOutputFormat:
+		return "xml"
+	case TomlOutputFormat:
+		return "toml"
+	case ShellVariablesOutputFormat:
+		return "sh"
+	case HtmlOutputFormat:
+		return "html"
+	case Base32OutputFormat, HexOutputFormat, GzipOutputFormat, JSONEscapeOutputFormat, TextOutputFormat:
+		return "txt"
+	}
+	return "yml"
+}
+
 func (sp *multiPrintWriter) GetWriter(node *CandidateNode) (*bufio.Writer, error) {
 	name := ""
 
@@ -73,6 +113,24 @@ func (sp *multiPrintWriter) GetWriter(node *CandidateNode) (*bufio.Writer, error
 		name = fmt.Sprintf("%v.%v", name, sp.extension)
 	}
 
+	if writer, exists := sp.writers[name]; exists {
+		log.Debugf("appending to '%v'", name)
+		sp.index = sp.index + 1
+		return writer, nil
+	}
+
+	if sp.prefs.NoOverwrite {
+		if _, err := os.Stat(name); err == nil {
+			return nil, fmt.Errorf("refusing to overwrite '%v', it already exists", name)
+		}
+	}
+
+	if directory := filepath.Dir(name); directory != "." {
+		if err := os.MkdirAll(directory, 0750); err != nil {
+			return nil, err
+		}
+	}
+
 	f, err := os.Create(name)
 
 	if err != nil {
@@ -80,6 +138,10 @@ func (sp *multiPrintWriter) GetWriter(node *CandidateNode) (*bufio.Writer, error
 	}
 	sp.index = sp.index + 1
 
-	return bufio.NewWriter(f), nil
+	writer := bufio.NewWriter(f)
+	if sp.prefs.Append {
+		sp.writers[name] = writer
+	}
+	return writer, nil
 
 }
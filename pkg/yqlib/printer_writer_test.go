@@ -0,0 +1,83 @@
+package yqlib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+func printSplitDocuments(t *testing.T, nameExpression string, format PrinterOutputFormat, prefs SplitFilePreferences, input string) error {
+	expression, err := getExpressionParser().ParseExpression(nameExpression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	documents, err := readDocuments(strings.NewReader(input), "sample.yml", 0, NewYamlDecoder(ConfiguredYamlPreferences))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoder := NewYamlEncoder(2, false, ConfiguredYamlPreferences)
+	if format == JSONOutputFormat {
+		encoder = NewJSONEncoder(2, false, false)
+	}
+	return NewPrinter(encoder, NewMultiPrinterWriter(expression, format, prefs)).PrintResults(documents)
+}
+
+func readTestFile(t *testing.T, filename string) string {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestMultiPrinterWriterCreatesDirectories(t *testing.T) {
+	directory := t.TempDir()
+	nameExpression := fmt.Sprintf(`"%v/" + .kind + "/" + .name`, filepath.ToSlash(directory))
+	err := printSplitDocuments(t, nameExpression, JSONOutputFormat, NewDefaultSplitFilePreferences(), "kind: a\nname: x\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResult(t, "{\n  \"kind\": \"a\",\n  \"name\": \"x\"\n}\n", readTestFile(t, filepath.Join(directory, "a", "x.json")))
+}
+
+func TestMultiPrinterWriterAppend(t *testing.T) {
+	directory := t.TempDir()
+	nameExpression := fmt.Sprintf(`"%v/" + .name`, filepath.ToSlash(directory))
+	prefs := NewDefaultSplitFilePreferences()
+	prefs.Append = true
+	err := printSplitDocuments(t, nameExpression, YamlOutputFormat, prefs, "name: x\nv: 1\n---\nname: y\n---\nname: x\nv: 2\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResult(t, "name: x\nv: 1\n---\nname: x\nv: 2\n", readTestFile(t, filepath.Join(directory, "x.yml")))
+}
+
+func TestMultiPrinterWriterNoOverwrite(t *testing.T) {
+	directory := t.TempDir()
+	filename := filepath.Join(directory, "x.yml")
+	if err := os.WriteFile(filename, []byte("existing\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	nameExpression := fmt.Sprintf(`"%v/" + .name`, filepath.ToSlash(directory))
+	prefs := NewDefaultSplitFilePreferences()
+	prefs.NoOverwrite = true
+	err := printSplitDocuments(t, nameExpression, YamlOutputFormat, prefs, "name: x\n")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	test.AssertResult(t, fmt.Sprintf("refusing to overwrite '%v/x.yml', it already exists", filepath.ToSlash(directory)), err.Error())
+	test.AssertResult(t, "existing\n", readTestFile(t, filename))
+}
+
+func TestOutputFormatExtension(t *testing.T) {
+	test.AssertResult(t, "yml", outputFormatExtension(YamlOutputFormat))
+	test.AssertResult(t, "xml", outputFormatExtension(XMLOutputFormat))
+	test.AssertResult(t, "toml", outputFormatExtension(TomlOutputFormat))
+	test.AssertResult(t, "csv", outputFormatExtension(CSVOutputFormat))
+	test.AssertResult(t, "tsv", outputFormatExtension(TSVOutputFormat))
+	test.AssertResult(t, "sh", outputFormatExtension(ShellVariablesOutputFormat))
+}
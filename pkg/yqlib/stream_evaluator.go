@@ -22,10 +22,26 @@ type StreamEvaluator interface {
 type streamEvaluator struct {
 	treeNavigator DataTreeNavigator
 	fileIndex     int
+
+	// the input being read, and the files to read after it
+	decoder          Decoder
+	filename         string
+	file             *os.File
+	reading          bool
+	pendingFilenames []string
+	documentIndex    uint
+	decodedDocuments uint
 }
 
 func NewStreamEvaluator() StreamEvaluator {
-	return &streamEvaluator{treeNavigator: NewDataTreeNavigator()}
+	return newStreamEvaluator(0)
+}
+
+// newStreamEvaluator creates an evaluator that numbers the files it reads from the given index.
+func newStreamEvaluator(fileIndex int) *streamEvaluator {
+	evaluator := &streamEvaluator{fileIndex: fileIndex}
+	evaluator.treeNavigator = &dataTreeNavigator{inputs: evaluator}
+	return evaluator
 }
 
 func (s *streamEvaluator) EvaluateNew(expression string, printer Printer) error {
@@ -50,28 +66,18 @@ func (s *streamEvaluator) EvaluateNew(expression string, printer Printer) error
 }
 
 func (s *streamEvaluator) EvaluateFiles(expression string, filenames []string, printer Printer, decoder Decoder) error {
-	var totalProcessDocs uint
 	node, err := ExpressionParser.ParseExpression(expression)
 	if err != nil {
 		return err
 	}
 
-	for _, filename := range filenames {
-		reader, err := readStream(filename)
-
-		if err != nil {
-			return err
-		}
-		processedDocs, err := s.Evaluate(filename, reader, node, printer, decoder)
-		if err != nil {
-			return err
-		}
-		totalProcessDocs = totalProcessDocs + processedDocs
-
-		switch reader := reader.(type) {
-		case *os.File:
-			safelyCloseFile(reader)
-		}
+	s.decoder = decoder
+	s.reading = false
+	s.pendingFilenames = filenames
+	totalProcessDocs, err := s.evaluateInputs(node, printer)
+	s.closeFile()
+	if err != nil {
+		return err
 	}
 
 	if totalProcessDocs == 0 {
@@ -83,37 +89,92 @@ func (s *streamEvaluator) EvaluateFiles(expression string, filenames []string, p
 }
 
 func (s *streamEvaluator) Evaluate(filename string, reader io.Reader, node *ExpressionNode, printer Printer, decoder Decoder) (uint, error) {
-
-	var currentIndex uint
-	err := decoder.Init(reader)
-	if err != nil {
+	s.decoder = decoder
+	s.pendingFilenames = nil
+	if err := s.startReading(filename, reader); err != nil {
 		return 0, err
 	}
-	for {
-		candidateNode, errorReading := decoder.Decode()
+	return s.evaluateInputs(node, printer)
+}
 
+// evaluateInputs evaluates the expression against each document in turn, returning how many documents were read.
+// The expression can read the documents after the one being evaluated with the input operators.
+func (s *streamEvaluator) evaluateInputs(node *ExpressionNode, printer Printer) (uint, error) {
+	s.decodedDocuments = 0
+	for {
+		candidateNode, errorReading := s.nextInput()
 		if errors.Is(errorReading, io.EOF) {
-			s.fileIndex = s.fileIndex + 1
-			return currentIndex, nil
+			return s.decodedDocuments, nil
 		} else if errorReading != nil {
-			return currentIndex, fmt.Errorf("bad file '%v': %w", filename, errorReading)
+			return s.decodedDocuments, errorReading
 		}
-		candidateNode.Document = currentIndex
-		candidateNode.Filename = filename
-		candidateNode.FileIndex = s.fileIndex
 
 		inputList := list.New()
 		inputList.PushBack(candidateNode)
 
 		result, errorParsing := s.treeNavigator.GetMatchingNodes(Context{MatchingNodes: inputList}, node)
 		if errorParsing != nil {
-			return currentIndex, errorParsing
+			return s.decodedDocuments, errorParsing
 		}
 		err := printer.PrintResults(result.MatchingNodes)
 
 		if err != nil {
-			return currentIndex, err
+			return s.decodedDocuments, err
 		}
-		currentIndex = currentIndex + 1
+	}
+}
+
+func (s *streamEvaluator) startReading(filename string, reader io.Reader) error {
+	if err := initDecoder(s.decoder, filename, reader); err != nil {
+		return err
+	}
+	s.filename = filename
+	s.reading = true
+	s.documentIndex = 0
+	return nil
+}
+
+// nextInput decodes the next document, from the next file once the current one has been read.
+func (s *streamEvaluator) nextInput() (*CandidateNode, error) {
+	for {
+		if s.reading {
+			candidateNode, errorReading := s.decoder.Decode()
+			if errors.Is(errorReading, io.EOF) {
+				s.reading = false
+				s.fileIndex = s.fileIndex + 1
+				s.closeFile()
+			} else if errorReading != nil {
+				return nil, fmt.Errorf("bad file '%v': %w", s.filename, errorReading)
+			} else {
+				candidateNode.Document = s.documentIndex
+				candidateNode.Filename = s.filename
+				candidateNode.FileIndex = s.fileIndex
+				s.documentIndex = s.documentIndex + 1
+				s.decodedDocuments = s.decodedDocuments + 1
+				return candidateNode, nil
+			}
+		}
+
+		if len(s.pendingFilenames) == 0 {
+			return nil, io.EOF
+		}
+		filename := s.pendingFilenames[0]
+		s.pendingFilenames = s.pendingFilenames[1:]
+		reader, file, err := readStream(filename)
+		if err != nil {
+			return nil, err
+		}
+		s.file = file
+		if err := s.startReading(filename, reader); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// closeFile closes the file that was opened for reading, if there is one.
+func (s *streamEvaluator) closeFile() {
+	if s.file != nil {
+		safelyCloseFile(s.file)
+		s.file = nil
 	}
 }
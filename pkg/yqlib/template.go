@@ -0,0 +1,158 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"io"
+	"os"
+	"text/template"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+type templateRenderer interface {
+	// Render executes the Go text/template in the file, with the values as its data.
+	Render(templateFilename string, writer io.Writer) error
+}
+
+type templateRendererImpl struct {
+	valuesFilename string
+	valuesDecoder  Decoder
+	values         *CandidateNode
+}
+
+// NewTemplateRenderer creates a renderer for Go text/templates. The values file is decoded with
+// the given decoder and used as the template data, if no values file is given the data is null.
+// Templates can evaluate yq expressions against the data with the yq function, e.g. {{ yq ".a.b" }}
+func NewTemplateRenderer(valuesFilename string, valuesDecoder Decoder) templateRenderer {
+	return &templateRendererImpl{valuesFilename: valuesFilename, valuesDecoder: valuesDecoder}
+}
+
+func (r *templateRendererImpl) Render(templateFilename string, writer io.Writer) error {
+	if r.values == nil {
+		r.values = &CandidateNode{Node: &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}}
+		if r.valuesFilename != "" {
+			values, err := loadYaml(r.valuesFilename, r.valuesDecoder)
+			if err != nil {
+				return err
+			}
+			r.values = values
+		}
+	}
+
+	// ignore CWE-22 gosec issue - that's more targeted for http based apps that run in a public directory,
+	// and ensuring that it's not possible to give a path to a file outside that directory.
+	templateBytes, err := os.ReadFile(templateFilename) // #nosec
+	if err != nil {
+		return err
+	}
+	return renderTemplate(templateFilename, string(templateBytes), r.values, writer)
+}
+
+func renderTemplate(name string, text string, data *CandidateNode, writer io.Writer) error {
+	functions := template.FuncMap{
+		"yq": func(expression string, values ...interface{}) (interface{}, error) {
+			return evaluateTemplateExpression(expression, data, values)
+		},
+	}
+	parsed, err := template.New(name).Funcs(functions).Parse(text)
+	if err != nil {
+		return err
+	}
+	return parsed.Execute(writer, nodeToGoValue(data.Node))
+}
+
+// evaluateTemplateExpression evaluates the expression against the template data,
+// or against the given value if there is one, e.g. {{ range .items }}{{ yq ".name" . }}{{ end }}
+func evaluateTemplateExpression(expression string, data *CandidateNode, values []interface{}) (interface{}, error) {
+	if len(values) > 1 {
+		return nil, fmt.Errorf("yq template function takes an expression and at most one value, but was given %v values", len(values))
+	} else if len(values) == 1 {
+		node := &yaml.Node{}
+		if err := node.Encode(values[0]); err != nil {
+			return nil, err
+		}
+		data = &CandidateNode{Node: node}
+	}
+
+	expressionNode, err := ExpressionParser.ParseExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	inputs := list.New()
+	inputs.PushBack(data)
+	context, err := NewDataTreeNavigator().GetMatchingNodes(Context{MatchingNodes: inputs}, expressionNode)
+	if err != nil {
+		return nil, err
+	}
+
+	switch context.MatchingNodes.Len() {
+	case 0:
+		return nil, nil
+	case 1:
+		return nodeToGoValue(context.MatchingNodes.Front().Value.(*CandidateNode).Node), nil
+	}
+	results := make([]interface{}, 0, context.MatchingNodes.Len())
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		results = append(results, nodeToGoValue(el.Value.(*CandidateNode).Node))
+	}
+	return results, nil
+}
+
+// nodeToGoValue converts the node to maps, slices and scalars that templates can work with.
+// Scalars that aren't numbers, booleans or null (like timestamps) are kept as they were written.
+func nodeToGoValue(node *yaml.Node) interface{} {
+	node = resolveMergeAlias(unwrapDoc(node))
+	switch node.Kind {
+	case yaml.MappingNode:
+		value := map[string]interface{}{}
+		for index := 0; index < len(node.Content); index = index + 2 {
+			key := node.Content[index]
+			if key.Tag == "!!merge" {
+				continue
+			}
+			value[key.Value] = nodeToGoValue(node.Content[index+1])
+		}
+		// merged in maps don't override keys that are set explicitly
+		for index := 0; index < len(node.Content); index = index + 2 {
+			if node.Content[index].Tag == "!!merge" {
+				mergeGoMaps(value, node.Content[index+1])
+			}
+		}
+		return value
+	case yaml.SequenceNode:
+		value := make([]interface{}, len(node.Content))
+		for index, item := range node.Content {
+			value[index] = nodeToGoValue(item)
+		}
+		return value
+	}
+
+	switch node.Tag {
+	case "!!null":
+		return nil
+	case "!!bool", "!!int", "!!float":
+		var value interface{}
+		if err := node.Decode(&value); err == nil {
+			return value
+		}
+	}
+	return node.Value
+}
+
+func mergeGoMaps(target map[string]interface{}, merge *yaml.Node) {
+	merge = resolveMergeAlias(merge)
+	sources := []*yaml.Node{merge}
+	if merge.Kind == yaml.SequenceNode {
+		sources = merge.Content
+	}
+	for _, source := range sources {
+		if mergedMap, isMap := nodeToGoValue(source).(map[string]interface{}); isMap {
+			for key, value := range mergedMap {
+				if _, exists := target[key]; !exists {
+					target[key] = value
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package yqlib
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+const conflictPlaceholderPrefix = "__yq_merge_conflict_"
+
+type threeWayMerger interface {
+	// Merge merges the changes made in ours and theirs (relative to base) and prints the result
+	// to writer, returning the paths of any conflicts.
+	Merge(base io.Reader, ours io.Reader, theirs io.Reader, writer io.Writer) ([]string, error)
+}
+
+type mergeConflict struct {
+	placeholder string
+	path        []interface{}
+	// key is set when the conflict is for a map entry, otherwise the conflict replaces a value
+	key    *yaml.Node
+	ours   *yaml.Node
+	theirs *yaml.Node
+}
+
+type threeWayMergerImpl struct {
+	decoder         Decoder
+	encoder         Encoder
+	conflictMarkers bool
+	conflicts       []*mergeConflict
+}
+
+// NewThreeWayMerger creates a node level three way merger. Comments and key order are kept from ours.
+// When conflictMarkers is set, git style conflict markers are written for conflicting paths (this only
+// makes sense for yaml output), otherwise the value from ours is kept.
+func NewThreeWayMerger(decoder Decoder, encoder Encoder, conflictMarkers bool) threeWayMerger {
+	return &threeWayMergerImpl{decoder: decoder, encoder: encoder, conflictMarkers: conflictMarkers}
+}
+
+func (m *threeWayMergerImpl) readDocuments(reader io.Reader, fileIndex int) ([]*CandidateNode, error) {
+	documents, err := readDocuments(bufio.NewReader(reader), "", fileIndex, m.decoder)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]*CandidateNode, 0, documents.Len())
+	for el := documents.Front(); el != nil; el = el.Next() {
+		candidates = append(candidates, el.Value.(*CandidateNode))
+	}
+	return candidates, nil
+}
+
+func documentAt(documents []*CandidateNode, index int) *yaml.Node {
+	if index < len(documents) {
+		return unwrapDoc(documents[index].Node)
+	}
+	return nil
+}
+
+func (m *threeWayMergerImpl) Merge(base io.Reader, ours io.Reader, theirs io.Reader, writer io.Writer) ([]string, error) {
+	m.conflicts = make([]*mergeConflict, 0)
+
+	baseDocs, err := m.readDocuments(base, 0)
+	if err != nil {
+		return nil, err
+	}
+	ourDocs, err := m.readDocuments(ours, 1)
+	if err != nil {
+		return nil, err
+	}
+	theirDocs, err := m.readDocuments(theirs, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	documentCount := len(ourDocs)
+	if len(theirDocs) > documentCount {
+		documentCount = len(theirDocs)
+	}
+
+	results := list.New()
+	for index := 0; index < documentCount; index++ {
+		path := []interface{}{}
+		if documentCount > 1 {
+			path = append(path, index)
+		}
+		merged := m.mergeNodes(path, documentAt(baseDocs, index), documentAt(ourDocs, index), documentAt(theirDocs, index))
+		if merged == nil {
+			continue
+		}
+		var candidate *CandidateNode
+		if index < len(ourDocs) {
+			candidate = ourDocs[index].CreateReplacementWithDocWrappers(merged)
+		} else {
+			candidate = theirDocs[index].CreateReplacementWithDocWrappers(merged)
+		}
+		candidate.Document = uint(results.Len())
+		results.PushBack(candidate)
+	}
+
+	var output bytes.Buffer
+	outputWriter := bufio.NewWriter(&output)
+	if err := NewPrinter(m.encoder, NewSinglePrinterWriter(outputWriter)).PrintResults(results); err != nil {
+		return nil, err
+	}
+
+	text := output.String()
+	if m.conflictMarkers {
+		text, err = m.writeConflictMarkers(text)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := writeString(writer, text); err != nil {
+		return nil, err
+	}
+
+	conflictPaths := make([]string, len(m.conflicts))
+	for i, conflict := range m.conflicts {
+		conflictPaths[i] = mergePathString(conflict.path)
+	}
+	return conflictPaths, nil
+}
+
+func mergePathString(path []interface{}) string {
+	if len(path) == 0 {
+		return "."
+	}
+	elements := make([]string, len(path))
+	for i, element := range path {
+		elements[i] = fmt.Sprintf("%v", element)
+	}
+	return strings.Join(elements, ".")
+}
+
+func mergeNodesEqual(lhs *yaml.Node, rhs *yaml.Node) bool {
+	if lhs == nil || rhs == nil {
+		return lhs == rhs
+	}
+	return recursiveNodeEqual(lhs, rhs)
+}
+
+func resolveMergeAlias(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.AliasNode {
+		return node.Alias
+	}
+	return node
+}
+
+// takeTheirs uses their value, keeping our comments.
+func takeTheirs(ours *yaml.Node, theirs *yaml.Node) *yaml.Node {
+	if theirs == nil {
+		return nil
+	}
+	result := deepClone(theirs)
+	if ours != nil {
+		result.HeadComment = ours.HeadComment
+		result.LineComment = ours.LineComment
+		result.FootComment = ours.FootComment
+	}
+	return result
+}
+
+// conflict records the conflict and returns the node to put in its place.
+func (m *threeWayMergerImpl) conflict(path []interface{}, ours *yaml.Node, theirs *yaml.Node) *yaml.Node {
+	if !m.conflictMarkers {
+		m.conflicts = append(m.conflicts, &mergeConflict{path: path, ours: ours, theirs: theirs})
+		return ours
+	}
+	placeholder := fmt.Sprintf("%v%v__", conflictPlaceholderPrefix, len(m.conflicts))
+	m.conflicts = append(m.conflicts, &mergeConflict{placeholder: placeholder, path: path, ours: ours, theirs: theirs})
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: placeholder}
+}
+
+// mergeNodes returns the merged node, or nil if the node was deleted.
+// base, ours or theirs are nil when the node does not exist in that version.
+func (m *threeWayMergerImpl) mergeNodes(path []interface{}, base *yaml.Node, ours *yaml.Node, theirs *yaml.Node) *yaml.Node {
+	base = resolveMergeAlias(base)
+	ours = resolveMergeAlias(ours)
+	theirs = resolveMergeAlias(theirs)
+
+	if mergeNodesEqual(ours, theirs) {
+		return ours
+	} else if ours == nil || theirs == nil || ours.Kind != theirs.Kind || (base != nil && base.Kind != ours.Kind) ||
+		(ours.Kind != yaml.MappingNode && ours.Kind != yaml.SequenceNode) {
+		return m.mergeValues(path, base, ours, theirs)
+	}
+
+	// collections are merged even when one side is unchanged, so that our comments are kept.
+	conflictsBefore := len(m.conflicts)
+	var merged *yaml.Node
+	if ours.Kind == yaml.MappingNode {
+		merged = m.mergeMaps(path, base, ours, theirs)
+	} else {
+		merged = m.mergeSequences(path, base, ours, theirs)
+	}
+
+	// conflict markers can't be written inside flow style collections,
+	// so the whole collection becomes the conflict
+	if m.conflictMarkers && ours.Style&yaml.FlowStyle != 0 && len(m.conflicts) > conflictsBefore {
+		m.conflicts = m.conflicts[:conflictsBefore]
+		return m.conflict(path, ours, theirs)
+	}
+	return merged
+}
+
+// mergeValues takes the side that changed, or conflicts if both did.
+func (m *threeWayMergerImpl) mergeValues(path []interface{}, base *yaml.Node, ours *yaml.Node, theirs *yaml.Node) *yaml.Node {
+	if mergeNodesEqual(base, ours) {
+		return takeTheirs(ours, theirs)
+	} else if mergeNodesEqual(base, theirs) {
+		return ours
+	}
+	return m.conflict(path, ours, theirs)
+}
+
+func mergeMapValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	index := findJSONPatchKey(node, key)
+	if index == -1 {
+		return nil
+	}
+	return node.Content[index+1]
+}
+
+func (m *threeWayMergerImpl) mergeMaps(path []interface{}, base *yaml.Node, ours *yaml.Node, theirs *yaml.Node) *yaml.Node {
+	merged := deepCloneNoContent(ours)
+	merged.Content = make([]*yaml.Node, 0, len(ours.Content))
+
+	addEntry := func(key *yaml.Node, value *yaml.Node) {
+		if value == nil {
+			return
+		}
+		if m.conflictMarkers && len(m.conflicts) > 0 && value.Value == m.conflicts[len(m.conflicts)-1].placeholder {
+			// conflicts in map values replace the whole entry
+			conflict := m.conflicts[len(m.conflicts)-1]
+			conflict.key = key
+			merged.Content = append(merged.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: conflict.placeholder}, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"})
+			return
+		}
+		merged.Content = append(merged.Content, deepClone(key), value)
+	}
+
+	for index := 0; index < len(ours.Content); index = index + 2 {
+		key := ours.Content[index]
+		value := m.mergeNodes(appendDiffPath(path, key.Value), mergeMapValue(base, key.Value), ours.Content[index+1], mergeMapValue(theirs, key.Value))
+		addEntry(key, value)
+	}
+	for index := 0; index < len(theirs.Content); index = index + 2 {
+		key := theirs.Content[index]
+		if findJSONPatchKey(ours, key.Value) != -1 {
+			continue
+		}
+		value := m.mergeNodes(appendDiffPath(path, key.Value), mergeMapValue(base, key.Value), nil, theirs.Content[index+1])
+		addEntry(key, value)
+	}
+	return merged
+}
+
+func (m *threeWayMergerImpl) mergeSequences(path []interface{}, base *yaml.Node, ours *yaml.Node, theirs *yaml.Node) *yaml.Node {
+	if base != nil && len(base.Content) == len(ours.Content) && len(base.Content) == len(theirs.Content) {
+		merged := deepCloneNoContent(ours)
+		merged.Content = make([]*yaml.Node, len(ours.Content))
+		for index := range ours.Content {
+			merged.Content[index] = m.mergeNodes(appendDiffPath(path, index), base.Content[index], ours.Content[index], theirs.Content[index])
+		}
+		return merged
+	}
+
+	if mergeNodesEqual(base, ours) || mergeNodesEqual(base, theirs) {
+		return m.mergeValues(path, base, ours, theirs)
+	}
+
+	baseLength := 0
+	if base != nil {
+		baseLength = len(base.Content)
+	}
+	if baseLength <= len(ours.Content) && baseLength <= len(theirs.Content) &&
+		isMergePrefix(base, ours) && isMergePrefix(base, theirs) {
+		// both sides appended items, add theirs after ours.
+		merged := deepClone(ours)
+		ourAdditions := ours.Content[baseLength:]
+		for _, item := range theirs.Content[baseLength:] {
+			alreadyAdded := false
+			for _, ourItem := range ourAdditions {
+				alreadyAdded = alreadyAdded || recursiveNodeEqual(ourItem, item)
+			}
+			if !alreadyAdded {
+				merged.Content = append(merged.Content, deepClone(item))
+			}
+		}
+		return merged
+	}
+
+	return m.conflict(path, ours, theirs)
+}
+
+func isMergePrefix(prefix *yaml.Node, node *yaml.Node) bool {
+	if prefix == nil {
+		return true
+	}
+	for index, item := range prefix.Content {
+		if !recursiveNodeEqual(item, node.Content[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeConflictMarkers replaces the conflict placeholders in the encoded text
+// with the conflicting versions, surrounded by git style conflict markers.
+func (m *threeWayMergerImpl) writeConflictMarkers(text string) (string, error) {
+	lines := strings.SplitAfter(text, "\n")
+	var result strings.Builder
+	for _, line := range lines {
+		column := strings.Index(line, conflictPlaceholderPrefix)
+		if column == -1 {
+			result.WriteString(line)
+			continue
+		}
+		conflict := m.findConflict(line[column:])
+		if conflict == nil {
+			result.WriteString(line)
+			continue
+		}
+		prefix := line[:column]
+		ours, err := m.conflictSide(prefix, conflict.key, conflict.ours)
+		if err != nil {
+			return "", err
+		}
+		theirs, err := m.conflictSide(prefix, conflict.key, conflict.theirs)
+		if err != nil {
+			return "", err
+		}
+		result.WriteString("<<<<<<< ours\n")
+		result.WriteString(ours)
+		result.WriteString("=======\n")
+		result.WriteString(theirs)
+		result.WriteString(">>>>>>> theirs\n")
+	}
+	return result.String(), nil
+}
+
+func (m *threeWayMergerImpl) findConflict(text string) *mergeConflict {
+	for _, conflict := range m.conflicts {
+		if strings.HasPrefix(text, conflict.placeholder) {
+			return conflict
+		}
+	}
+	return nil
+}
+
+// conflictSide encodes one side of the conflict, indented to line up with the line the placeholder was on.
+func (m *threeWayMergerImpl) conflictSide(prefix string, key *yaml.Node, value *yaml.Node) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	node := value
+	if key != nil {
+		node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{key, value}}
+	}
+	var encoded bytes.Buffer
+	if err := m.encoder.Encode(&encoded, node); err != nil {
+		return "", err
+	}
+	indent := strings.Repeat(" ", len(prefix))
+	var result strings.Builder
+	for i, line := range strings.SplitAfter(strings.TrimSuffix(encoded.String(), "\n"), "\n") {
+		if i == 0 {
+			result.WriteString(prefix)
+		} else {
+			result.WriteString(indent)
+		}
+		result.WriteString(line)
+	}
+	result.WriteString("\n")
+	return result.String(), nil
+}
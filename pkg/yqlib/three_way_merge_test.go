@@ -0,0 +1,125 @@
+package yqlib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+type threeWayMergeScenario struct {
+	description       string
+	base              string
+	ours              string
+	theirs            string
+	expected          string
+	expectedConflicts []string
+}
+
+var threeWayMergeScenarios = []threeWayMergeScenario{
+	{
+		description: "changes to different keys are merged, keeping our comments and order",
+		base:        "a: 1\nb: 2\nc: 3\n",
+		ours:        "# config\nc: 3\na: 10 # changed\nb: 2\n",
+		theirs:      "a: 1\nb: 20\nc: 3\nd: 4\n",
+		expected:    "# config\nc: 3\na: 10 # changed\nb: 20\nd: 4\n",
+	},
+	{
+		description: "their change keeps our comment",
+		base:        "a: 1\n",
+		ours:        "a: 1 # the a\n",
+		theirs:      "a: 2\n",
+		expected:    "a: 2 # the a\n",
+	},
+	{
+		description: "the same change on both sides is not a conflict",
+		base:        "a: 1\n",
+		ours:        "a: 2\n",
+		theirs:      "a: 2\n",
+		expected:    "a: 2\n",
+	},
+	{
+		description: "deletions are merged",
+		base:        "a: 1\nb: 2\nc: 3\n",
+		ours:        "a: 1\nc: 3\n",
+		theirs:      "a: 1\nb: 2\n",
+		expected:    "a: 1\n",
+	},
+	{
+		description: "items appended on both sides are merged",
+		base:        "- a\n- b\n",
+		ours:        "- a\n- b\n- c\n",
+		theirs:      "- a\n- b\n- d\n- c\n",
+		expected:    "- a\n- b\n- c\n- d\n",
+	},
+	{
+		description:       "conflicting scalar values",
+		base:              "a:\n  b: 1\n  c: 1\n",
+		ours:              "a:\n  b: 2\n  c: 1\n",
+		theirs:            "a:\n  b: 3\n  c: 2\n",
+		expected:          "a:\n<<<<<<< ours\n  b: 2\n=======\n  b: 3\n>>>>>>> theirs\n  c: 2\n",
+		expectedConflicts: []string{"a.b"},
+	},
+	{
+		description:       "delete and modify conflict",
+		base:              "a: 1\nb: 1\n",
+		ours:              "b: 1\n",
+		theirs:            "a: 2\nb: 1\n",
+		expected:          "b: 1\n<<<<<<< ours\n=======\na: 2\n>>>>>>> theirs\n",
+		expectedConflicts: []string{"a"},
+	},
+	{
+		description:       "conflicting map values",
+		base:              "a: 1\n",
+		ours:              "a:\n  b: 1\n",
+		theirs:            "a:\n  - x\n",
+		expected:          "<<<<<<< ours\na:\n  b: 1\n=======\na:\n  - x\n>>>>>>> theirs\n",
+		expectedConflicts: []string{"a"},
+	},
+	{
+		description:       "conflicts inside sequences",
+		base:              "- name: a\n  value: 1\n",
+		ours:              "- name: a\n  value: 2\n",
+		theirs:            "- name: a\n  value: 3\n",
+		expected:          "- name: a\n<<<<<<< ours\n  value: 2\n=======\n  value: 3\n>>>>>>> theirs\n",
+		expectedConflicts: []string{"0.value"},
+	},
+	{
+		description:       "conflicts inside flow collections conflict the whole collection",
+		base:              "a: [1, 2]\n",
+		ours:              "a: [1, 3]\n",
+		theirs:            "a: [1, 4]\n",
+		expected:          "<<<<<<< ours\na: [1, 3]\n=======\na: [1, 4]\n>>>>>>> theirs\n",
+		expectedConflicts: []string{"a"},
+	},
+}
+
+func testThreeWayMergeScenario(t *testing.T, s threeWayMergeScenario) {
+	merger := NewThreeWayMerger(NewYamlDecoder(ConfiguredYamlPreferences), NewYamlEncoder(2, false, ConfiguredYamlPreferences), true)
+	var output bytes.Buffer
+	conflicts, err := merger.Merge(strings.NewReader(s.base), strings.NewReader(s.ours), strings.NewReader(s.theirs), &output)
+	if err != nil {
+		t.Error(s.description, err)
+		return
+	}
+	test.AssertResultWithContext(t, s.expected, output.String(), s.description)
+	test.AssertResultWithContext(t, strings.Join(s.expectedConflicts, ","), strings.Join(conflicts, ","), s.description)
+}
+
+func TestThreeWayMergeScenarios(t *testing.T) {
+	for _, s := range threeWayMergeScenarios {
+		testThreeWayMergeScenario(t, s)
+	}
+}
+
+func TestThreeWayMergeWithoutConflictMarkersKeepsOurs(t *testing.T) {
+	merger := NewThreeWayMerger(NewYamlDecoder(ConfiguredYamlPreferences), NewYamlEncoder(2, false, ConfiguredYamlPreferences), false)
+	var output bytes.Buffer
+	conflicts, err := merger.Merge(strings.NewReader("a: 1\nb: 1\n"), strings.NewReader("a: 2\nb: 1\n"), strings.NewReader("a: 3\nb: 2\n"), &output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResult(t, "a: 2\nb: 2\n", output.String())
+	test.AssertResult(t, "a", strings.Join(conflicts, ","))
+}
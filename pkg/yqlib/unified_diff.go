@@ -0,0 +1,233 @@
+package yqlib
+
+import (
+	"fmt"
+	"strings"
+)
+
+const unifiedDiffContext = 3
+
+type lineEditKind int
+
+const (
+	lineEqual lineEditKind = iota
+	lineDelete
+	lineInsert
+)
+
+type lineEdit struct {
+	kind lineEditKind
+	line string
+}
+
+// unifiedDiff returns the changes from the original text to the updated text in the unified diff format,
+// or an empty string when they are the same.
+func unifiedDiff(originalName string, updatedName string, original string, updated string) string {
+	edits := diffLines(splitLines(original), splitLines(updated))
+
+	var sb strings.Builder
+	originalLine, updatedLine := 1, 1
+	for start := 0; start < len(edits); {
+		if edits[start].kind == lineEqual {
+			originalLine++
+			updatedLine++
+			start++
+			continue
+		}
+		// a hunk includes the changes that are within twice the context of each other
+		hunkStart := start - unifiedDiffContext
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		hunkEnd := start
+		for index := start; index < len(edits) && index-hunkEnd <= 2*unifiedDiffContext; index++ {
+			if edits[index].kind != lineEqual {
+				hunkEnd = index + 1
+			}
+		}
+		hunkEnd = hunkEnd + unifiedDiffContext
+		if hunkEnd > len(edits) {
+			hunkEnd = len(edits)
+		}
+
+		leading := start - hunkStart
+		originalStart, updatedStart := originalLine-leading, updatedLine-leading
+		originalCount, updatedCount := 0, 0
+		var hunk strings.Builder
+		for _, edit := range edits[hunkStart:hunkEnd] {
+			switch edit.kind {
+			case lineEqual:
+				writeDiffLine(&hunk, " ", edit.line)
+				originalCount++
+				updatedCount++
+			case lineDelete:
+				writeDiffLine(&hunk, "-", edit.line)
+				originalCount++
+			case lineInsert:
+				writeDiffLine(&hunk, "+", edit.line)
+				updatedCount++
+			}
+		}
+
+		if sb.Len() == 0 {
+			sb.WriteString(fmt.Sprintf("--- %v\n+++ %v\n", originalName, updatedName))
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%v +%v @@\n", hunkRange(originalStart, originalCount), hunkRange(updatedStart, updatedCount)))
+		sb.WriteString(hunk.String())
+
+		originalLine = originalStart + originalCount
+		updatedLine = updatedStart + updatedCount
+		start = hunkEnd
+	}
+	return sb.String()
+}
+
+func hunkRange(start int, count int) string {
+	switch count {
+	case 0:
+		// empty ranges refer to the line before them
+		return fmt.Sprintf("%v,0", start-1)
+	case 1:
+		return fmt.Sprintf("%v", start)
+	}
+	return fmt.Sprintf("%v,%v", start, count)
+}
+
+func writeDiffLine(sb *strings.Builder, prefix string, line string) {
+	sb.WriteString(prefix)
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// splitLines splits the text into lines, keeping their line endings.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines finds the shortest edit script from the original to the updated lines, using the linear space
+// variant of Myers' algorithm, so that large rewrites don't need memory for every step of the search.
+func diffLines(original []string, updated []string) []lineEdit {
+	return appendLineDiff(make([]lineEdit, 0, len(original)+len(updated)), original, updated)
+}
+
+func appendLineDiff(edits []lineEdit, original []string, updated []string) []lineEdit {
+	// the common start and end are kept out of the search, as that is usually most of the file
+	prefix := 0
+	for prefix < len(original) && prefix < len(updated) && original[prefix] == updated[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(original)-prefix && suffix < len(updated)-prefix &&
+		original[len(original)-1-suffix] == updated[len(updated)-1-suffix] {
+		suffix++
+	}
+
+	for _, line := range original[:prefix] {
+		edits = append(edits, lineEdit{lineEqual, line})
+	}
+	changedOriginal := original[prefix : len(original)-suffix]
+	changedUpdated := updated[prefix : len(updated)-suffix]
+	if x, y, found := middleSnake(changedOriginal, changedUpdated); found {
+		edits = appendLineDiff(edits, changedOriginal[:x], changedUpdated[:y])
+		edits = appendLineDiff(edits, changedOriginal[x:], changedUpdated[y:])
+	} else {
+		for _, line := range changedOriginal {
+			edits = append(edits, lineEdit{lineDelete, line})
+		}
+		for _, line := range changedUpdated {
+			edits = append(edits, lineEdit{lineInsert, line})
+		}
+	}
+	for _, line := range original[len(original)-suffix:] {
+		edits = append(edits, lineEdit{lineEqual, line})
+	}
+	return edits
+}
+
+// middleSnake searches for the shortest edit script from both ends at once, returning where the paths meet
+// to split the problem in two. There's nothing to split when either side is empty, or they have no lines in
+// common.
+func middleSnake(original []string, updated []string) (int, int, bool) {
+	n, m := len(original), len(updated)
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// the furthest x reached on each diagonal, from the start and from the end
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for index := range forward {
+		forward[index] = -1
+		backward[index] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+	delta := n - m
+	// when the difference in length is odd the paths meet while extending forwards
+	front := delta%2 != 0
+	// the diagonals that went past the end of either side aren't searched again
+	forwardStart, forwardEnd, backwardStart, backwardEnd := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + forwardStart; k <= d-forwardEnd; k = k + 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && original[x] == updated[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			if x > n {
+				forwardEnd = forwardEnd + 2
+			} else if y > m {
+				forwardStart = forwardStart + 2
+			} else if front {
+				backwardIndex := offset + delta - k
+				if backwardIndex >= 0 && backwardIndex < len(backward) && backward[backwardIndex] != -1 && x >= n-backward[backwardIndex] {
+					return x, y, true
+				}
+			}
+		}
+
+		for k := -d + backwardStart; k <= d-backwardEnd; k = k + 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && original[n-x-1] == updated[m-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			if x > n {
+				backwardEnd = backwardEnd + 2
+			} else if y > m {
+				backwardStart = backwardStart + 2
+			} else if !front {
+				forwardIndex := offset + delta - k
+				if forwardIndex >= 0 && forwardIndex < len(forward) && forward[forwardIndex] != -1 {
+					forwardX := forward[forwardIndex]
+					if forwardX >= n-x {
+						return forwardX, forwardX - (forwardIndex - offset), true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
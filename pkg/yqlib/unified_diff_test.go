@@ -0,0 +1,138 @@
+package yqlib
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+type unifiedDiffScenario struct {
+	description string
+	original    string
+	updated     string
+	expected    string
+}
+
+var unifiedDiffScenarios = []unifiedDiffScenario{
+	{
+		description: "no changes",
+		original:    "a: 1\n",
+		updated:     "a: 1\n",
+		expected:    "",
+	},
+	{
+		description: "changed line",
+		original:    "a: 1\nb: 2\nc: 3\n",
+		updated:     "a: 1\nb: 5\nc: 3\n",
+		expected:    "--- f.yml\n+++ f.yml\n@@ -1,3 +1,3 @@\n a: 1\n-b: 2\n+b: 5\n c: 3\n",
+	},
+	{
+		description: "separate hunks",
+		original:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+		updated:     "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n11\n12\n",
+		expected:    "--- f.yml\n+++ f.yml\n@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n@@ -7,6 +8,5 @@\n 7\n 8\n 9\n-10\n 11\n 12\n",
+	},
+	{
+		description: "close changes share a hunk",
+		original:    "1\n2\n3\n4\n5\n6\n7\n8\n",
+		updated:     "1\nx\n3\n4\n5\n6\n7\ny\n",
+		expected:    "--- f.yml\n+++ f.yml\n@@ -1,8 +1,8 @@\n 1\n-2\n+x\n 3\n 4\n 5\n 6\n 7\n-8\n+y\n",
+	},
+	{
+		description: "no newline at the end",
+		original:    "a: 1\nb: 2",
+		updated:     "a: 1\nb: 2\n",
+		expected:    "--- f.yml\n+++ f.yml\n@@ -1,2 +1,2 @@\n a: 1\n-b: 2\n\\ No newline at end of file\n+b: 2\n",
+	},
+	{
+		description: "from an empty file",
+		original:    "",
+		updated:     "a: 1\n",
+		expected:    "--- f.yml\n+++ f.yml\n@@ -0,0 +1 @@\n+a: 1\n",
+	},
+}
+
+func TestUnifiedDiffScenarios(t *testing.T) {
+	for _, s := range unifiedDiffScenarios {
+		test.AssertResultWithContext(t, s.expected, unifiedDiff("f.yml", "f.yml", s.original, s.updated), s.description)
+	}
+}
+
+// checkLineDiff checks that the edits turn the original lines into the updated ones, and that
+// there are no more of them than the lines outside the longest common subsequence.
+func checkLineDiff(t *testing.T, original []string, updated []string, description string) {
+	edits := diffLines(original, updated)
+	var fromEdits, toEdits []string
+	changes := 0
+	for _, edit := range edits {
+		if edit.kind != lineInsert {
+			fromEdits = append(fromEdits, edit.line)
+		}
+		if edit.kind != lineDelete {
+			toEdits = append(toEdits, edit.line)
+		}
+		if edit.kind != lineEqual {
+			changes++
+		}
+	}
+	test.AssertResultWithContext(t, strings.Join(original, ""), strings.Join(fromEdits, ""), description)
+	test.AssertResultWithContext(t, strings.Join(updated, ""), strings.Join(toEdits, ""), description)
+
+	common := make([][]int, len(original)+1)
+	for i := range common {
+		common[i] = make([]int, len(updated)+1)
+	}
+	for i := len(original) - 1; i >= 0; i-- {
+		for j := len(updated) - 1; j >= 0; j-- {
+			if original[i] == updated[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] > common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+	test.AssertResultWithContext(t, len(original)+len(updated)-2*common[0][0], changes, description)
+}
+
+func TestDiffLinesIsShortest(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, random.Intn(20))
+		for index := range lines {
+			lines[index] = fmt.Sprintf("%v\n", random.Intn(4))
+		}
+		return lines
+	}
+	for index := 0; index < 500; index++ {
+		checkLineDiff(t, randomLines(), randomLines(), fmt.Sprintf("random lines %v", index))
+	}
+}
+
+func TestUnifiedDiffOfLargeRewrite(t *testing.T) {
+	var items []string
+	var lines []string
+	for index := 0; index < 20000; index++ {
+		items = append(items, fmt.Sprintf(`{"id":%v}`, index))
+		lines = append(lines, fmt.Sprintf("  - id: %v\n", index))
+	}
+	original := "[" + strings.Join(items, ",") + "]\n"
+	updated := "items:\n" + strings.Join(lines, "")
+
+	diff := unifiedDiff("f.json", "f.json", original, updated)
+	test.AssertResult(t, "--- f.json\n+++ f.json\n@@ -1 +1,20001 @@\n-"+original+"+items:\n", diff[:len("--- f.json\n+++ f.json\n@@ -1 +1,20001 @@\n-"+original+"+items:\n")])
+	test.AssertResult(t, 20000, strings.Count(diff, "\n+  - id: "))
+
+	// the worst case, where all but one line is deleted and inserted again
+	var reversed []string
+	for index := 5000 - 1; index >= 0; index-- {
+		reversed = append(reversed, lines[index])
+	}
+	diff = unifiedDiff("f.yml", "f.yml", strings.Join(lines[:5000], ""), strings.Join(reversed, ""))
+	test.AssertResult(t, 4999, strings.Count(diff, "\n-  - id: "))
+	test.AssertResult(t, 4999, strings.Count(diff, "\n+  - id: "))
+}
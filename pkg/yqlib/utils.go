@@ -9,21 +9,18 @@ import (
 	"os"
 )
 
-func readStream(filename string) (io.Reader, error) {
-	var reader *bufio.Reader
+// readStream opens the file, or stdin for "-". The file needs to be closed once it's been read, it's nil for stdin.
+func readStream(filename string) (io.Reader, *os.File, error) {
 	if filename == "-" {
-		reader = bufio.NewReader(os.Stdin)
-	} else {
-		// ignore CWE-22 gosec issue - that's more targeted for http based apps that run in a public directory,
-		// and ensuring that it's not possible to give a path to a file outside that directory.
-		file, err := os.Open(filename) // #nosec
-		if err != nil {
-			return nil, err
-		}
-		reader = bufio.NewReader(file)
+		return stdinStream(), nil, nil
 	}
-	return reader, nil
-
+	// ignore CWE-22 gosec issue - that's more targeted for http based apps that run in a public directory,
+	// and ensuring that it's not possible to give a path to a file outside that directory.
+	file, err := os.Open(filename) // #nosec
+	if err != nil {
+		return nil, nil, err
+	}
+	return bufio.NewReader(file), file, nil
 }
 
 func writeString(writer io.Writer, txt string) error {
@@ -32,7 +29,7 @@ func writeString(writer io.Writer, txt string) error {
 }
 
 func readDocuments(reader io.Reader, filename string, fileIndex int, decoder Decoder) (*list.List, error) {
-	err := decoder.Init(reader)
+	err := initDecoder(decoder, filename, reader)
 	if err != nil {
 		return nil, err
 	}
@@ -43,10 +40,6 @@ func readDocuments(reader io.Reader, filename string, fileIndex int, decoder Dec
 		candidateNode, errorReading := decoder.Decode()
 
 		if errors.Is(errorReading, io.EOF) {
-			switch reader := reader.(type) {
-			case *os.File:
-				safelyCloseFile(reader)
-			}
 			return inputList, nil
 		} else if errorReading != nil {
 			return nil, fmt.Errorf("bad file '%v': %w", filename, errorReading)
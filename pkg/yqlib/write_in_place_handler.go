@@ -1,22 +1,44 @@
 package yqlib
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 )
 
 type writeInPlaceHandler interface {
 	CreateTempFile() (*os.File, error)
 	FinishWriteInPlace(evaluatedSuccessfully bool) error
+	// SetBackupSuffix keeps a copy of the original file, named with the suffix added, when it's updated.
+	SetBackupSuffix(suffix string)
+	// SetDryRun leaves the file as it is, printing a unified diff of the changes to the writer instead.
+	SetDryRun(diffWriter io.Writer)
+	// Changed checks if the file was (or in a dry run, would have been) changed, once finished.
+	Changed() bool
 }
 
 type writeInPlaceHandlerImpl struct {
 	inputFilename string
 	tempFile      *os.File
+	minimalDiff   bool
+	indent        int
+	backupSuffix  string
+	diffWriter    io.Writer
+	changed       bool
 }
 
 func NewWriteInPlaceHandler(inputFile string) writeInPlaceHandler {
 
-	return &writeInPlaceHandlerImpl{inputFile, nil}
+	return &writeInPlaceHandlerImpl{inputFilename: inputFile}
+}
+
+// NewMinimalDiffWriteInPlaceHandler creates a handler for yaml files that only rewrites the
+// nodes that were changed, leaving the rest of the file as it was. If the changes can't be
+// spliced into the original file, the whole file is rewritten.
+func NewMinimalDiffWriteInPlaceHandler(inputFile string, indent int) writeInPlaceHandler {
+	return &writeInPlaceHandlerImpl{inputFilename: inputFile, minimalDiff: true, indent: indent}
 }
 
 func (w *writeInPlaceHandlerImpl) CreateTempFile() (*os.File, error) {
@@ -43,14 +65,97 @@ func (w *writeInPlaceHandlerImpl) CreateTempFile() (*os.File, error) {
 	return file, err
 }
 
+func (w *writeInPlaceHandlerImpl) SetBackupSuffix(suffix string) {
+	w.backupSuffix = suffix
+}
+
+func (w *writeInPlaceHandlerImpl) SetDryRun(diffWriter io.Writer) {
+	w.diffWriter = diffWriter
+}
+
+func (w *writeInPlaceHandlerImpl) Changed() bool {
+	return w.changed
+}
+
 func (w *writeInPlaceHandlerImpl) FinishWriteInPlace(evaluatedSuccessfully bool) error {
 	log.Debug("Going to write-inplace, evaluatedSuccessfully=%v, target=%v", evaluatedSuccessfully, w.inputFilename)
 	safelyCloseFile(w.tempFile)
-	if evaluatedSuccessfully {
-		log.Debug("Moving temp file to target")
-		return tryRenameFile(w.tempFile.Name(), w.inputFilename)
+	if !evaluatedSuccessfully {
+		tryRemoveTempFile(w.tempFile.Name())
+		return nil
+	}
+	err := w.writeChanges()
+	if err != nil || w.diffWriter != nil {
+		tryRemoveTempFile(w.tempFile.Name())
+	}
+	return err
+}
+
+func (w *writeInPlaceHandlerImpl) writeChanges() error {
+	if w.minimalDiff {
+		if err := w.spliceChanges(); err != nil {
+			return err
+		}
+	}
+
+	original, err := os.ReadFile(w.inputFilename)
+	if err != nil {
+		return err
+	}
+	updated, err := os.ReadFile(w.tempFile.Name())
+	if err != nil {
+		return err
 	}
-	tryRemoveTempFile(w.tempFile.Name())
+	w.changed = !bytes.Equal(original, updated)
 
-	return nil
+	if w.diffWriter != nil {
+		log.Debug("Dry run, not updating %v", w.inputFilename)
+		if !w.changed {
+			return nil
+		}
+		return writeString(w.diffWriter, unifiedDiff(w.inputFilename, w.inputFilename, string(original), string(updated)))
+	}
+
+	if w.backupSuffix != "" {
+		if err := w.writeBackup(original); err != nil {
+			return err
+		}
+	}
+	log.Debug("Moving temp file to target")
+	return tryRenameFile(w.tempFile.Name(), w.inputFilename)
+}
+
+func (w *writeInPlaceHandlerImpl) writeBackup(original []byte) error {
+	info, err := os.Stat(w.inputFilename)
+	if err != nil {
+		return err
+	}
+	backupFilename := w.inputFilename + w.backupSuffix
+	log.Debug("WriteInPlaceHandler: backing up %v to %v", w.inputFilename, backupFilename)
+	if err := os.WriteFile(backupFilename, original, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("could not back up %v: %w", w.inputFilename, err)
+	}
+	// the file may have already existed with other permissions
+	return os.Chmod(backupFilename, info.Mode().Perm())
+}
+
+// spliceChanges replaces the temp file with the original file, updated with only the changed nodes.
+func (w *writeInPlaceHandlerImpl) spliceChanges() error {
+	original, err := os.ReadFile(w.inputFilename)
+	if err != nil {
+		return err
+	}
+	updated, err := os.ReadFile(w.tempFile.Name())
+	if err != nil {
+		return err
+	}
+	spliced, err := minimalDiff(original, updated, w.indent)
+	if errors.Is(err, errMinimalDiffUnsupported) {
+		log.Warningf("could not splice the changes into %v with --minimal-diff, rewriting the whole file", w.inputFilename)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	// the temp file already exists with the right permissions
+	return os.WriteFile(w.tempFile.Name(), spliced, 0600)
 }
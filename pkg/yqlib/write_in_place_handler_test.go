@@ -0,0 +1,70 @@
+package yqlib
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+func writeInPlace(t *testing.T, handler writeInPlaceHandler, content string) {
+	out, err := handler.CreateTempFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := out.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := handler.FinishWriteInPlace(true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteInPlaceBackup(t *testing.T) {
+	file := createTestFile("a: 1\n")
+
+	handler := NewWriteInPlaceHandler(file)
+	handler.SetBackupSuffix(".bak")
+	writeInPlace(t, handler, "a: 2\n")
+
+	test.AssertResult(t, "a: 2\n", readFile(file))
+	test.AssertResult(t, "a: 1\n", readFile(file+".bak"))
+	test.AssertResult(t, true, handler.Changed())
+
+	tryRemoveTempFile(file)
+	tryRemoveTempFile(file + ".bak")
+}
+
+func TestWriteInPlaceDryRun(t *testing.T) {
+	file := createTestFile("a: 1\nb: 2\n")
+
+	var diff bytes.Buffer
+	handler := NewWriteInPlaceHandler(file)
+	handler.SetDryRun(&diff)
+	writeInPlace(t, handler, "a: 1\nb: 3\n")
+
+	test.AssertResult(t, "a: 1\nb: 2\n", readFile(file))
+	test.AssertResult(t, "--- "+file+"\n+++ "+file+"\n@@ -1,2 +1,2 @@\n a: 1\n-b: 2\n+b: 3\n", diff.String())
+	test.AssertResult(t, true, handler.Changed())
+
+	tryRemoveTempFile(file)
+}
+
+func TestWriteInPlaceDryRunUnchanged(t *testing.T) {
+	file := createTestFile("a: 1\n")
+
+	var diff bytes.Buffer
+	handler := NewWriteInPlaceHandler(file)
+	handler.SetDryRun(&diff)
+	handler.SetBackupSuffix(".bak")
+	writeInPlace(t, handler, "a: 1\n")
+
+	test.AssertResult(t, "", diff.String())
+	test.AssertResult(t, false, handler.Changed())
+	if _, err := os.Stat(file + ".bak"); !os.IsNotExist(err) {
+		t.Error("dry runs should not back up the file")
+	}
+
+	tryRemoveTempFile(file)
+}
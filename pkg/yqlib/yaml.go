@@ -5,6 +5,19 @@ type YamlPreferences struct {
 	PrintDocSeparators          bool
 	UnwrapScalar                bool
 	EvaluateTogether            bool
+	// PreserveFormatting keeps blank lines between entries and the original sequence indentation
+	// style (indented or indentless '- ' under keys) when round tripping yaml.
+	PreserveFormatting bool
+	// LineWidth wraps long strings onto several lines at their spaces to keep lines within the width, 0 doesn't wrap.
+	LineWidth int
+	// IndentlessSequences writes block sequences under map keys without indenting their '- '.
+	IndentlessSequences bool
+	// QuoteStyle is the style of string values: single, double or minimal quotes. Empty keeps the style of each string.
+	QuoteStyle string
+	// LiteralMultilineStrings writes strings spanning several lines as literal blocks.
+	LiteralMultilineStrings bool
+	// FlowThreshold writes collections of at most this many scalars in flow style, 0 keeps their style.
+	FlowThreshold int
 }
 
 func NewDefaultYamlPreferences() YamlPreferences {
@@ -13,6 +26,12 @@ func NewDefaultYamlPreferences() YamlPreferences {
 		PrintDocSeparators:          true,
 		UnwrapScalar:                true,
 		EvaluateTogether:            false,
+		PreserveFormatting:          false,
+		LineWidth:                   0,
+		IndentlessSequences:         false,
+		QuoteStyle:                  "",
+		LiteralMultilineStrings:     false,
+		FlowThreshold:               0,
 	}
 }
 
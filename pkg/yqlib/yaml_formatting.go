@@ -0,0 +1,234 @@
+package yqlib
+
+import (
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// yamlSourceLines keeps the lines of the source as it's read, splitting each of them only once. The lines
+// of the documents that have been decoded can be dropped, so that only the current document is kept.
+type yamlSourceLines struct {
+	lines []string
+	// the (1 based) line number of the first line kept
+	firstLine int
+	partial   string
+}
+
+func newYamlSourceLines() *yamlSourceLines {
+	return &yamlSourceLines{firstLine: 1}
+}
+
+func (s *yamlSourceLines) Write(p []byte) (int, error) {
+	text := s.partial + string(p)
+	lastNewLine := strings.LastIndexByte(text, '\n')
+	if lastNewLine == -1 {
+		s.partial = text
+		return len(p), nil
+	}
+	s.lines = append(s.lines, strings.Split(text[:lastNewLine], "\n")...)
+	s.partial = text[lastNewLine+1:]
+	return len(p), nil
+}
+
+// line returns the text of the (1 based) line number, if it's been read and not dropped.
+func (s *yamlSourceLines) line(number int) (string, bool) {
+	index := number - s.firstLine
+	if index < 0 || index >= len(s.lines) {
+		return "", false
+	}
+	return s.lines[index], true
+}
+
+// dropBefore forgets the lines before the (1 based) line number.
+func (s *yamlSourceLines) dropBefore(number int) {
+	drop := number - s.firstLine
+	if drop <= 0 {
+		return
+	}
+	if drop > len(s.lines) {
+		drop = len(s.lines)
+	}
+	s.lines = append([]string(nil), s.lines[drop:]...)
+	s.firstLine = s.firstLine + drop
+}
+
+// recordBlankLines counts the blank lines that separated each block map entry and sequence item from the
+// previous entry in the source. They're kept apart from the nodes' comments, so that only the yaml encoder
+// writes them out again.
+func recordBlankLines(node *yaml.Node, source *yamlSourceLines, blankLines map[*yaml.Node]int) {
+	if node.Style&yaml.FlowStyle == 0 {
+		switch node.Kind {
+		case yaml.MappingNode:
+			for index := 2; index < len(node.Content); index = index + 2 {
+				countBlankLines(node.Content[index], node.Content[index-1], source, blankLines)
+			}
+		case yaml.SequenceNode:
+			for index := 1; index < len(node.Content); index++ {
+				countBlankLines(node.Content[index], node.Content[index-1], source, blankLines)
+			}
+		}
+	}
+	for _, child := range node.Content {
+		recordBlankLines(child, source, blankLines)
+	}
+}
+
+func countBlankLines(node *yaml.Node, previous *yaml.Node, source *yamlSourceLines, blankLines map[*yaml.Node]int) {
+	// blank lines at the end of a block scalar with keep chomping are part of its value
+	if previous.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 && strings.HasSuffix(previous.Value, "\n\n") {
+		return
+	}
+	firstLine := node.Line
+	if node.HeadComment != "" {
+		firstLine = firstLine - strings.Count(node.HeadComment, "\n") - 1
+	}
+	count := 0
+	line, found := source.line(firstLine - 1)
+	for ; found && strings.TrimSpace(line) == ""; line, found = source.line(firstLine - 1 - count) {
+		count++
+	}
+	// the encoder already separates foot comments from the next entry with a blank line
+	if count == 0 || (found && strings.HasPrefix(strings.TrimSpace(line), "#")) {
+		return
+	}
+	blankLines[node] = count
+}
+
+// withBlankLines copies the node, prefixing the head comments of the entries that had blank lines before
+// them with those lines, which the yaml encoder writes out as they are.
+func withBlankLines(node *yaml.Node, blankLines map[*yaml.Node]int) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	copied := deepCloneNoContent(node)
+	if count := blankLines[node]; count > 0 {
+		copied.HeadComment = strings.Repeat("\n", count) + copied.HeadComment
+	}
+	if node.Content != nil {
+		copied.Content = make([]*yaml.Node, len(node.Content))
+		for index, child := range node.Content {
+			copied.Content[index] = withBlankLines(child, blankLines)
+		}
+	}
+	return copied
+}
+
+// applyYamlFormatting restores the formatting recorded when decoding on the encoded yaml. It removes the
+// indentation the encoder gives block sequences under map keys for the sequences that were indentless
+// in the source (or all of them with allIndentless), and the indentation the encoder writes on blank
+// lines kept from the source.
+func applyYamlFormatting(node *yaml.Node, encoded []byte, allIndentless bool) []byte {
+	indentless, indented := countSequenceIndentStyles(node)
+	if indentless == 0 && !allIndentless && !indentedBlankLineRegExp.Match(encoded) {
+		return encoded
+	}
+	var encodedNode yaml.Node
+	if err := yaml.Unmarshal(encoded, &encodedNode); err != nil {
+		log.Debugf("could not apply the yaml formatting: %v", err)
+		return encoded
+	}
+
+	lines := strings.Split(string(encoded), "\n")
+	formatter := &yamlLineFormatter{
+		lines:         lines,
+		shifts:        make([]int, len(lines)),
+		inBlockScalar: make([]bool, len(lines)),
+		documentStyle: indentless > indented,
+		allIndentless: allIndentless,
+	}
+	if !formatter.collect(unwrapDoc(node), unwrapDoc(&encodedNode), len(lines)) {
+		return encoded
+	}
+	for index, line := range lines {
+		if !formatter.inBlockScalar[index] && strings.TrimSpace(line) == "" {
+			lines[index] = ""
+			continue
+		}
+		shift := formatter.shifts[index]
+		if leading := len(line) - len(strings.TrimLeft(line, " ")); leading < shift {
+			shift = leading
+		}
+		lines[index] = line[shift:]
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+var indentedBlankLineRegExp = regexp.MustCompile(`(?m)^ +$`)
+
+// isIndentlessSequence checks if the block sequence value of the key was written without an indent,
+// falling back to the document style when it has no position to compare.
+func isIndentlessSequence(key *yaml.Node, value *yaml.Node, documentStyle bool) bool {
+	if key.Line > 0 && value.Line > key.Line {
+		return value.Column == key.Column
+	}
+	return documentStyle
+}
+
+func countSequenceIndentStyles(node *yaml.Node) (int, int) {
+	indentless, indented := 0, 0
+	if node.Kind == yaml.MappingNode {
+		for index := 0; index+1 < len(node.Content); index = index + 2 {
+			key, value := node.Content[index], node.Content[index+1]
+			if value.Kind == yaml.SequenceNode && key.Line > 0 && value.Line > key.Line {
+				if value.Column == key.Column {
+					indentless++
+				} else {
+					indented++
+				}
+			}
+		}
+	}
+	for _, child := range node.Content {
+		childIndentless, childIndented := countSequenceIndentStyles(child)
+		indentless = indentless + childIndentless
+		indented = indented + childIndented
+	}
+	return indentless, indented
+}
+
+type yamlLineFormatter struct {
+	lines         []string
+	shifts        []int
+	inBlockScalar []bool
+	documentStyle bool
+	allIndentless bool
+}
+
+// collect walks the node and its encoded form together, recording how far each line of the encoded
+// indentless sequences needs to move left and which lines are block scalar content. endLine is the
+// last line the node can span.
+func (f *yamlLineFormatter) collect(node *yaml.Node, encoded *yaml.Node, endLine int) bool {
+	if node.Kind != encoded.Kind || len(node.Content) != len(encoded.Content) {
+		return false
+	}
+	if encoded.Kind == yaml.ScalarNode && encoded.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		for line := encoded.Line + 1; line <= endLine && line <= len(f.lines); line++ {
+			f.inBlockScalar[line-1] = true
+		}
+	}
+	for index, child := range node.Content {
+		childEndLine := endLine
+		if index+1 < len(encoded.Content) && (node.Kind == yaml.SequenceNode || index%2 == 1) {
+			childEndLine = encoded.Content[index+1].Line - 1
+		}
+
+		encodedChild := encoded.Content[index]
+		if node.Kind == yaml.MappingNode && index%2 == 1 && encodedChild.Kind == yaml.SequenceNode &&
+			encodedChild.Style&yaml.FlowStyle == 0 && len(encodedChild.Content) > 0 &&
+			(f.allIndentless || isIndentlessSequence(node.Content[index-1], child, f.documentStyle)) {
+			shift := encodedChild.Column - encoded.Content[index-1].Column
+			for line := encodedChild.Line; line <= childEndLine && line <= len(f.lines); line++ {
+				text := f.lines[line-1]
+				if len(text)-len(strings.TrimLeft(text, " ")) >= encodedChild.Column-1 {
+					f.shifts[line-1] = f.shifts[line-1] + shift
+				}
+			}
+		}
+		if !f.collect(child, encodedChild, childEndLine) {
+			return false
+		}
+	}
+	return true
+}
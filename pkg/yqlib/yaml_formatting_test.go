@@ -0,0 +1,147 @@
+package yqlib
+
+import (
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+var yamlFormattingScenarios = []formatScenario{
+	{
+		description: "blank lines between entries",
+		input:       "a: 1\n\nb: 2\n\n\n# about c\nc:\n  d: 3\n\n  e: 4\n",
+		expected:    "a: 1\n\nb: 2\n\n\n# about c\nc:\n  d: 3\n\n  e: 4\n",
+	},
+	{
+		description: "blank lines between sequence items",
+		input:       "items:\n  - a\n\n  - b\n  - c\n",
+		expected:    "items:\n  - a\n\n  - b\n  - c\n",
+	},
+	{
+		description: "indentless sequences",
+		input:       "items:\n- name: a\n  tags:\n  - x\n  - y\n\n- name: b\nother:\n  nested:\n  - 1\n",
+		expected:    "items:\n- name: a\n  tags:\n  - x\n  - y\n\n- name: b\nother:\n  nested:\n  - 1\n",
+	},
+	{
+		description: "mixed sequence styles",
+		input:       "a:\n- 1\nb:\n  - 2\n",
+		expected:    "a:\n- 1\nb:\n  - 2\n",
+	},
+	{
+		description: "new sequences use the document style",
+		input:       "a:\n- 1\nb:\n- 2\n",
+		expression:  ".c = [3, 4] | .c style=\"\"",
+		expected:    "a:\n- 1\nb:\n- 2\nc:\n- 3\n- 4\n",
+	},
+	{
+		description: "blank lines kept on updates",
+		input:       "a: 1\n\n# about b\nb:\n- 2\n\nc: 3\n",
+		expression:  ".b += [5] | .a = 2",
+		expected:    "a: 2\n\n# about b\nb:\n- 2\n- 5\n\nc: 3\n",
+	},
+	{
+		description: "foot comments",
+		input:       "a: 1\n# foot\n\nb: 2\n",
+		expected:    "a: 1\n# foot\n\nb: 2\n",
+	},
+	{
+		description: "block scalars",
+		input:       "a: |\n  one\n\n  two\n\nb: |+\n  kept\n\nc:\n- |\n  text\n- d\n",
+		expected:    "a: |\n  one\n\n  two\n\nb: |+\n  kept\n\nc:\n- |\n  text\n- d\n",
+	},
+	{
+		description: "several documents",
+		input:       "a:\n- 1\n\nb: 2\n---\nc:\n  - 3\n\nd: 4\n",
+		expected:    "a:\n- 1\n\nb: 2\n---\nc:\n  - 3\n\nd: 4\n",
+	},
+	{
+		description: "blank lines in later documents",
+		input:       "a: 1\n\nb: 2\n---\n# c\nc: 3\n---\nd: 4\n\n\n# about e\ne:\n- 5\n\n- 6\n",
+		expected:    "a: 1\n\nb: 2\n---\n# c\nc: 3\n---\nd: 4\n\n\n# about e\ne:\n- 5\n\n- 6\n",
+	},
+	{
+		description: "head comments don't include the blank lines",
+		input:       "a: 1\n\n# about b\nb: 2\n",
+		expression:  ".b | key | head_comment",
+		expected:    "about b\n",
+	},
+}
+
+func testYamlFormattingScenario(t *testing.T, s formatScenario) {
+	prefs := NewDefaultYamlPreferences()
+	prefs.PreserveFormatting = true
+	output, err := processFormatScenario(s, NewYamlDecoder(prefs), NewYamlEncoder(2, false, prefs))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	test.AssertResultWithContext(t, s.expected, output, s.description)
+}
+
+func TestYamlFormattingScenarios(t *testing.T) {
+	for _, s := range yamlFormattingScenarios {
+		testYamlFormattingScenario(t, s)
+	}
+}
+
+func TestYamlFormattingIsOffByDefault(t *testing.T) {
+	s := formatScenario{input: "a: 1\n\nb:\n- 2\n"}
+	output, err := processFormatScenario(s, NewYamlDecoder(NewDefaultYamlPreferences()), NewYamlEncoder(2, false, NewDefaultYamlPreferences()))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	test.AssertResult(t, "a: 1\nb:\n  - 2\n", output)
+}
+
+func TestYamlSourceLines(t *testing.T) {
+	source := newYamlSourceLines()
+	for _, chunk := range []string{"a: 1\n\nb", ": 2\n", "c: 3"} {
+		if _, err := source.Write([]byte(chunk)); err != nil {
+			t.Error(err)
+		}
+	}
+	source.dropBefore(2)
+	_, found := source.line(1)
+	test.AssertResult(t, false, found)
+	line, _ := source.line(3)
+	test.AssertResult(t, "b: 2", line)
+	// the last line isn't complete yet
+	_, found = source.line(4)
+	test.AssertResult(t, false, found)
+}
+
+var preservedFormattingOtherEncoderScenarios = []struct {
+	formatScenario
+	encoder Encoder
+}{
+	{
+		formatScenario: formatScenario{
+			description: "xml",
+			input:       "root:\n  a: 1\n\n  b: 2\n\n  c:\n    - 3\n\n    - 4\n",
+			expected:    "<root>\n  <a>1</a>\n  <b>2</b>\n  <c>3</c>\n  <c>4</c>\n</root>\n",
+		},
+		encoder: NewXMLEncoder(2, ConfiguredXMLPreferences),
+	},
+	{
+		formatScenario: formatScenario{
+			description: "props",
+			input:       "a: 1\n\nb:\n  c: 2\n\n  d: 3\n",
+			expected:    "a = 1\nb.c = 2\nb.d = 3\n",
+		},
+		encoder: NewPropertiesEncoder(true),
+	},
+}
+
+func TestPreservedBlankLinesOnlyWrittenAsYaml(t *testing.T) {
+	prefs := NewDefaultYamlPreferences()
+	prefs.PreserveFormatting = true
+	for _, s := range preservedFormattingOtherEncoderScenarios {
+		output, err := processFormatScenario(s.formatScenario, NewYamlDecoder(prefs), s.encoder)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		test.AssertResultWithContext(t, s.expected, output, s.description)
+	}
+}
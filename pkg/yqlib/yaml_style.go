@@ -0,0 +1,276 @@
+package yqlib
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	QuoteStyleSingle  = "single"
+	QuoteStyleDouble  = "double"
+	QuoteStyleMinimal = "minimal"
+)
+
+// yaml11BoolRegEx matches the strings that yaml 1.1 reads as booleans, which need to stay quoted.
+var yaml11BoolRegEx = regexp.MustCompile(`(?i)^(y|yes|n|no|on|off)$`)
+
+// hasYamlStyles checks if the preferences change the style of any nodes before they are encoded.
+func hasYamlStyles(prefs YamlPreferences) bool {
+	return prefs.QuoteStyle != "" || prefs.LiteralMultilineStrings || prefs.FlowThreshold > 0
+}
+
+// applyYamlStyles sets the quote, block and flow styles of the nodes from the preferences.
+func applyYamlStyles(node *yaml.Node, prefs YamlPreferences, inFlow bool, isKey bool) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if !isKey && node.Tag == "!!str" {
+			applyStringStyle(node, prefs, inFlow)
+		}
+		return
+	case yaml.MappingNode, yaml.SequenceNode:
+		if !inFlow && isSmallScalarCollection(node, prefs.FlowThreshold) {
+			node.Style = node.Style | yaml.FlowStyle
+		}
+		inFlow = inFlow || node.Style&yaml.FlowStyle != 0
+	}
+	for index, child := range node.Content {
+		applyYamlStyles(child, prefs, inFlow, node.Kind == yaml.MappingNode && index%2 == 0)
+	}
+}
+
+func applyStringStyle(node *yaml.Node, prefs YamlPreferences, inFlow bool) {
+	quoteStyles := yaml.SingleQuotedStyle | yaml.DoubleQuotedStyle
+	if prefs.LiteralMultilineStrings && !inFlow && strings.Contains(strings.TrimSuffix(node.Value, "\n"), "\n") {
+		node.Style = node.Style&^(quoteStyles|yaml.FoldedStyle) | yaml.LiteralStyle
+		return
+	}
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return
+	}
+	switch prefs.QuoteStyle {
+	case QuoteStyleSingle:
+		node.Style = node.Style&^quoteStyles | yaml.SingleQuotedStyle
+	case QuoteStyleDouble:
+		node.Style = node.Style&^quoteStyles | yaml.DoubleQuotedStyle
+	case QuoteStyleMinimal:
+		// the encoder only quotes the strings that need it, except the yaml 1.1 booleans like the pretty print
+		if !yaml11BoolRegEx.MatchString(node.Value) {
+			node.Style = node.Style &^ quoteStyles
+		}
+	}
+}
+
+// isSmallScalarCollection checks if the collection has at most the given number of items, all of them
+// scalars without comments, so it can be written on a single line.
+func isSmallScalarCollection(node *yaml.Node, threshold int) bool {
+	items := len(node.Content)
+	if node.Kind == yaml.MappingNode {
+		items = items / 2
+	}
+	if threshold <= 0 || items == 0 || items > threshold {
+		return false
+	}
+	for _, child := range node.Content {
+		if child.Kind != yaml.ScalarNode || child.HeadComment != "" || child.LineComment != "" || child.FootComment != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// wrapYamlLines folds long strings onto several lines at their spaces, so that lines fit in the width
+// where possible. Plain, quoted and folded strings can be wrapped without changing their values.
+func wrapYamlLines(encoded []byte, width int, indent int) []byte {
+	var encodedNode yaml.Node
+	if err := yaml.Unmarshal(encoded, &encodedNode); err != nil {
+		log.Debugf("could not wrap the yaml lines: %v", err)
+		return encoded
+	}
+	lines := strings.Split(string(encoded), "\n")
+	wrapper := &yamlLineWrapper{lines: lines, wrapped: make([][]string, len(lines)), width: width, indent: indent}
+	wrapper.collect(unwrapDoc(&encodedNode), nil, len(lines))
+
+	var sb strings.Builder
+	for index, line := range lines {
+		if index > 0 {
+			sb.WriteString("\n")
+		}
+		if wrapper.wrapped[index] != nil {
+			sb.WriteString(strings.Join(wrapper.wrapped[index], "\n"))
+		} else {
+			sb.WriteString(line)
+		}
+	}
+	result := []byte(sb.String())
+
+	// only keep the wrapped lines when they parse back to the same document
+	var resultNode yaml.Node
+	if err := yaml.Unmarshal(result, &resultNode); err != nil || !minimalDiffEqual(&encodedNode, &resultNode) {
+		log.Debugf("wrapping the yaml lines changed the document, leaving them as they were")
+		return encoded
+	}
+	return result
+}
+
+type yamlLineWrapper struct {
+	lines   []string
+	wrapped [][]string
+	width   int
+	indent  int
+}
+
+// collect finds the strings that go past the width, parent is the node holding it and endLine
+// the last line the node can span.
+func (w *yamlLineWrapper) collect(node *yaml.Node, parent *yaml.Node, endLine int) {
+	if node.Style&yaml.FlowStyle != 0 {
+		return
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if parent != nil && node.Tag == "!!str" {
+			w.wrapScalar(node, parent, endLine)
+		}
+	case yaml.MappingNode, yaml.SequenceNode:
+		for index, child := range node.Content {
+			childEndLine := endLine
+			if index+1 < len(node.Content) {
+				childEndLine = node.Content[index+1].Line - 1
+			}
+			if node.Kind == yaml.MappingNode && index%2 == 0 {
+				// keys are not wrapped
+				continue
+			}
+			w.collect(child, node, childEndLine)
+		}
+	}
+}
+
+func (w *yamlLineWrapper) wrapScalar(node *yaml.Node, parent *yaml.Node, endLine int) {
+	if node.Style&yaml.FoldedStyle != 0 {
+		w.wrapFoldedScalar(node, endLine)
+		return
+	}
+	if node.Style&yaml.LiteralStyle != 0 || node.Line < 1 || node.Line > len(w.lines) || w.wrapped[node.Line-1] != nil {
+		return
+	}
+	line := w.lines[node.Line-1]
+	start := node.Column - 1
+	end := scalarTokenEnd(line, start, node.Style)
+	if end < 0 || utf8.RuneCountInString(line[:end]) <= w.width {
+		return
+	}
+
+	// continuation lines need to be indented more than the key or the '- ' of the item
+	continuationIndent := start
+	if parent.Kind == yaml.MappingNode {
+		continuationIndent = parentKeyColumn(parent, node) - 1 + w.indent
+	}
+	w.wrapped[node.Line-1] = wrapText(line[:start], line[start:end], line[end:], strings.Repeat(" ", continuationIndent), w.width, node.Style)
+}
+
+func (w *yamlLineWrapper) wrapFoldedScalar(node *yaml.Node, endLine int) {
+	blockIndent := -1
+	for line := node.Line + 1; line <= endLine && line <= len(w.lines); line++ {
+		text := w.lines[line-1]
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		textIndent := len(text) - len(strings.TrimLeft(text, " "))
+		if blockIndent < 0 {
+			blockIndent = textIndent
+		}
+		// more indented lines are kept as they are
+		if textIndent != blockIndent || utf8.RuneCountInString(text) <= w.width {
+			continue
+		}
+		prefix := text[:blockIndent]
+		w.wrapped[line-1] = wrapText(prefix, text[blockIndent:], "", prefix, w.width, node.Style)
+	}
+}
+
+func parentKeyColumn(parent *yaml.Node, value *yaml.Node) int {
+	for index := 1; index < len(parent.Content); index = index + 2 {
+		if parent.Content[index] == value {
+			return parent.Content[index-1].Column
+		}
+	}
+	return 1
+}
+
+// scalarTokenEnd finds where the single line scalar starting at the index ends in the line,
+// or -1 when it doesn't end on the line.
+func scalarTokenEnd(line string, start int, style yaml.Style) int {
+	if start < 0 || start >= len(line) {
+		return -1
+	}
+	switch {
+	case style&yaml.SingleQuotedStyle != 0:
+		for index := start + 1; index < len(line); index++ {
+			if line[index] == '\'' {
+				if index+1 < len(line) && line[index+1] == '\'' {
+					index++
+					continue
+				}
+				return index + 1
+			}
+		}
+		return -1
+	case style&yaml.DoubleQuotedStyle != 0:
+		for index := start + 1; index < len(line); index++ {
+			if line[index] == '\\' {
+				index++
+			} else if line[index] == '"' {
+				return index + 1
+			}
+		}
+		return -1
+	}
+	if commentIndex := strings.Index(line[start:], " #"); commentIndex >= 0 {
+		return start + commentIndex
+	}
+	return len(line)
+}
+
+// wrapText breaks the text at single spaces between words, greedily filling each line up to the width.
+// A line break between words folds back into a single space when the yaml is read.
+func wrapText(prefix string, text string, suffix string, continuationPrefix string, width int, style yaml.Style) []string {
+	var lines []string
+	current := prefix
+	for {
+		available := width - utf8.RuneCountInString(current)
+		breakIndex := -1
+		for index := 1; index < len(text)-1; index++ {
+			if !canBreakAt(text, index, style) {
+				continue
+			}
+			if breakIndex >= 0 && utf8.RuneCountInString(text[:index]) > available {
+				break
+			}
+			breakIndex = index
+			if utf8.RuneCountInString(text[:index]) > available {
+				break
+			}
+		}
+		if breakIndex < 0 || utf8.RuneCountInString(text) <= available {
+			lines = append(lines, current+text+suffix)
+			return lines
+		}
+		lines = append(lines, current+text[:breakIndex])
+		text = text[breakIndex+1:]
+		current = continuationPrefix
+	}
+}
+
+func canBreakAt(text string, index int, style yaml.Style) bool {
+	if text[index] != ' ' || text[index-1] == ' ' || text[index+1] == ' ' {
+		return false
+	}
+	if style&yaml.DoubleQuotedStyle != 0 && text[index-1] == '\\' {
+		return false
+	}
+	// a plain continuation line starting with # would be read as a comment
+	return style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.FoldedStyle) != 0 || text[index+1] != '#'
+}
@@ -0,0 +1,111 @@
+package yqlib
+
+import (
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+type yamlStyleScenario struct {
+	description string
+	prefs       func(prefs *YamlPreferences)
+	input       string
+	expression  string
+	expected    string
+}
+
+var yamlStyleScenarios = []yamlStyleScenario{
+	{
+		description: "wrap long plain strings",
+		prefs:       func(prefs *YamlPreferences) { prefs.LineWidth = 20 },
+		input:       "a: the quick brown fox jumps over the lazy dog # animals\nb:\n  - the quick brown fox jumps\n",
+		expected:    "a: the quick brown\n  fox jumps over the\n  lazy dog # animals\nb:\n  - the quick brown\n    fox jumps\n",
+	},
+	{
+		description: "wrap long quoted strings",
+		prefs:       func(prefs *YamlPreferences) { prefs.LineWidth = 20 },
+		input:       "a: 'it''s a long sentence with quotes'\nb: \"tab\\tseparated and long text\"\n",
+		expected:    "a: 'it''s a long\n  sentence with\n  quotes'\nb: \"tab\\tseparated\n  and long text\"\n",
+	},
+	{
+		description: "wrap folded strings",
+		prefs:       func(prefs *YamlPreferences) { prefs.LineWidth = 20 },
+		input:       "a: >\n  the quick brown fox jumps over the lazy dog\n\n    indented lines are kept as they are\n",
+		expected:    "a: >\n  the quick brown\n  fox jumps over the\n  lazy dog\n\n\n    indented lines are kept as they are\n",
+	},
+	{
+		description: "words longer than the width are not broken",
+		prefs:       func(prefs *YamlPreferences) { prefs.LineWidth = 10 },
+		input:       "a: https://example.com/a/long/path and more\nb: '#notacomment #either'\nc: x #y z\n",
+		expected:    "a: https://example.com/a/long/path\n  and more\nb: '#notacomment\n  #either'\nc: x #y z\n",
+	},
+	{
+		description: "indentless sequences",
+		prefs:       func(prefs *YamlPreferences) { prefs.IndentlessSequences = true },
+		input:       "a:\n  - b: 1\n    c:\n      - 2\n  - 3\n",
+		expected:    "a:\n- b: 1\n  c:\n  - 2\n- 3\n",
+	},
+	{
+		description: "single quotes",
+		prefs:       func(prefs *YamlPreferences) { prefs.QuoteStyle = QuoteStyleSingle },
+		input:       "a: cat\n\"b\": \"dog\"\nc: 3\nd: |\n  block\n",
+		expected:    "a: 'cat'\n\"b\": 'dog'\nc: 3\nd: |\n  block\n",
+	},
+	{
+		description: "double quotes",
+		prefs:       func(prefs *YamlPreferences) { prefs.QuoteStyle = QuoteStyleDouble },
+		input:       "a: cat\nb: 'dog'\nc: [x, true]\n",
+		expected:    "a: \"cat\"\nb: \"dog\"\nc: [\"x\", true]\n",
+	},
+	{
+		description: "minimal quotes",
+		prefs:       func(prefs *YamlPreferences) { prefs.QuoteStyle = QuoteStyleMinimal },
+		input:       "a: \"cat\"\nb: 'true'\nc: \"a: b\"\n",
+		expected:    "a: cat\nb: \"true\"\nc: 'a: b'\n",
+	},
+	{
+		description: "minimal quotes keep yaml 1.1 booleans quoted",
+		prefs:       func(prefs *YamlPreferences) { prefs.QuoteStyle = QuoteStyleMinimal },
+		input:       "a: \"yes\"\nb: 'Off'\nc: \"y\"\nd: \"yesterday\"\n",
+		expected:    "a: \"yes\"\nb: 'Off'\nc: \"y\"\nd: yesterday\n",
+	},
+	{
+		description: "literal multiline strings",
+		prefs:       func(prefs *YamlPreferences) { prefs.LiteralMultilineStrings = true },
+		input:       "a: \"one\\ntwo\\n\"\nb: \"single line\\n\"\nc: >\n  folded\n\n  text\n",
+		expected:    "a: |\n  one\n  two\nb: \"single line\\n\"\nc: |\n  folded\n  text\n",
+	},
+	{
+		description: "flow style for small collections",
+		prefs:       func(prefs *YamlPreferences) { prefs.FlowThreshold = 2 },
+		input:       "a:\n  - 1\n  - 2\nb:\n  - 1\n  - 2\n  - 3\nc:\n  d: 1\nlist:\n  - x: 1\n    y: 2\n  - z: 3 # comment\n",
+		expected:    "a: [1, 2]\nb:\n  - 1\n  - 2\n  - 3\nc: {d: 1}\nlist:\n  - {x: 1, y: 2}\n  - z: 3 # comment\n",
+	},
+	{
+		description: "styles apply to updated values",
+		prefs: func(prefs *YamlPreferences) {
+			prefs.QuoteStyle = QuoteStyleSingle
+			prefs.IndentlessSequences = true
+		},
+		input:      "a: 1\n",
+		expression: ".b = [\"x\", \"y\"]",
+		expected:   "a: 1\nb:\n- 'x'\n- 'y'\n",
+	},
+}
+
+func testYamlStyleScenario(t *testing.T, s yamlStyleScenario) {
+	prefs := NewDefaultYamlPreferences()
+	s.prefs(&prefs)
+	output, err := processFormatScenario(formatScenario{input: s.input, expression: s.expression}, NewYamlDecoder(prefs), NewYamlEncoder(2, false, prefs))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	test.AssertResultWithContext(t, s.expected, output, s.description)
+}
+
+func TestYamlStyleScenarios(t *testing.T) {
+	for _, s := range yamlStyleScenarios {
+		testYamlStyleScenario(t, s)
+	}
+}